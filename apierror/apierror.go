@@ -0,0 +1,133 @@
+// Package apierror declares errors returned by handlers and middleware. Kind of error decides status
+// of response and code identifies error for clients, so they do not have to parse messages
+package apierror
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+)
+
+type Kind int
+
+const (
+	// KindInternal is unexpected failure of server, its message is never sent to client
+	KindInternal Kind = iota
+	// KindBadRequest is request which can not be read, e.g. body is not JSON or id is not UUID
+	KindBadRequest
+	// KindValidation is well-formed request with invalid values
+	KindValidation
+	KindUnauthorized
+	KindForbidden
+	KindNotFound
+	KindConflict
+	KindTooManyRequests
+	// KindUpstream is failure of external service, e.g. identity provider
+	KindUpstream
+	// KindUnavailable is feature which is turned off in configuration, e.g. sending emails
+	KindUnavailable
+)
+
+// Status returns HTTP status of response to errors of kind
+func (k Kind) Status() int {
+	switch k {
+	case KindBadRequest, KindValidation:
+		return fiber.StatusBadRequest
+	case KindUnauthorized:
+		return fiber.StatusUnauthorized
+	case KindForbidden:
+		return fiber.StatusForbidden
+	case KindNotFound:
+		return fiber.StatusNotFound
+	case KindConflict:
+		return fiber.StatusConflict
+	case KindTooManyRequests:
+		return fiber.StatusTooManyRequests
+	case KindUpstream:
+		return fiber.StatusBadGateway
+	case KindUnavailable:
+		return fiber.StatusServiceUnavailable
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// Error is error with kind and machine-readable code, e.g. tournament_not_found
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	// Errors holds details of error, e.g. invalid rows of import file, they are sent to client
+	Errors interface{}
+	// Err is cause of error, it is logged but never sent to client
+	Err error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Wrap returns copy of error with given cause
+func (e *Error) Wrap(err error) *Error {
+	wrapped := *e
+	wrapped.Err = err
+	return &wrapped
+}
+
+// WithErrors returns copy of error with given details
+func (e *Error) WithErrors(errors interface{}) *Error {
+	detailed := *e
+	detailed.Errors = errors
+	return &detailed
+}
+
+func New(kind Kind, code string, format string, args ...interface{}) *Error {
+	return &Error{Kind: kind, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+func BadRequest(code string, format string, args ...interface{}) *Error {
+	return New(KindBadRequest, code, format, args...)
+}
+
+func Validation(code string, format string, args ...interface{}) *Error {
+	return New(KindValidation, code, format, args...)
+}
+
+func Unauthorized(code string, format string, args ...interface{}) *Error {
+	return New(KindUnauthorized, code, format, args...)
+}
+
+func Forbidden(code string, format string, args ...interface{}) *Error {
+	return New(KindForbidden, code, format, args...)
+}
+
+func NotFound(code string, format string, args ...interface{}) *Error {
+	return New(KindNotFound, code, format, args...)
+}
+
+func Conflict(code string, format string, args ...interface{}) *Error {
+	return New(KindConflict, code, format, args...)
+}
+
+func TooManyRequests(code string, format string, args ...interface{}) *Error {
+	return New(KindTooManyRequests, code, format, args...)
+}
+
+func Upstream(code string, format string, args ...interface{}) *Error {
+	return New(KindUpstream, code, format, args...)
+}
+
+func Unavailable(code string, format string, args ...interface{}) *Error {
+	return New(KindUnavailable, code, format, args...)
+}
+
+// Internal wraps unexpected error, client gets generic message and the error is logged
+func Internal(err error) *Error {
+	return New(KindInternal, "internal_error", "Internal server error").Wrap(err)
+}
@@ -0,0 +1,93 @@
+// Package commands declares subcommands of tiktok-arena binary. Every subcommand loads configuration
+// from the same env file, only serve starts HTTP server
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"github.com/urfave/cli/v2"
+	"io/fs"
+	"os"
+	"strings"
+	"tiktok-arena/configuration"
+	"tiktok-arena/database"
+	"tiktok-arena/logging"
+	"tiktok-arena/store"
+)
+
+func NewApp() *cli.App {
+	return &cli.App{
+		Name:  "tiktok-arena",
+		Usage: "TikTok arena server and administration commands",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "env",
+				Value: ".env",
+				Usage: "path to env file with configuration, default file is skipped if it does not exist",
+			},
+			&cli.StringSliceFlag{
+				Name:  "set",
+				Usage: "override configuration key, e.g. --set LOGIN_THROTTLE_STORE=database, can be repeated",
+			},
+			&cli.StringFlag{
+				Name:  "listen",
+				Usage: "address to serve API on, overrides LISTEN_ADDR",
+			},
+		},
+		Before: loadConfig,
+		Commands: []*cli.Command{
+			serveCommand(),
+			migrateCommand(),
+			userCommand(),
+			tournamentCommand(),
+			statsCommand(),
+		},
+		DefaultCommand: "serve",
+	}
+}
+
+// loadConfig loads configuration from env file, environment and flags, later ones take precedence
+func loadConfig(c *cli.Context) error {
+	envFile := c.String("env")
+	if !c.IsSet("env") {
+		if _, err := os.Stat(envFile); errors.Is(err, fs.ErrNotExist) {
+			envFile = ""
+		}
+	}
+
+	overrides := make(map[string]string)
+	for _, override := range c.StringSlice("set") {
+		key, value, ok := strings.Cut(override, "=")
+		if !ok {
+			return fmt.Errorf("--set expects KEY=VALUE, got %s", override)
+		}
+		overrides[key] = value
+	}
+	if c.IsSet("listen") {
+		overrides["LISTEN_ADDR"] = c.String("listen")
+	}
+
+	err := configuration.LoadConfig(envFile, overrides)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	return logging.Setup(&configuration.EnvConfig)
+}
+
+// connectStores connects to database with schema check of serve command, so administration commands
+// never write to database with outdated schema
+func connectStores() (store.Stores, error) {
+	db, err := database.ConnectDB(&configuration.EnvConfig)
+	if err != nil {
+		return store.Stores{}, err
+	}
+	return database.NewStore(db).Stores(), nil
+}
+
+// requireArgs returns error unless command got exactly count positional arguments
+func requireArgs(c *cli.Context, count int) error {
+	if c.NArg() != count {
+		return fmt.Errorf("%s expects %d argument(s): %s", c.Command.FullName(), count, c.Command.ArgsUsage)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/urfave/cli/v2"
+	"tiktok-arena/configuration"
+	"tiktok-arena/database"
+	"time"
+)
+
+func migrateCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "migrate",
+		Usage: "manage database schema",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "up",
+				Usage:  "apply pending migrations",
+				Action: migrateUp,
+			},
+			{
+				Name:  "down",
+				Usage: "revert latest applied migrations",
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:  "steps",
+						Value: 1,
+						Usage: "count of migrations to revert",
+					},
+				},
+				Action: migrateDown,
+			},
+			{
+				Name:   "status",
+				Usage:  "list migrations and whether they are applied",
+				Action: migrateStatus,
+			},
+		},
+	}
+}
+
+// newMigrator connects to database without schema check, migrate commands must work with any schema
+func newMigrator() (*database.Migrator, error) {
+	db, err := database.Open(&configuration.EnvConfig)
+	if err != nil {
+		return nil, err
+	}
+	return database.NewMigrator(db)
+}
+
+func migrateUp(c *cli.Context) error {
+	migrator, err := newMigrator()
+	if err != nil {
+		return err
+	}
+	applied, err := migrator.Up()
+	for _, migration := range applied {
+		fmt.Fprintf(c.App.Writer, "Applied %04d_%s\n", migration.Version, migration.Name)
+	}
+	if err != nil {
+		return err
+	}
+	if len(applied) == 0 {
+		fmt.Fprintln(c.App.Writer, "Database schema is up to date")
+	}
+	return nil
+}
+
+func migrateDown(c *cli.Context) error {
+	steps := c.Int("steps")
+	if steps < 1 {
+		return fmt.Errorf("steps must be positive, got %d", steps)
+	}
+	migrator, err := newMigrator()
+	if err != nil {
+		return err
+	}
+	reverted, err := migrator.Down(steps)
+	for _, migration := range reverted {
+		fmt.Fprintf(c.App.Writer, "Reverted %04d_%s\n", migration.Version, migration.Name)
+	}
+	if err != nil {
+		return err
+	}
+	if len(reverted) == 0 {
+		fmt.Fprintln(c.App.Writer, "No migrations are applied")
+	}
+	return nil
+}
+
+func migrateStatus(c *cli.Context) error {
+	migrator, err := newMigrator()
+	if err != nil {
+		return err
+	}
+	status, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+	for _, migration := range status {
+		appliedAt := "pending"
+		if migration.AppliedAt != nil {
+			appliedAt = "applied at " + migration.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(c.App.Writer, "%04d_%s\t%s\n", migration.Version, migration.Name, appliedAt)
+	}
+	version, err := migrator.Version()
+	if err != nil {
+		return err
+	}
+	if version > migrator.Latest() {
+		fmt.Fprintf(c.App.Writer, "Database is at version %d, newer than this build\n", version)
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/exp/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"tiktok-arena/configuration"
+	"tiktok-arena/controllers"
+	"tiktok-arena/database"
+	"tiktok-arena/events"
+	"tiktok-arena/health"
+	"tiktok-arena/jwtkeys"
+	"tiktok-arena/logging"
+	"tiktok-arena/mailer"
+	"tiktok-arena/metrics"
+	"tiktok-arena/middleware"
+	"tiktok-arena/notifications"
+	"tiktok-arena/oidc"
+	"tiktok-arena/router"
+	"tiktok-arena/throttle"
+	"tiktok-arena/tracing"
+	"tiktok-arena/webhooks"
+	"time"
+)
+
+func serveCommand() *cli.Command {
+	return &cli.Command{
+		Name:   "serve",
+		Usage:  "start HTTP API server, pending migrations are applied if DB_AUTO_MIGRATE is set",
+		Action: serve,
+	}
+}
+
+func serve(_ *cli.Context) error {
+	err := jwtkeys.Setup(&configuration.EnvConfig)
+	if err != nil {
+		return fmt.Errorf("failed to load JWT keys: %w", err)
+	}
+	oidc.Setup(&configuration.EnvConfig)
+	err = mailer.Setup(&configuration.EnvConfig)
+	if err != nil {
+		return fmt.Errorf("failed to set up mailer: %w", err)
+	}
+	err = tracing.Setup(&configuration.EnvConfig)
+	if err != nil {
+		return fmt.Errorf("failed to set up tracing: %w", err)
+	}
+	defer func() {
+		//	Spans of last requests are exported before exit
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		err := tracing.Shutdown(ctx)
+		if err != nil {
+			slog.Error("Failed to export remaining spans", "error", err)
+		}
+	}()
+
+	//	Startup banner is replaced with log record, so log collectors get only JSON lines
+	//	Client IP is taken from proxy header only in requests of trusted load balancers
+	app := fiber.New(fiber.Config{
+		DisableStartupMessage:   true,
+		ErrorHandler:            controllers.ErrorHandler,
+		ProxyHeader:             configuration.EnvConfig.ProxyHeader,
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          configuration.EnvConfig.TrustedProxyList(),
+		EnableIPValidation:      true,
+	})
+
+	//	Tracing middleware comes first, so request span covers all other middleware
+	app.Use(tracing.Middleware())
+
+	//	Request ID middleware for tagging every log line of request with its ID
+	app.Use(middleware.RequestID())
+
+	//	Logging middleware for logging HTTP request/response details
+	app.Use(logging.Middleware())
+
+	//	Metrics middleware for counting requests and measuring their duration
+	app.Use(metrics.Middleware())
+
+	//	Error middleware for responding to errors with problem details before they reach middleware above
+	app.Use(controllers.ErrorMiddleware())
+
+	//	Cors middleware
+	app.Use(cors.New(cors.Config{
+		AllowOrigins: configuration.EnvConfig.CORSAllowOrigins,
+		AllowHeaders: "*",
+	}))
+
+	db, err := database.ConnectDB(&configuration.EnvConfig)
+	if err != nil {
+		return err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	err = metrics.InstrumentDB(db)
+	if err != nil {
+		return fmt.Errorf("failed to instrument database: %w", err)
+	}
+	err = tracing.InstrumentDB(db)
+	if err != nil {
+		return fmt.Errorf("failed to instrument database: %w", err)
+	}
+	defer func() {
+		err := sqlDB.Close()
+		if err != nil {
+			slog.Error("Failed to close database connections", "error", err)
+		}
+	}()
+	err = throttle.Setup(&configuration.EnvConfig, db)
+	if err != nil {
+		return err
+	}
+	stores := database.NewStore(db).Stores()
+
+	notifier := notifications.Setup(events.Default, stores.Notifications)
+	dispatcher := webhooks.Setup(&configuration.EnvConfig, events.Default, stores.Webhooks)
+
+	checker := health.NewChecker()
+	checker.Add("database", sqlDB.PingContext)
+	checker.Add("migrations", func(ctx context.Context) error {
+		migrator, err := database.NewMigrator(db.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+		return migrator.CheckApplied()
+	})
+	checker.Add("webhooks", func(_ context.Context) error {
+		if !dispatcher.Running() {
+			return errors.New("webhook dispatcher is not running")
+		}
+		return nil
+	})
+
+	handlers := controllers.NewHandlers(stores, events.Default, mailer.Default, dispatcher, notifier.Live, checker)
+	auth := middleware.NewAuth(stores.Users, stores.APITokens)
+	router.SetupRoutes(app, handlers, auth)
+
+	//	Webhook deliveries are sent in background, outside of request handlers
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		dispatcher.Run(workersCtx)
+	}()
+
+	signals, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	listenErr := make(chan error, 1)
+	go func() {
+		listenErr <- app.Listen(configuration.EnvConfig.ListenAddr)
+	}()
+	slog.Info("Serving API", "address", configuration.EnvConfig.ListenAddr)
+
+	select {
+	case err = <-listenErr:
+		stopWorkers()
+		workers.Wait()
+		return err
+	case <-signals.Done():
+	}
+	return shutdown(app, checker, notifier.Live, stopWorkers, &workers)
+}
+
+// shutdown stops accepting connections and lets in-flight requests and background work finish
+// within SHUTDOWN_TIMEOUT, then remaining connections are closed and background work is cancelled
+func shutdown(
+	app *fiber.App,
+	checker *health.Checker,
+	live *notifications.Hub,
+	stopWorkers context.CancelFunc,
+	workers *sync.WaitGroup,
+) error {
+	timeout := configuration.EnvConfig.ShutdownTimeout
+	deadline := time.Now().Add(timeout)
+	slog.Info("Shutting down, waiting for in-flight requests", "timeout", timeout.String())
+
+	checker.ShutDown()
+	//	Notification streams never end by themselves, they would hold shutdown until timeout
+	live.Close()
+	err := app.ShutdownWithTimeout(timeout)
+	if err != nil {
+		slog.Warn("Requests did not finish in time", "error", err)
+	}
+
+	stopWorkers()
+	drained := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		slog.Info("Server stopped")
+	case <-time.After(time.Until(deadline)):
+		slog.Warn("Background work did not finish in time")
+	}
+	return nil
+}
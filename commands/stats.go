@@ -0,0 +1,36 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/urfave/cli/v2"
+)
+
+func statsCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "stats",
+		Usage: "maintain tiktok statistics",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "recompute-wins",
+				Usage: "recompute wins of tiktoks from recorded contests",
+				Description: "Sets wins of every tiktok to count of recorded contests it won. Average points " +
+					"and times played can not be recomputed, contests record only winner and not points " +
+					"of every played tiktok, so they are left as is",
+				Action: recomputeWins,
+			},
+		},
+	}
+}
+
+func recomputeWins(c *cli.Context) error {
+	stores, err := connectStores()
+	if err != nil {
+		return err
+	}
+	fixed, err := stores.Tournaments.RecomputeTiktokWins(c.Context)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(c.App.Writer, "Recomputed wins, %d tiktok(s) corrected\n", fixed)
+	return nil
+}
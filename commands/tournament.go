@@ -0,0 +1,219 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/urfave/cli/v2"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"tiktok-arena/models"
+	"tiktok-arena/store"
+	"tiktok-arena/transfer"
+)
+
+func tournamentCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "tournament",
+		Usage: "manage tournaments",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "import",
+				Usage:     "create tournament from JSON or CSV file, every invalid row is reported",
+				ArgsUsage: "FILE",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "owner",
+						Required: true,
+						Usage:    "name of user who will own tournament",
+					},
+					formatFlag(),
+					&cli.StringFlag{
+						Name:  "name",
+						Usage: "tournament name, required for CSV file",
+					},
+					&cli.IntFlag{
+						Name:  "size",
+						Usage: "tournament size for CSV file, count of rows by default",
+					},
+				},
+				Action: importTournament,
+			},
+			{
+				Name:      "export",
+				Usage:     "write tournament with its tiktoks and their statistics as JSON or CSV",
+				ArgsUsage: "ID",
+				Flags: []cli.Flag{
+					formatFlag(),
+					&cli.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "file to write to, standard output by default",
+					},
+				},
+				Action: exportTournament,
+			},
+			{
+				Name:      "delete",
+				Usage:     "delete tournament with its tiktoks, contests and upvotes",
+				ArgsUsage: "ID",
+				Action:    deleteTournament,
+			},
+		},
+	}
+}
+
+// formatFlag is file format of import and export, by default it is taken from file extension
+func formatFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:  "format",
+		Usage: "file format: json or csv, by default taken from file extension",
+	}
+}
+
+func transferFormat(c *cli.Context, path string) (string, error) {
+	format := c.String("format")
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	}
+	if format == "" {
+		format = models.TransferFormatJSON
+	}
+	if !models.CheckIfAllowedTransferFormat(format) {
+		return "", fmt.Errorf("%s is not allowed file format", format)
+	}
+	return format, nil
+}
+
+func importTournament(c *cli.Context) error {
+	err := requireArgs(c, 1)
+	if err != nil {
+		return err
+	}
+	path := c.Args().First()
+	format, err := transferFormat(c, path)
+	if err != nil {
+		return err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var payload *models.CreateTournament
+	if format == models.TransferFormatCSV {
+		payload, err = transfer.ImportCSV(file, c.String("name"), c.Int("size"))
+	} else {
+		payload, err = transfer.ImportJSON(file)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid tournament file %s:\n%w", path, err)
+	}
+
+	stores, err := connectStores()
+	if err != nil {
+		return err
+	}
+	owner, err := getUserByName(c, stores.Users, c.String("owner"))
+	if err != nil {
+		return err
+	}
+	if stores.Tournaments.CheckIfTournamentExists(c.Context, payload.Name) {
+		return fmt.Errorf("tournament %s already exists", payload.Name)
+	}
+
+	newTournamentId, err := uuid.NewRandom()
+	if err != nil {
+		return err
+	}
+	newTournament := models.Tournament{
+		ID:     &newTournamentId,
+		Name:   payload.Name,
+		UserID: owner.ID,
+		Size:   payload.Size,
+	}
+	err = stores.Tournaments.CreateNewTournament(c.Context, &newTournament)
+	if err != nil {
+		return err
+	}
+	for _, value := range payload.Tiktoks {
+		err = stores.Tiktoks.CreateNewTiktok(c.Context, &models.Tiktok{
+			TournamentID: &newTournamentId,
+			URL:          value.URL,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(c.App.Writer, "Imported tournament %s with id %s\n", newTournament.Name, newTournamentId)
+	return nil
+}
+
+func exportTournament(c *cli.Context) error {
+	err := requireArgs(c, 1)
+	if err != nil {
+		return err
+	}
+	tournamentId := c.Args().First()
+
+	stores, err := connectStores()
+	if err != nil {
+		return err
+	}
+	tournament, err := stores.Tournaments.GetTournamentById(c.Context, tournamentId)
+	if errors.Is(err, store.ErrNotFound) {
+		return fmt.Errorf("tournament with id %s not found", tournamentId)
+	}
+	if err != nil {
+		return err
+	}
+	tiktoks, err := stores.Tiktoks.GetTournamentTiktoksById(c.Context, tournamentId, true)
+	if err != nil {
+		return err
+	}
+
+	path := c.String("output")
+	format, err := transferFormat(c, path)
+	if err != nil {
+		return err
+	}
+	var output io.Writer = c.App.Writer
+	if path != "" {
+		file, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		output = file
+	}
+	export := models.NewTournamentExport(&tournament, tiktoks)
+	return transfer.Export(output, format, &export)
+}
+
+func deleteTournament(c *cli.Context) error {
+	err := requireArgs(c, 1)
+	if err != nil {
+		return err
+	}
+	tournamentId := c.Args().First()
+	if _, err := uuid.Parse(tournamentId); err != nil {
+		return fmt.Errorf("%s is not a valid tournament id", tournamentId)
+	}
+
+	stores, err := connectStores()
+	if err != nil {
+		return err
+	}
+	err = stores.Tournaments.DeleteTournament(c.Context, tournamentId)
+	if errors.Is(err, store.ErrNotFound) {
+		return fmt.Errorf("tournament with id %s not found", tournamentId)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(c.App.Writer, "Tournament %s deleted\n", tournamentId)
+	return nil
+}
@@ -0,0 +1,200 @@
+package commands
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/term"
+	"io"
+	"os"
+	"strings"
+	"tiktok-arena/models"
+	"tiktok-arena/store"
+)
+
+func userCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "user",
+		Usage: "manage users",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "create",
+				Usage: "create user, password must follow the same policy as on registration",
+				Description: "Password is read from " + passwordEnv + " environment variable when it is set, " +
+					"otherwise it is prompted for without echo, or read from first line of standard input " +
+					"when it is not a terminal. Password is not accepted as flag, flags are visible " +
+					"to other users in process list and end up in shell history",
+				ArgsUsage: "NAME",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "role",
+						Value: models.RoleUser,
+						Usage: "role of new user: user, moderator or admin",
+					},
+				},
+				Action: createUser,
+			},
+			{
+				Name:      "ban",
+				Usage:     "ban user, banned user can not log in or use existing tokens",
+				ArgsUsage: "NAME",
+				Action:    banUser,
+			},
+			{
+				Name:      "promote",
+				Usage:     "change role of user",
+				ArgsUsage: "NAME",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "role",
+						Value: models.RoleAdmin,
+						Usage: "new role of user: user, moderator or admin",
+					},
+				},
+				Action: promoteUser,
+			},
+		},
+	}
+}
+
+func createUser(c *cli.Context) error {
+	err := requireArgs(c, 1)
+	if err != nil {
+		return err
+	}
+	name, role := c.Args().First(), c.String("role")
+
+	if !models.CheckIfAllowedRole(role) {
+		return fmt.Errorf("unknown role %s", role)
+	}
+	err = models.ValidateUsername(name)
+	if err != nil {
+		return err
+	}
+	password, err := readPassword(c)
+	if err != nil {
+		return err
+	}
+	err = models.ValidatePassword(name, password)
+	if err != nil {
+		return err
+	}
+
+	stores, err := connectStores()
+	if err != nil {
+		return err
+	}
+	if stores.Users.CheckIfUserExists(c.Context, name) {
+		return fmt.Errorf("user %s already exists", name)
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	newUser := models.User{
+		Name:     name,
+		Password: string(hashedPassword),
+		Role:     role,
+	}
+	err = stores.Users.CreateNewUser(c.Context, &newUser)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(c.App.Writer, "Created %s %s with id %s\n", role, name, newUser.ID)
+	return nil
+}
+
+// passwordEnv is environment variable with password of created user, for scripts without standard input
+const passwordEnv = "ARENA_USER_PASSWORD"
+
+// readPassword reads password of created user from passwordEnv, terminal prompt or standard input
+func readPassword(c *cli.Context) (string, error) {
+	if password, ok := os.LookupEnv(passwordEnv); ok {
+		return password, nil
+	}
+
+	stdin := int(os.Stdin.Fd())
+	if !term.IsTerminal(stdin) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", fmt.Errorf("reading password from standard input failed: %w", err)
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	fmt.Fprint(c.App.ErrWriter, "Password: ")
+	password, err := term.ReadPassword(stdin)
+	fmt.Fprintln(c.App.ErrWriter)
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprint(c.App.ErrWriter, "Repeat password: ")
+	repeated, err := term.ReadPassword(stdin)
+	fmt.Fprintln(c.App.ErrWriter)
+	if err != nil {
+		return "", err
+	}
+	if string(password) != string(repeated) {
+		return "", errors.New("passwords do not match")
+	}
+	return string(password), nil
+}
+
+func banUser(c *cli.Context) error {
+	err := requireArgs(c, 1)
+	if err != nil {
+		return err
+	}
+	stores, err := connectStores()
+	if err != nil {
+		return err
+	}
+	user, err := getUserByName(c, stores.Users, c.Args().First())
+	if err != nil {
+		return err
+	}
+
+	err = stores.Users.SetUserBanned(c.Context, user.ID.String(), true)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(c.App.Writer, "User %s banned\n", user.Name)
+	return nil
+}
+
+func promoteUser(c *cli.Context) error {
+	err := requireArgs(c, 1)
+	if err != nil {
+		return err
+	}
+	role := c.String("role")
+	if !models.CheckIfAllowedRole(role) {
+		return fmt.Errorf("unknown role %s", role)
+	}
+	stores, err := connectStores()
+	if err != nil {
+		return err
+	}
+	user, err := getUserByName(c, stores.Users, c.Args().First())
+	if err != nil {
+		return err
+	}
+
+	err = stores.Users.SetUserRole(c.Context, user.ID.String(), role)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(c.App.Writer, "User %s is now %s\n", user.Name, role)
+	return nil
+}
+
+func getUserByName(c *cli.Context, users store.UserStore, name string) (models.User, error) {
+	user, err := users.GetUserByName(c.Context, name)
+	if errors.Is(err, store.ErrNotFound) {
+		return user, fmt.Errorf("user %s not found", name)
+	}
+	return user, err
+}
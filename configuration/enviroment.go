@@ -1,32 +1,263 @@
 package configuration
 
 import (
+	"fmt"
 	"github.com/spf13/viper"
+	"reflect"
+	"regexp"
+	"strings"
 	"time"
 )
 
 type EnvConfigModel struct {
+	// AppEnv is development or production, development allows settings which are unsafe with real users
+	AppEnv string `mapstructure:"APP_ENV"`
+
+	// DBDriver is postgres or sqlite, SQLite keeps database in SQLitePath file and needs no server
+	DBDriver       string `mapstructure:"DB_DRIVER"`
+	SQLitePath     string `mapstructure:"SQLITE_PATH"`
 	DBHost         string `mapstructure:"POSTGRES_HOST"`
 	DBUserName     string `mapstructure:"POSTGRES_USER"`
 	DBUserPassword string `mapstructure:"POSTGRES_PASSWORD"`
 	DBName         string `mapstructure:"POSTGRES_DB"`
 	DBPort         string `mapstructure:"POSTGRES_PORT"`
+	// DBAutoMigrate applies pending migrations on startup
+	DBAutoMigrate     bool          `mapstructure:"DB_AUTO_MIGRATE"`
+	DBMaxOpenConns    int           `mapstructure:"DB_MAX_OPEN_CONNS"`
+	DBMaxIdleConns    int           `mapstructure:"DB_MAX_IDLE_CONNS"`
+	DBConnMaxLifetime time.Duration `mapstructure:"DB_CONN_MAX_LIFETIME"`
+
+	ListenAddr string `mapstructure:"LISTEN_ADDR"`
+	// ShutdownTimeout is how long in-flight requests and background work may take to finish on shutdown
+	ShutdownTimeout time.Duration `mapstructure:"SHUTDOWN_TIMEOUT"`
+	// CORSAllowOrigins is comma separated list of origins allowed to call API, * allows any origin
+	CORSAllowOrigins string `mapstructure:"CORS_ALLOW_ORIGINS"`
+	// TrustedProxies is comma separated list of IPs and CIDR ranges of load balancers. Client IP is read
+	// from ProxyHeader only in requests coming from them, other requests use address of connection
+	TrustedProxies string `mapstructure:"TRUSTED_PROXIES"`
+	ProxyHeader    string `mapstructure:"PROXY_HEADER"`
+
+	// LogLevel is debug, info, warn or error, debug level logs every database query
+	LogLevel string `mapstructure:"LOG_LEVEL"`
+	// LogFormat is json for log collectors or text for reading in terminal
+	LogFormat string `mapstructure:"LOG_FORMAT"`
+
+	// TracingExporter is none, stdout, memory or otlp. Memory exporter keeps spans for tests
+	TracingExporter     string  `mapstructure:"TRACING_EXPORTER"`
+	TracingOTLPEndpoint string  `mapstructure:"TRACING_OTLP_ENDPOINT"`
+	TracingSampleRatio  float64 `mapstructure:"TRACING_SAMPLE_RATIO"`
+	TracingServiceName  string  `mapstructure:"TRACING_SERVICE_NAME"`
 
 	JwtSecret    string        `mapstructure:"JWT_SECRET_KEY"`
 	JwtExpiresIn time.Duration `mapstructure:"JWT_SECRET_KEY_EXPIRES_IN"`
+
+	JwtAlgorithm      string `mapstructure:"JWT_ALGORITHM"`
+	JwtPrivateKeyFile string `mapstructure:"JWT_PRIVATE_KEY_FILE"`
+	JwtKeyID          string `mapstructure:"JWT_KEY_ID"`
+	JwtPublicKeyFiles string `mapstructure:"JWT_PUBLIC_KEY_FILES"`
+	// JwtLegacyHS256Until is RFC 3339 time until which HS256 tokens are accepted with RS256 or EdDSA algorithm
+	JwtLegacyHS256Until string `mapstructure:"JWT_LEGACY_HS256_UNTIL"`
+	// JwtLegacyAudienceUntil is RFC 3339 time until which access tokens issued without aud claim are accepted
+	JwtLegacyAudienceUntil string `mapstructure:"JWT_LEGACY_AUDIENCE_UNTIL"`
+
+	PasswordMinLength      int    `mapstructure:"PASSWORD_MIN_LENGTH"`
+	PasswordRejectCommon   bool   `mapstructure:"PASSWORD_REJECT_COMMON"`
+	UsernameMinLength      int    `mapstructure:"USERNAME_MIN_LENGTH"`
+	UsernameMaxLength      int    `mapstructure:"USERNAME_MAX_LENGTH"`
+	UsernameAllowedPattern string `mapstructure:"USERNAME_ALLOWED_PATTERN"`
+	// usernamePattern is UsernameAllowedPattern compiled once when configuration is loaded
+	usernamePattern *regexp.Regexp
+
+	LoginThrottleStore      string        `mapstructure:"LOGIN_THROTTLE_STORE"`
+	LoginUserFreeAttempts   int           `mapstructure:"LOGIN_USER_FREE_ATTEMPTS"`
+	LoginIPFreeAttempts     int           `mapstructure:"LOGIN_IP_FREE_ATTEMPTS"`
+	LoginBackoffBase        time.Duration `mapstructure:"LOGIN_BACKOFF_BASE"`
+	LoginLockoutMax         time.Duration `mapstructure:"LOGIN_LOCKOUT_MAX"`
+	LoginAttemptsResetAfter time.Duration `mapstructure:"LOGIN_ATTEMPTS_RESET_AFTER"`
+	// PasswordResetEmailFreeRequests and PasswordResetIPFreeRequests limit reset emails before requests back off
+	PasswordResetEmailFreeRequests int `mapstructure:"PASSWORD_RESET_EMAIL_FREE_REQUESTS"`
+	PasswordResetIPFreeRequests    int `mapstructure:"PASSWORD_RESET_IP_FREE_REQUESTS"`
+
+	TOTPIssuer                  string        `mapstructure:"TOTP_ISSUER"`
+	TwoFactorChallengeExpiresIn time.Duration `mapstructure:"TWO_FACTOR_CHALLENGE_EXPIRES_IN"`
+
+	OIDCIssuer       string `mapstructure:"OIDC_ISSUER"`
+	OIDCClientID     string `mapstructure:"OIDC_CLIENT_ID"`
+	OIDCClientSecret string `mapstructure:"OIDC_CLIENT_SECRET"`
+	OIDCRedirectURL  string `mapstructure:"OIDC_REDIRECT_URL"`
+	OIDCScopes       string `mapstructure:"OIDC_SCOPES"`
+
+	AppBaseURL                 string        `mapstructure:"APP_BASE_URL"`
+	EmailVerificationExpiresIn time.Duration `mapstructure:"EMAIL_VERIFICATION_EXPIRES_IN"`
+	PasswordResetExpiresIn     time.Duration `mapstructure:"PASSWORD_RESET_EXPIRES_IN"`
+	Mailer                     string        `mapstructure:"MAILER"`
+	MailFrom                   string        `mapstructure:"MAIL_FROM"`
+	SMTPHost                   string        `mapstructure:"SMTP_HOST"`
+	SMTPPort                   string        `mapstructure:"SMTP_PORT"`
+	SMTPUsername               string        `mapstructure:"SMTP_USERNAME"`
+	SMTPPassword               string        `mapstructure:"SMTP_PASSWORD"`
+
+	WebhookMaxAttempts          int           `mapstructure:"WEBHOOK_MAX_ATTEMPTS"`
+	WebhookBackoffBase          time.Duration `mapstructure:"WEBHOOK_BACKOFF_BASE"`
+	WebhookBackoffMax           time.Duration `mapstructure:"WEBHOOK_BACKOFF_MAX"`
+	WebhookTimeout              time.Duration `mapstructure:"WEBHOOK_TIMEOUT"`
+	WebhookPollInterval         time.Duration `mapstructure:"WEBHOOK_POLL_INTERVAL"`
+	WebhookAllowPrivateNetworks bool          `mapstructure:"WEBHOOK_ALLOW_PRIVATE_NETWORKS"`
+
+	// NotificationStreamKeepAlive is interval of keep-alive comments of notification stream,
+	// access of user is checked again with each of them
+	NotificationStreamKeepAlive time.Duration `mapstructure:"NOTIFICATION_STREAM_KEEP_ALIVE"`
 }
 
 var EnvConfig EnvConfigModel
 
-func LoadConfig(filePath string) (err error) {
-	viper.SetConfigType("env")
-	viper.SetConfigFile(filePath)
+// LoadConfig loads configuration in layers, each overriding previous one: defaults, env file,
+// environment variables and overrides, which come from command line flags. Empty filePath skips env file.
+// Configuration is validated, so server never starts with settings it can not work with
+func LoadConfig(filePath string, overrides map[string]string) error {
+	v := viper.New()
+
+	//	Settings for local development must be enabled explicitly
+	v.SetDefault("APP_ENV", "production")
+
+	//	Postgres is used unless SQLite is selected
+	v.SetDefault("DB_DRIVER", "postgres")
+	v.SetDefault("SQLITE_PATH", "tiktok-arena.db")
+
+	//	Migrations are applied on startup unless disabled
+	v.SetDefault("DB_AUTO_MIGRATE", true)
+	v.SetDefault("DB_MAX_OPEN_CONNS", 20)
+	v.SetDefault("DB_MAX_IDLE_CONNS", 5)
+	v.SetDefault("DB_CONN_MAX_LIFETIME", "30m")
+
+	//	API is served on port 8000 to any origin
+	v.SetDefault("LISTEN_ADDR", ":8000")
+	v.SetDefault("CORS_ALLOW_ORIGINS", "*")
+	v.SetDefault("SHUTDOWN_TIMEOUT", "15s")
+	v.SetDefault("PROXY_HEADER", "X-Forwarded-For")
+
+	//	Logs are written as JSON lines to stderr
+	v.SetDefault("LOG_LEVEL", "info")
+	v.SetDefault("LOG_FORMAT", "json")
+
+	//	Tracing is disabled, trace context of requests is still passed to outbound calls
+	v.SetDefault("TRACING_EXPORTER", "none")
+	v.SetDefault("TRACING_OTLP_ENDPOINT", "http://localhost:4318")
+	v.SetDefault("TRACING_SAMPLE_RATIO", 1)
+	v.SetDefault("TRACING_SERVICE_NAME", "tiktok-arena")
+
+	//	JWT secret has no default, server refuses to start without it
+	v.SetDefault("JWT_SECRET_KEY_EXPIRES_IN", "24h")
+	v.SetDefault("JWT_ALGORITHM", "HS256")
+
+	//	Credential policy defaults, can be overridden in .env
+	v.SetDefault("PASSWORD_MIN_LENGTH", 8)
+	v.SetDefault("PASSWORD_REJECT_COMMON", true)
+	v.SetDefault("USERNAME_MIN_LENGTH", 3)
+	v.SetDefault("USERNAME_MAX_LENGTH", 32)
+	v.SetDefault("USERNAME_ALLOWED_PATTERN", "^[a-zA-Z0-9_.-]+$")
 
-	viper.AutomaticEnv()
+	//	Login brute-force protection defaults
+	v.SetDefault("LOGIN_THROTTLE_STORE", "memory")
+	v.SetDefault("LOGIN_USER_FREE_ATTEMPTS", 5)
+	v.SetDefault("LOGIN_IP_FREE_ATTEMPTS", 20)
+	v.SetDefault("LOGIN_BACKOFF_BASE", "1s")
+	v.SetDefault("LOGIN_LOCKOUT_MAX", "15m")
+	v.SetDefault("LOGIN_ATTEMPTS_RESET_AFTER", "1h")
+	v.SetDefault("PASSWORD_RESET_EMAIL_FREE_REQUESTS", 3)
+	v.SetDefault("PASSWORD_RESET_IP_FREE_REQUESTS", 10)
 
-	if viper.ReadInConfig() != nil {
-		return
+	//	Two-factor authentication defaults
+	v.SetDefault("TOTP_ISSUER", "TikTok Arena")
+	v.SetDefault("TWO_FACTOR_CHALLENGE_EXPIRES_IN", "5m")
+
+	//	OpenID Connect login is disabled while issuer is empty
+	v.SetDefault("OIDC_SCOPES", "openid profile email")
+
+	//	Email defaults, sending emails is disabled until SMTP server is configured
+	v.SetDefault("APP_BASE_URL", "http://localhost:3000")
+	v.SetDefault("EMAIL_VERIFICATION_EXPIRES_IN", "24h")
+	v.SetDefault("PASSWORD_RESET_EXPIRES_IN", "1h")
+	v.SetDefault("MAILER", "disabled")
+	v.SetDefault("MAIL_FROM", "TikTok Arena <no-reply@localhost>")
+	v.SetDefault("SMTP_PORT", "587")
+
+	//	Webhook delivery defaults, retries of failing delivery span about four hours
+	v.SetDefault("WEBHOOK_MAX_ATTEMPTS", 10)
+	v.SetDefault("WEBHOOK_BACKOFF_BASE", "30s")
+	v.SetDefault("WEBHOOK_BACKOFF_MAX", "6h")
+	v.SetDefault("WEBHOOK_TIMEOUT", "10s")
+	v.SetDefault("WEBHOOK_POLL_INTERVAL", "5s")
+	v.SetDefault("WEBHOOK_ALLOW_PRIVATE_NETWORKS", false)
+
+	v.SetDefault("NOTIFICATION_STREAM_KEEP_ALIVE", "25s")
+
+	if filePath != "" {
+		v.SetConfigType("env")
+		v.SetConfigFile(filePath)
+		err := v.ReadInConfig()
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", filePath, err)
+		}
+	}
+
+	//	Every key is bound explicitly, viper reads environment only for keys it already knows
+	keys := Keys()
+	for key := range keys {
+		err := v.BindEnv(key)
+		if err != nil {
+			return err
+		}
+	}
+
+	for key, value := range overrides {
+		if !keys[key] {
+			return fmt.Errorf("unknown configuration key %s", key)
+		}
+		v.Set(key, value)
+	}
+
+	var config EnvConfigModel
+	err := v.Unmarshal(&config)
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	err = config.Validate()
+	if err != nil {
+		return err
+	}
+	if config.UsernameAllowedPattern != "" {
+		config.usernamePattern = regexp.MustCompile(config.UsernameAllowedPattern)
+	}
+	EnvConfig = config
+	return nil
+}
+
+// TrustedProxyList returns entries of TrustedProxies
+func (c *EnvConfigModel) TrustedProxyList() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(c.TrustedProxies, ",") {
+		proxy = strings.TrimSpace(proxy)
+		if proxy != "" {
+			proxies = append(proxies, proxy)
+		}
 	}
+	return proxies
+}
 
-	return viper.Unmarshal(&EnvConfig)
+// UsernamePattern returns compiled USERNAME_ALLOWED_PATTERN, nil if any username charset is allowed
+func (c *EnvConfigModel) UsernamePattern() *regexp.Regexp {
+	return c.usernamePattern
+}
+
+// Keys returns names of all configuration keys, as used in env file and environment
+func Keys() map[string]bool {
+	keys := make(map[string]bool)
+	model := reflect.TypeOf(EnvConfigModel{})
+	for i := 0; i < model.NumField(); i++ {
+		if key := model.Field(i).Tag.Get("mapstructure"); key != "" {
+			keys[key] = true
+		}
+	}
+	return keys
 }
@@ -0,0 +1,185 @@
+package configuration
+
+import (
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MinJwtSecretLength is length of HS256 secret in bytes, shorter secrets can be brute-forced from issued tokens
+const MinJwtSecretLength = 32
+
+// Validate checks configuration for values server can not work with and reports all of them at once
+func (c *EnvConfigModel) Validate() error {
+	var problems []string
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+	positive := func(key string, value time.Duration) {
+		check(value > 0, "%s must be positive duration, got %s", key, value)
+	}
+
+	check(c.AppEnv == "development" || c.AppEnv == "production",
+		"APP_ENV must be development or production, got %q", c.AppEnv)
+
+	switch c.DBDriver {
+	case "postgres":
+		check(c.DBHost != "", "POSTGRES_HOST is required for postgres driver")
+		check(c.DBUserName != "", "POSTGRES_USER is required for postgres driver")
+		check(c.DBName != "", "POSTGRES_DB is required for postgres driver")
+		check(isPort(c.DBPort), "POSTGRES_PORT must be port number, got %q", c.DBPort)
+	case "sqlite":
+		check(c.SQLitePath != "", "SQLITE_PATH is required for sqlite driver")
+	default:
+		problems = append(problems, fmt.Sprintf("DB_DRIVER must be postgres or sqlite, got %q", c.DBDriver))
+	}
+	check(c.DBMaxOpenConns >= 0, "DB_MAX_OPEN_CONNS must not be negative, got %d", c.DBMaxOpenConns)
+	check(c.DBMaxIdleConns >= 0, "DB_MAX_IDLE_CONNS must not be negative, got %d", c.DBMaxIdleConns)
+	check(c.DBMaxOpenConns == 0 || c.DBMaxIdleConns <= c.DBMaxOpenConns,
+		"DB_MAX_IDLE_CONNS must not exceed DB_MAX_OPEN_CONNS, got %d > %d", c.DBMaxIdleConns, c.DBMaxOpenConns)
+	check(c.DBConnMaxLifetime >= 0, "DB_CONN_MAX_LIFETIME must not be negative, got %s", c.DBConnMaxLifetime)
+
+	_, port, err := net.SplitHostPort(c.ListenAddr)
+	check(err == nil && isPort(port), "LISTEN_ADDR must be host:port or :port, got %q", c.ListenAddr)
+	positive("SHUTDOWN_TIMEOUT", c.ShutdownTimeout)
+	for _, origin := range strings.Split(c.CORSAllowOrigins, ",") {
+		origin = strings.TrimSpace(origin)
+		check(origin == "*" || isOrigin(origin),
+			"CORS_ALLOW_ORIGINS must be * or comma separated origins like https://example.com, got %q", origin)
+	}
+
+	for _, proxy := range c.TrustedProxyList() {
+		_, _, err := net.ParseCIDR(proxy)
+		check(err == nil || net.ParseIP(proxy) != nil, "TRUSTED_PROXIES must be comma separated IPs or CIDR ranges, got %q",
+			proxy)
+	}
+	check(c.TrustedProxies == "" || c.ProxyHeader != "", "PROXY_HEADER is required when TRUSTED_PROXIES is set")
+
+	switch strings.ToLower(c.LogLevel) {
+	case "debug", "info", "warn", "error":
+	default:
+		problems = append(problems, fmt.Sprintf("LOG_LEVEL must be debug, info, warn or error, got %q", c.LogLevel))
+	}
+	check(c.LogFormat == "json" || c.LogFormat == "text", "LOG_FORMAT must be json or text, got %q", c.LogFormat)
+
+	switch c.TracingExporter {
+	case "none", "stdout", "memory":
+	case "otlp":
+		check(isURL(c.TracingOTLPEndpoint), "TRACING_OTLP_ENDPOINT must be absolute URL, got %q", c.TracingOTLPEndpoint)
+	default:
+		problems = append(problems,
+			fmt.Sprintf("TRACING_EXPORTER must be none, stdout, memory or otlp, got %q", c.TracingExporter))
+	}
+	check(c.TracingSampleRatio >= 0 && c.TracingSampleRatio <= 1,
+		"TRACING_SAMPLE_RATIO must be between 0 and 1, got %v", c.TracingSampleRatio)
+	check(c.TracingServiceName != "", "TRACING_SERVICE_NAME must not be empty")
+
+	switch c.JwtAlgorithm {
+	case "HS256":
+		check(len(c.JwtSecret) >= MinJwtSecretLength,
+			"JWT_SECRET_KEY must be at least %d characters long for HS256 algorithm", MinJwtSecretLength)
+	case "RS256", "EdDSA":
+		check(c.JwtPrivateKeyFile != "", "JWT_PRIVATE_KEY_FILE is required for %s algorithm", c.JwtAlgorithm)
+		if c.JwtLegacyHS256Until != "" {
+			_, err := time.Parse(time.RFC3339, c.JwtLegacyHS256Until)
+			check(err == nil, "JWT_LEGACY_HS256_UNTIL must be RFC 3339 time like 2024-01-31T00:00:00Z, got %q",
+				c.JwtLegacyHS256Until)
+			check(len(c.JwtSecret) >= MinJwtSecretLength,
+				"JWT_SECRET_KEY must be at least %d characters long to accept legacy HS256 tokens", MinJwtSecretLength)
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("JWT_ALGORITHM must be HS256, RS256 or EdDSA, got %q", c.JwtAlgorithm))
+	}
+	if c.JwtLegacyAudienceUntil != "" {
+		_, err := time.Parse(time.RFC3339, c.JwtLegacyAudienceUntil)
+		check(err == nil, "JWT_LEGACY_AUDIENCE_UNTIL must be RFC 3339 time like 2024-01-31T00:00:00Z, got %q",
+			c.JwtLegacyAudienceUntil)
+	}
+	positive("JWT_SECRET_KEY_EXPIRES_IN", c.JwtExpiresIn)
+
+	check(c.PasswordMinLength >= 1, "PASSWORD_MIN_LENGTH must be positive, got %d", c.PasswordMinLength)
+	check(c.UsernameMinLength >= 1, "USERNAME_MIN_LENGTH must be positive, got %d", c.UsernameMinLength)
+	check(c.UsernameMaxLength >= c.UsernameMinLength,
+		"USERNAME_MAX_LENGTH must not be less than USERNAME_MIN_LENGTH, got %d < %d",
+		c.UsernameMaxLength, c.UsernameMinLength)
+	_, err = regexp.Compile(c.UsernameAllowedPattern)
+	check(err == nil, "USERNAME_ALLOWED_PATTERN must be regular expression: %v", err)
+
+	switch c.LoginThrottleStore {
+	case "memory", "database":
+	default:
+		problems = append(problems,
+			fmt.Sprintf("LOGIN_THROTTLE_STORE must be memory or database, got %q", c.LoginThrottleStore))
+	}
+	check(c.LoginUserFreeAttempts >= 0, "LOGIN_USER_FREE_ATTEMPTS must not be negative, got %d",
+		c.LoginUserFreeAttempts)
+	check(c.LoginIPFreeAttempts >= 0, "LOGIN_IP_FREE_ATTEMPTS must not be negative, got %d", c.LoginIPFreeAttempts)
+	positive("LOGIN_BACKOFF_BASE", c.LoginBackoffBase)
+	check(c.LoginLockoutMax >= c.LoginBackoffBase, "LOGIN_LOCKOUT_MAX must not be less than LOGIN_BACKOFF_BASE")
+	positive("LOGIN_ATTEMPTS_RESET_AFTER", c.LoginAttemptsResetAfter)
+	check(c.PasswordResetEmailFreeRequests >= 0, "PASSWORD_RESET_EMAIL_FREE_REQUESTS must not be negative, got %d",
+		c.PasswordResetEmailFreeRequests)
+	check(c.PasswordResetIPFreeRequests >= 0, "PASSWORD_RESET_IP_FREE_REQUESTS must not be negative, got %d",
+		c.PasswordResetIPFreeRequests)
+	positive("TWO_FACTOR_CHALLENGE_EXPIRES_IN", c.TwoFactorChallengeExpiresIn)
+
+	if c.OIDCIssuer != "" {
+		check(isURL(c.OIDCIssuer), "OIDC_ISSUER must be absolute URL, got %q", c.OIDCIssuer)
+		check(c.OIDCClientID != "", "OIDC_CLIENT_ID is required when OIDC_ISSUER is set")
+		check(isURL(c.OIDCRedirectURL), "OIDC_REDIRECT_URL must be absolute URL, got %q", c.OIDCRedirectURL)
+	}
+
+	check(isURL(c.AppBaseURL), "APP_BASE_URL must be absolute URL, got %q", c.AppBaseURL)
+	positive("EMAIL_VERIFICATION_EXPIRES_IN", c.EmailVerificationExpiresIn)
+	positive("PASSWORD_RESET_EXPIRES_IN", c.PasswordResetExpiresIn)
+	switch c.Mailer {
+	case "disabled":
+	case "log", "memory":
+		//	Emails carry verification and password reset tokens, they must reach only their recipients
+		check(c.AppEnv == "development", "MAILER %s does not deliver emails, it is allowed only with APP_ENV=development",
+			c.Mailer)
+	case "smtp":
+		check(c.SMTPHost != "", "SMTP_HOST is required for smtp mailer")
+		check(isPort(c.SMTPPort), "SMTP_PORT must be port number, got %q", c.SMTPPort)
+	default:
+		problems = append(problems, fmt.Sprintf("MAILER must be disabled, log, memory or smtp, got %q", c.Mailer))
+	}
+	_, err = mail.ParseAddress(c.MailFrom)
+	check(err == nil, "MAIL_FROM must be email address, got %q", c.MailFrom)
+
+	check(c.WebhookMaxAttempts >= 1, "WEBHOOK_MAX_ATTEMPTS must be positive, got %d", c.WebhookMaxAttempts)
+	positive("WEBHOOK_BACKOFF_BASE", c.WebhookBackoffBase)
+	check(c.WebhookBackoffMax >= c.WebhookBackoffBase, "WEBHOOK_BACKOFF_MAX must not be less than WEBHOOK_BACKOFF_BASE")
+	positive("WEBHOOK_TIMEOUT", c.WebhookTimeout)
+	positive("WEBHOOK_POLL_INTERVAL", c.WebhookPollInterval)
+
+	positive("NOTIFICATION_STREAM_KEEP_ALIVE", c.NotificationStreamKeepAlive)
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid configuration:\n\t%s", strings.Join(problems, "\n\t"))
+	}
+	return nil
+}
+
+func isPort(port string) bool {
+	number, err := strconv.Atoi(port)
+	return err == nil && number >= 1 && number <= 65535
+}
+
+func isURL(value string) bool {
+	parsed, err := url.Parse(value)
+	return err == nil && parsed.Scheme != "" && parsed.Host != ""
+}
+
+// isOrigin checks value is scheme and host without path, as sent by browsers in Origin header
+func isOrigin(value string) bool {
+	parsed, err := url.Parse(value)
+	return err == nil && parsed.Scheme != "" && parsed.Host != "" && parsed.Path == "" && parsed.RawQuery == ""
+}
@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"tiktok-arena/apierror"
+	"tiktok-arena/mailer"
+	"tiktok-arena/models"
+)
+
+// GetUsers
+//
+//	@Summary		List users
+//	@Description	Get page of users, optionally filtered by part of name
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			search	query		string				false	"Part of username"
+//	@Param			page	query		int					false	"Page number, starting from 1"
+//	@Param			count	query		int					false	"Users per page"
+//	@Success		200		{object}	models.UsersPage	"Page of users"
+//	@Failure		500		{object}	ProblemResponseType	"Failed to get users"
+//	@Router			/admin/users [get]
+func (h *Handlers) GetUsers(c *fiber.Ctx) error {
+	pagination := models.NewPagination(c.QueryInt("page", 1), c.QueryInt("count", models.DefaultPageSize))
+
+	users, total, err := h.Users.SearchUsers(c.Context(), c.Query("search"), pagination)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(models.UsersPage{
+		Users: users,
+		Total: total,
+	})
+}
+
+// BanUser
+//
+//	@Summary		Ban user
+//	@Description	Ban user, banned user can not log in or use existing tokens
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			userId	path		string				true	"User id"
+//	@Success		200		{object}	MessageResponseType	"User banned"
+//	@Failure		400		{object}	ProblemResponseType	"Invalid id"
+//	@Failure		403		{object}	ProblemResponseType	"User can not ban themselves"
+//	@Failure		404		{object}	ProblemResponseType	"User not found"
+//	@Router			/admin/users/{userId}/ban [post]
+func (h *Handlers) BanUser(c *fiber.Ctx) error {
+	id, err := idParam(c, "userId")
+	if err != nil {
+		return err
+	}
+	userId := id.String()
+
+	currentUserId, err := currentUserId(c)
+	if err != nil {
+		return err
+	}
+	if currentUserId == userId {
+		return apierror.Forbidden("self_ban", "You can not ban yourself")
+	}
+
+	err = h.Users.SetUserBanned(c.Context(), userId, true)
+	if err != nil {
+		return userUpdateError(userId, err)
+	}
+	return MessageResponse(c, fiber.StatusOK, fmt.Sprintf("User %s banned", userId))
+}
+
+// UnbanUser
+//
+//	@Summary		Unban user
+//	@Description	Lift ban from user
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			userId	path		string				true	"User id"
+//	@Success		200		{object}	MessageResponseType	"User unbanned"
+//	@Failure		400		{object}	ProblemResponseType	"Invalid id"
+//	@Failure		404		{object}	ProblemResponseType	"User not found"
+//	@Router			/admin/users/{userId}/unban [post]
+func (h *Handlers) UnbanUser(c *fiber.Ctx) error {
+	id, err := idParam(c, "userId")
+	if err != nil {
+		return err
+	}
+	userId := id.String()
+
+	err = h.Users.SetUserBanned(c.Context(), userId, false)
+	if err != nil {
+		return userUpdateError(userId, err)
+	}
+	return MessageResponse(c, fiber.StatusOK, fmt.Sprintf("User %s unbanned", userId))
+}
+
+// ForcePasswordReset
+//
+//	@Summary		Force password reset
+//	@Description	Require user to change password before next login and log user out of existing sessions,
+//	@Description	reset link is sent to verified email of user unless sending emails is disabled
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			userId	path		string				true	"User id"
+//	@Success		200		{object}	MessageResponseType	"Password reset forced"
+//	@Failure		400		{object}	ProblemResponseType	"Invalid id"
+//	@Failure		404		{object}	ProblemResponseType	"User not found"
+//	@Failure		502		{object}	ProblemResponseType	"Reset link could not be sent"
+//	@Router			/admin/users/{userId}/reset-password [post]
+func (h *Handlers) ForcePasswordReset(c *fiber.Ctx) error {
+	id, err := idParam(c, "userId")
+	if err != nil {
+		return err
+	}
+	userId := id.String()
+
+	err = h.Users.SetUserMustResetPassword(c.Context(), userId, true)
+	if err != nil {
+		return userUpdateError(userId, err)
+	}
+
+	user, err := h.Users.GetUserById(c.Context(), userId)
+	if err != nil {
+		return userUpdateError(userId, err)
+	}
+	if user.Email == nil || !user.EmailVerified || !mailer.Enabled(h.Mailer) {
+		return MessageResponse(c, fiber.StatusOK, fmt.Sprintf("User %s must reset password", userId))
+	}
+
+	err = h.sendPasswordReset(c.Context(), &user)
+	if err != nil {
+		return apierror.Upstream("email_not_sent",
+			"User %s must reset password, but reset link could not be sent", userId).Wrap(err)
+	}
+	return MessageResponse(c, fiber.StatusOK,
+		fmt.Sprintf("User %s must reset password, reset link sent to email", userId))
+}
+
+// DeleteAnyTournament
+//
+//	@Summary		Delete tournament
+//	@Description	Delete any tournament with its tiktoks
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			tournamentId	path		string				true	"Tournament id"
+//	@Success		200				{object}	MessageResponseType	"Tournament deleted"
+//	@Failure		404				{object}	ProblemResponseType	"Tournament not found"
+//	@Router			/admin/tournaments/{tournamentId} [delete]
+func (h *Handlers) DeleteAnyTournament(c *fiber.Ctx) error {
+	tournamentId := c.Params("tournamentId")
+
+	err := h.Tournaments.DeleteTournament(c.Context(), tournamentId)
+	if err != nil {
+		return storeError(err, tournamentNotFound(tournamentId))
+	}
+	return MessageResponse(c, fiber.StatusOK, fmt.Sprintf("Tournament %s deleted", tournamentId))
+}
+
+func userUpdateError(userId string, err error) error {
+	return storeError(err, apierror.NotFound("user_not_found", "User with id %s not found", userId))
+}
@@ -0,0 +1,143 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"strings"
+	"tiktok-arena/apierror"
+	"tiktok-arena/models"
+	"time"
+)
+
+// GetAPITokens
+//
+//	@Summary		Personal API tokens
+//	@Description	Get personal API tokens of current user, plaintext tokens are not returned
+//	@Tags			auth
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200				{array}		models.APIToken		"API tokens"
+//	@Failure		500				{object}	ProblemResponseType	"Failed to get tokens"
+//	@Router			/auth/tokens	[get]
+func (h *Handlers) GetAPITokens(c *fiber.Ctx) error {
+	userId, err := currentUserId(c)
+	if err != nil {
+		return err
+	}
+
+	tokens, err := h.APITokens.GetUserAPITokens(c.Context(), userId)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(tokens)
+}
+
+// CreateAPIToken
+//
+//	@Summary		Create personal API token
+//	@Description	Create personal API token with given scopes, plaintext token is shown only in this response
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			payload			body		models.CreateAPIToken	true	"Token name, scopes and lifetime"
+//	@Success		201				{object}	models.CreatedAPIToken	"Created token"
+//	@Failure		400				{object}	ProblemResponseType		"Invalid token name, scopes or lifetime"
+//	@Router			/auth/tokens	[post]
+func (h *Handlers) CreateAPIToken(c *fiber.Ctx) error {
+	userId, err := currentUserId(c)
+	if err != nil {
+		return err
+	}
+	ownerId, err := uuid.Parse(userId)
+	if err != nil {
+		return invalidTokenSubject()
+	}
+
+	var payload *models.CreateAPIToken
+
+	err = c.BodyParser(&payload)
+	if err != nil {
+		return invalidBody(err)
+	}
+
+	err = models.ValidateStruct(payload)
+	if err != nil {
+		return validationFailed(err)
+	}
+
+	for _, scope := range payload.Scopes {
+		if !models.CheckIfAllowedScope(scope) {
+			return apierror.Validation("invalid_scope", "%s is not allowed token scope", scope)
+		}
+	}
+
+	plaintext, err := generateAPIToken()
+	if err != nil {
+		return err
+	}
+
+	newToken := models.APIToken{
+		UserID:    &ownerId,
+		Name:      payload.Name,
+		Prefix:    plaintext[:len(models.APITokenPrefix)+6],
+		TokenHash: models.HashAPIToken(plaintext),
+		Scopes:    strings.Join(payload.Scopes, " "),
+	}
+	if payload.ExpiresInDays > 0 {
+		expiresAt := time.Now().UTC().AddDate(0, 0, payload.ExpiresInDays)
+		newToken.ExpiresAt = &expiresAt
+	}
+
+	err = h.APITokens.CreateAPIToken(c.Context(), &newToken)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(models.CreatedAPIToken{
+		APIToken: newToken,
+		Token:    plaintext,
+	})
+}
+
+// DeleteAPIToken
+//
+//	@Summary		Revoke personal API token
+//	@Description	Delete personal API token of current user
+//	@Tags			auth
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			tokenId	path		string				true	"Token id"
+//	@Success		200		{object}	MessageResponseType	"Token revoked"
+//	@Failure		400		{object}	ProblemResponseType	"Invalid id"
+//	@Failure		404		{object}	ProblemResponseType	"Token not found"
+//	@Router			/auth/tokens/{tokenId} [delete]
+func (h *Handlers) DeleteAPIToken(c *fiber.Ctx) error {
+	userId, err := currentUserId(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := idParam(c, "tokenId")
+	if err != nil {
+		return err
+	}
+	tokenId := id.String()
+	err = h.APITokens.DeleteAPIToken(c.Context(), userId, tokenId)
+	if err != nil {
+		return storeError(err, apierror.NotFound("api_token_not_found", "API token with id %s not found", tokenId))
+	}
+	return MessageResponse(c, fiber.StatusOK, fmt.Sprintf("API token %s revoked", tokenId))
+}
+
+func generateAPIToken() (string, error) {
+	random := make([]byte, 32)
+	_, err := rand.Read(random)
+	if err != nil {
+		return "", err
+	}
+	return models.APITokenPrefix + base64.RawURLEncoding.EncodeToString(random), nil
+}
@@ -5,9 +5,15 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v4"
 	"golang.org/x/crypto/bcrypt"
+	"strings"
+	"sync"
+	"tiktok-arena/apierror"
 	"tiktok-arena/configuration"
-	"tiktok-arena/database"
+	"tiktok-arena/jwtkeys"
+	"tiktok-arena/metrics"
+	"tiktok-arena/middleware"
 	"tiktok-arena/models"
+	"tiktok-arena/throttle"
 	"time"
 )
 
@@ -19,57 +25,55 @@ import (
 //	@Produce		json
 //	@Param			payload			body		models.AuthInput		true	"Data to register user"
 //	@Success		200				{object}	models.UserAuthDetails	"Register success"
-//	@Failure		400				{object}	MessageResponseType		"Failed to register user"
+//	@Failure		400				{object}	ProblemResponseType		"Invalid username or password"
+//	@Failure		409				{object}	ProblemResponseType		"User already exists"
 //	@Router			/auth/register	[post]
-func RegisterUser(c *fiber.Ctx) error {
+func (h *Handlers) RegisterUser(c *fiber.Ctx) error {
 	var payload *models.AuthInput
 
 	err := c.BodyParser(&payload)
 	if err != nil {
-		return MessageResponse(c, fiber.StatusBadRequest, err.Error())
+		return invalidBody(err)
 	}
 
 	err = models.ValidateStruct(payload)
 	if err != nil {
-		return MessageResponse(c, fiber.StatusBadRequest, err.Error())
+		return validationFailed(err)
+	}
+
+	err = models.ValidateUsername(payload.Name)
+	if err != nil {
+		return apierror.Validation("invalid_username", "%v", err)
+	}
+
+	err = models.ValidatePassword(payload.Name, payload.Password)
+	if err != nil {
+		return apierror.Validation("invalid_password", "%v", err)
 	}
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(payload.Password), bcrypt.DefaultCost)
 
 	if err != nil {
-		return MessageResponse(c, fiber.StatusBadRequest, err.Error())
+		return err
 	}
 
-	if database.CheckIfUserExists(payload.Name) {
-		return MessageResponse(c, fiber.StatusBadRequest,
-			fmt.Sprintf("User %s already exists", payload.Name))
+	if h.Users.CheckIfUserExists(c.Context(), payload.Name) {
+		return apierror.Conflict("user_exists", "User %s already exists", payload.Name)
 	}
 
 	newUser := models.User{
 		Name:     payload.Name,
 		Password: string(hashedPassword),
+		Role:     models.RoleUser,
 	}
 
-	err = database.CreateNewUser(&newUser)
-
-	if err != nil {
-		return MessageResponse(c, fiber.StatusBadGateway, err.Error())
-	}
-
-	token, err := UserJwtToken(&newUser)
+	err = h.Users.CreateNewUser(c.Context(), &newUser)
 
 	if err != nil {
-		return MessageResponse(c, fiber.StatusBadGateway,
-			fmt.Sprintf("Generating JWT Token failed: %v", err))
+		return err
 	}
 
-	return c.Status(fiber.StatusCreated).JSON(
-		models.UserAuthDetails{
-			ID:       newUser.ID.String(),
-			Username: newUser.Name,
-			Token:    token,
-		},
-	)
+	return userAuthDetailsResponse(c, fiber.StatusCreated, &newUser)
 }
 
 // LoginUser
@@ -79,61 +83,224 @@ func RegisterUser(c *fiber.Ctx) error {
 //	@Accept			json
 //	@Produce		json
 //	@Param			payload			body		models.AuthInput		true	"Data to login user"
-//	@Success		200				{object}	models.UserAuthDetails	"Login success"
-//	@Failure		400				{object}	MessageResponseType		"Error logging in"
+//	@Success		200				{object}	models.UserAuthDetails		"Login success"
+//	@Success		202				{object}	models.TwoFactorChallenge	"Two-factor code required"
+//	@Failure		400				{object}	ProblemResponseType			"Invalid payload"
+//	@Failure		401				{object}	ProblemResponseType			"Invalid credentials"
+//	@Failure		403				{object}	ProblemResponseType			"User is banned or must change password"
+//	@Failure		429				{object}	ProblemResponseType			"Too many failed login attempts"
 //	@Router			/auth/login    	[post]
-func LoginUser(c *fiber.Ctx) error {
+func (h *Handlers) LoginUser(c *fiber.Ctx) error {
 	var payload *models.AuthInput
 
 	err := c.BodyParser(&payload)
 	if err != nil {
-		return MessageResponse(c, fiber.StatusBadRequest, err.Error())
+		return invalidBody(err)
 	}
 
 	err = models.ValidateStruct(payload)
 	if err != nil {
-		return MessageResponse(c, fiber.StatusBadRequest, err.Error())
+		return validationFailed(err)
+	}
+
+	userKey := strings.ToLower(payload.Name)
+	ipKey := c.IP()
+
+	retryAfter, err := loginRetryAfter(userKey, ipKey)
+	if err != nil {
+		return err
+	}
+	if retryAfter > 0 {
+		return tooManyRequests(c, retryAfter, "Too many failed login attempts")
 	}
 
-	user, err := database.GetUserByName(payload.Name)
+	user, err := h.Users.GetUserByName(c.Context(), payload.Name)
+	err = comparePassword(&user, err, payload.Password)
 	if err != nil {
-		return MessageResponse(c, fiber.StatusBadRequest, "Invalid credentials")
+		err = loginFailed(userKey, ipKey)
+		if err != nil {
+			return err
+		}
+		return apierror.Unauthorized("invalid_credentials", "Invalid credentials")
+	}
+
+	if user.Banned {
+		return apierror.Forbidden("user_banned", "User is banned")
+	}
+	if user.MustResetPassword {
+		return apierror.Forbidden("password_reset_required", "Password reset required, change password before logging in")
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(payload.Password))
+	//	Failed attempts are kept until second factor is passed too, otherwise anyone knowing password
+	//	could clear lockout between guesses of two-factor code
+	if user.TOTPEnabled {
+		return twoFactorChallengeResponse(c, &user)
+	}
+
+	err = throttle.LoginByUser.Reset(userKey)
 	if err != nil {
-		return MessageResponse(c, fiber.StatusBadRequest, "Invalid credentials")
+		return err
 	}
 
-	token, err := UserJwtToken(&user)
+	return userAuthDetailsResponse(c, fiber.StatusOK, &user)
+}
+
+func userAuthDetailsResponse(c *fiber.Ctx, status int, user *models.User) error {
+	token, err := UserJwtToken(user)
 
 	if err != nil {
-		return MessageResponse(c, fiber.StatusBadGateway,
-			fmt.Sprintf("Generating JWT Token failed: %v", err))
+		return fmt.Errorf("generating JWT token failed: %w", err)
 	}
 
-	return c.Status(fiber.StatusOK).JSON(
+	return c.Status(status).JSON(
 		models.UserAuthDetails{
 			ID:       user.ID.String(),
 			Username: user.Name,
+			Role:     user.Role,
 			Token:    token,
 		},
 	)
 }
 
+// ChangePassword
+//	@Summary		Change password
+//	@Description	Change password with current credentials, also used when administrator forced password reset.
+//	@Description	Existing sessions and API tokens of user are revoked
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload			body		models.ChangePasswordInput	true	"Current credentials and new password"
+//	@Success		200				{object}	MessageResponseType			"Password changed"
+//	@Failure		400				{object}	ProblemResponseType			"Invalid new password"
+//	@Failure		401				{object}	ProblemResponseType			"Invalid credentials"
+//	@Failure		403				{object}	ProblemResponseType			"User is banned"
+//	@Failure		429				{object}	ProblemResponseType			"Too many failed login attempts"
+//	@Router			/auth/password	[post]
+func (h *Handlers) ChangePassword(c *fiber.Ctx) error {
+	var payload *models.ChangePasswordInput
+
+	err := c.BodyParser(&payload)
+	if err != nil {
+		return invalidBody(err)
+	}
+
+	err = models.ValidateStruct(payload)
+	if err != nil {
+		return validationFailed(err)
+	}
+
+	userKey := strings.ToLower(payload.Name)
+	ipKey := c.IP()
+
+	retryAfter, err := loginRetryAfter(userKey, ipKey)
+	if err != nil {
+		return err
+	}
+	if retryAfter > 0 {
+		return tooManyRequests(c, retryAfter, "Too many failed login attempts")
+	}
+
+	user, err := h.Users.GetUserByName(c.Context(), payload.Name)
+	err = comparePassword(&user, err, payload.Password)
+	if err == nil && user.TOTPEnabled {
+		var valid bool
+		valid, err = h.checkTwoFactorCode(c.Context(), &user, payload.Code)
+		if err == nil && !valid {
+			err = fmt.Errorf("invalid two-factor code")
+		}
+	}
+	if err != nil {
+		err = loginFailed(userKey, ipKey)
+		if err != nil {
+			return err
+		}
+		return apierror.Unauthorized("invalid_credentials", "Invalid credentials")
+	}
+
+	if user.Banned {
+		return apierror.Forbidden("user_banned", "User is banned")
+	}
+
+	err = models.ValidatePassword(user.Name, payload.NewPassword)
+	if err != nil {
+		return apierror.Validation("invalid_password", "%v", err)
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(payload.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	err = h.Users.UpdateUserPassword(c.Context(), user.ID.String(), string(hashedPassword))
+	if err != nil {
+		return err
+	}
+
+	err = throttle.LoginByUser.Reset(userKey)
+	if err != nil {
+		return err
+	}
+
+	return MessageResponse(c, fiber.StatusOK, "Password changed")
+}
+
+// dummyPasswordHash is compared with passwords sent for unknown users
+var (
+	dummyPasswordHash     []byte
+	dummyPasswordHashOnce sync.Once
+)
+
+// comparePassword checks password of user looked up by name. When user was not found password is still
+// compared with dummy hash, so response time does not tell whether user exists
+func comparePassword(user *models.User, lookupErr error, password string) error {
+	if lookupErr != nil {
+		dummyPasswordHashOnce.Do(func() {
+			dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+		})
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+		return lookupErr
+	}
+	return bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
+}
+
+// loginRetryAfter returns how long login is locked for given username or IP
+func loginRetryAfter(userKey string, ipKey string) (time.Duration, error) {
+	userRetryAfter, err := throttle.LoginByUser.RetryAfter(userKey)
+	if err != nil {
+		return 0, err
+	}
+	ipRetryAfter, err := throttle.LoginByIP.RetryAfter(ipKey)
+	if err != nil {
+		return 0, err
+	}
+	if userRetryAfter > ipRetryAfter {
+		return userRetryAfter, nil
+	}
+	return ipRetryAfter, nil
+}
+
+func loginFailed(userKey string, ipKey string) error {
+	metrics.LoginFailures.Inc()
+	err := throttle.LoginByUser.Fail(userKey)
+	if err != nil {
+		return err
+	}
+	return throttle.LoginByIP.Fail(ipKey)
+}
+
 func UserJwtToken(user *models.User) (string, error) {
 	now := time.Now().UTC()
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+	tokenString, err := jwtkeys.Keys.Sign(jwt.MapClaims{
 		"sub":  user.ID,
+		"aud":  jwtkeys.AccessAudience,
 		"name": user.Name,
+		"role": user.Role,
 		"exp":  now.Add(configuration.EnvConfig.JwtExpiresIn).Unix(),
 		"iat":  now.Unix(),
 		"nbf":  now.Unix(),
 	})
 
-	tokenString, err := token.SignedString([]byte(configuration.EnvConfig.JwtSecret))
-
 	if err != nil {
 		return "", err
 	}
@@ -149,9 +316,9 @@ func UserJwtToken(user *models.User) (string, error) {
 //	@Produce		json
 //	@Security		ApiKeyAuth
 //	@Success		200	{object}	models.UserAuthDetails	"User details"
-//	@Failure		400	{object}	MessageResponseType		"Error getting user data"
+//	@Failure		401	{object}	ProblemResponseType		"Invalid or expired token"
 //	@Router			/auth/whoami [get]
-func WhoAmI(c *fiber.Ctx) error {
+func (h *Handlers) WhoAmI(c *fiber.Ctx) error {
 	token := c.Locals("user").(*jwt.Token)
 	claims := token.Claims.(jwt.MapClaims)
 
@@ -161,6 +328,7 @@ func WhoAmI(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(models.UserAuthDetails{
 		ID:       id,
 		Username: username,
+		Role:     middleware.UserRole(c),
 		Token:    token.Raw,
 	})
 }
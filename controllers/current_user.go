@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+	"tiktok-arena/apierror"
+	"tiktok-arena/middleware"
+	"tiktok-arena/models"
+)
+
+// currentUserId returns id of authenticated user from JWT subject
+func currentUserId(c *fiber.Ctx) (string, error) {
+	token := c.Locals("user").(*jwt.Token)
+	claims := token.Claims.(jwt.MapClaims)
+
+	userId, ok := claims["sub"].(string)
+	if !ok {
+		return "", invalidTokenSubject()
+	}
+	return userId, nil
+}
+
+func invalidTokenSubject() *apierror.Error {
+	return apierror.Unauthorized("invalid_token", "Invalid token subject")
+}
+
+// currentUserName returns name of authenticated user from JWT
+func currentUserName(c *fiber.Ctx) string {
+	token := c.Locals("user").(*jwt.Token)
+	claims := token.Claims.(jwt.MapClaims)
+	name, _ := claims["name"].(string)
+	return name
+}
+
+// currentUser loads user identified by JWT subject
+func (h *Handlers) currentUser(c *fiber.Ctx) (models.User, error) {
+	userId, err := currentUserId(c)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	user, err := h.Users.GetUserById(c.Context(), userId)
+	if err != nil {
+		return models.User{}, storeError(err, apierror.NotFound("user_not_found", "Could not get user with id %s", userId))
+	}
+	return user, nil
+}
+
+// currentViewer returns id of authenticated user, empty for anonymous requests,
+// and whether user can see content hidden by moderators
+func currentViewer(c *fiber.Ctx) (string, bool) {
+	token, ok := c.Locals("user").(*jwt.Token)
+	if !ok {
+		return "", false
+	}
+	claims := token.Claims.(jwt.MapClaims)
+	userId, _ := claims["sub"].(string)
+	return userId, models.CanModerate(middleware.UserRole(c))
+}
+
+// canViewTournament reports whether tournament is visible to viewer, hidden tournaments are visible
+// only to their owner and moderators
+func canViewTournament(tournament *models.Tournament, viewerId string, moderator bool) bool {
+	if !tournament.Hidden || moderator {
+		return true
+	}
+	return tournament.UserID != nil && tournament.UserID.String() == viewerId
+}
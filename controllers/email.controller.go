@@ -0,0 +1,401 @@
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/crypto/bcrypt"
+	"net/url"
+	"strings"
+	"tiktok-arena/apierror"
+	"tiktok-arena/configuration"
+	"tiktok-arena/jwtkeys"
+	"tiktok-arena/mailer"
+	"tiktok-arena/models"
+	"tiktok-arena/store"
+	"tiktok-arena/throttle"
+	"time"
+)
+
+const (
+	emailVerificationType = "email_verification"
+	passwordResetType     = "password_reset"
+)
+
+// SetEmail
+//
+//	@Summary		Set email
+//	@Description	Set email of current user and send verification link to it, email is unverified until link is opened
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			payload			body		models.EmailInput	true	"New email"
+//	@Success		200				{object}	MessageResponseType	"Verification link sent"
+//	@Failure		400				{object}	ProblemResponseType	"Invalid email"
+//	@Failure		409				{object}	ProblemResponseType	"Email is used by another account"
+//	@Failure		502				{object}	ProblemResponseType	"Failed to send email"
+//	@Failure		503				{object}	ProblemResponseType	"Sending emails is disabled"
+//	@Router			/auth/email		[put]
+func (h *Handlers) SetEmail(c *fiber.Ctx) error {
+	if !mailer.Enabled(h.Mailer) {
+		return emailDisabled()
+	}
+
+	user, err := h.currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	var payload *models.EmailInput
+
+	err = c.BodyParser(&payload)
+	if err != nil {
+		return invalidBody(err)
+	}
+
+	err = models.ValidateStruct(payload)
+	if err != nil {
+		return validationFailed(err)
+	}
+
+	email := strings.ToLower(strings.TrimSpace(payload.Email))
+	if h.Users.CheckIfEmailTaken(c.Context(), email, user.ID.String()) {
+		return apierror.Conflict("email_taken", "Email is already used by another account")
+	}
+
+	err = h.Users.SetUserEmail(c.Context(), user.ID.String(), email)
+	if err != nil {
+		return err
+	}
+
+	err = h.sendEmailVerification(c.Context(), &user, email)
+	if err != nil {
+		return emailNotSent(err)
+	}
+	return MessageResponse(c, fiber.StatusOK, fmt.Sprintf("Verification link sent to %s", email))
+}
+
+// ResendEmailVerification
+//
+//	@Summary		Resend email verification
+//	@Description	Send new verification link to unverified email of current user
+//	@Tags			auth
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200						{object}	MessageResponseType	"Verification link sent"
+//	@Failure		409						{object}	ProblemResponseType	"Email is not set or already verified"
+//	@Failure		502						{object}	ProblemResponseType	"Failed to send email"
+//	@Failure		503						{object}	ProblemResponseType	"Sending emails is disabled"
+//	@Router			/auth/email/resend		[post]
+func (h *Handlers) ResendEmailVerification(c *fiber.Ctx) error {
+	if !mailer.Enabled(h.Mailer) {
+		return emailDisabled()
+	}
+
+	user, err := h.currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	if user.Email == nil {
+		return apierror.Conflict("email_not_set", "Email is not set")
+	}
+	if user.EmailVerified {
+		return apierror.Conflict("email_verified", "Email is already verified")
+	}
+
+	err = h.sendEmailVerification(c.Context(), &user, *user.Email)
+	if err != nil {
+		return emailNotSent(err)
+	}
+	return MessageResponse(c, fiber.StatusOK, fmt.Sprintf("Verification link sent to %s", *user.Email))
+}
+
+// VerifyEmail
+//
+//	@Summary		Verify email
+//	@Description	Verify email with token from verification link
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload				body		models.EmailVerificationInput	true	"Token from verification link"
+//	@Success		200					{object}	MessageResponseType				"Email verified"
+//	@Failure		400					{object}	ProblemResponseType				"Invalid or expired link"
+//	@Router			/auth/email/verify	[post]
+func (h *Handlers) VerifyEmail(c *fiber.Ctx) error {
+	var payload *models.EmailVerificationInput
+
+	err := c.BodyParser(&payload)
+	if err != nil {
+		return invalidBody(err)
+	}
+
+	err = models.ValidateStruct(payload)
+	if err != nil {
+		return validationFailed(err)
+	}
+
+	claims, err := parseEmailToken(payload.Token, emailVerificationType)
+	if err != nil {
+		return invalidLink("Invalid or expired verification link").Wrap(err)
+	}
+	userId, _ := claims["sub"].(string)
+	email, _ := claims["email"].(string)
+
+	// Link becomes invalid when user changes email after it was sent
+	err = h.Users.VerifyUserEmail(c.Context(), userId, email)
+	if err != nil {
+		return storeError(err, invalidLink("Invalid or expired verification link"))
+	}
+	return MessageResponse(c, fiber.StatusOK, "Email verified")
+}
+
+// ForgotPassword
+//
+//	@Summary		Request password reset
+//	@Description	Send password reset link to verified email. Response is the same whether account exists or not
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload					body		models.ForgotPasswordInput	true	"Account email"
+//	@Success		200						{object}	MessageResponseType			"Reset link sent if account exists"
+//	@Failure		400						{object}	ProblemResponseType			"Invalid email"
+//	@Failure		429						{object}	ProblemResponseType			"Too many password reset requests"
+//	@Failure		502						{object}	ProblemResponseType			"Failed to send email"
+//	@Failure		503						{object}	ProblemResponseType			"Sending emails is disabled"
+//	@Router			/auth/password/forgot	[post]
+func (h *Handlers) ForgotPassword(c *fiber.Ctx) error {
+	if !mailer.Enabled(h.Mailer) {
+		return emailDisabled()
+	}
+
+	var payload *models.ForgotPasswordInput
+
+	err := c.BodyParser(&payload)
+	if err != nil {
+		return invalidBody(err)
+	}
+
+	err = models.ValidateStruct(payload)
+	if err != nil {
+		return validationFailed(err)
+	}
+
+	const response = "If account with this email exists, password reset link was sent to it"
+
+	//	Requests count whether account exists or not, so throttling does not reveal registered emails
+	email := strings.ToLower(strings.TrimSpace(payload.Email))
+	retryAfter, err := passwordResetRetryAfter(email, c.IP())
+	if err != nil {
+		return err
+	}
+	if retryAfter > 0 {
+		return tooManyRequests(c, retryAfter, "Too many password reset requests")
+	}
+	err = throttle.PasswordResetByEmail.Count(email)
+	if err != nil {
+		return err
+	}
+	err = throttle.PasswordResetByIP.Count(c.IP())
+	if err != nil {
+		return err
+	}
+
+	user, err := h.Users.GetUserByEmail(c.Context(), email)
+	if errors.Is(err, store.ErrNotFound) || (err == nil && user.Banned) {
+		return MessageResponse(c, fiber.StatusOK, response)
+	}
+	if err != nil {
+		return err
+	}
+
+	err = h.sendPasswordReset(c.Context(), &user)
+	if err != nil {
+		return emailNotSent(err)
+	}
+	return MessageResponse(c, fiber.StatusOK, response)
+}
+
+// ResetPassword
+//
+//	@Summary		Reset password
+//	@Description	Set new password with token from password reset link, link can be used only once.
+//	@Description	Existing sessions and API tokens of user are revoked
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload					body		models.ResetPasswordInput	true	"Token from reset link and new password"
+//	@Success		200						{object}	MessageResponseType			"Password changed"
+//	@Failure		400						{object}	ProblemResponseType			"Invalid or expired link or invalid password"
+//	@Failure		403						{object}	ProblemResponseType			"User is banned"
+//	@Router			/auth/password/reset	[post]
+func (h *Handlers) ResetPassword(c *fiber.Ctx) error {
+	var payload *models.ResetPasswordInput
+
+	err := c.BodyParser(&payload)
+	if err != nil {
+		return invalidBody(err)
+	}
+
+	err = models.ValidateStruct(payload)
+	if err != nil {
+		return validationFailed(err)
+	}
+
+	claims, err := parseEmailToken(payload.Token, passwordResetType)
+	if err != nil {
+		return invalidLink("Invalid or expired password reset link").Wrap(err)
+	}
+	userId, _ := claims["sub"].(string)
+
+	user, err := h.Users.GetUserById(c.Context(), userId)
+	if err != nil {
+		return storeError(err, invalidLink("Invalid or expired password reset link"))
+	}
+	if claims["pwd"] != passwordFingerprint(&user) {
+		return invalidLink("Invalid or expired password reset link")
+	}
+
+	if user.Banned {
+		return apierror.Forbidden("user_banned", "User is banned")
+	}
+
+	err = models.ValidatePassword(user.Name, payload.NewPassword)
+	if err != nil {
+		return apierror.Validation("invalid_password", "%v", err)
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(payload.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	err = h.Users.UpdateUserPassword(c.Context(), user.ID.String(), string(hashedPassword))
+	if err != nil {
+		return err
+	}
+
+	err = throttle.LoginByUser.Reset(strings.ToLower(user.Name))
+	if err != nil {
+		return err
+	}
+
+	return MessageResponse(c, fiber.StatusOK, "Password changed")
+}
+
+// passwordResetRetryAfter returns how long password reset is locked for given email or IP
+func passwordResetRetryAfter(email string, ip string) (time.Duration, error) {
+	emailRetryAfter, err := throttle.PasswordResetByEmail.RetryAfter(email)
+	if err != nil {
+		return 0, err
+	}
+	ipRetryAfter, err := throttle.PasswordResetByIP.RetryAfter(ip)
+	if err != nil {
+		return 0, err
+	}
+	if emailRetryAfter > ipRetryAfter {
+		return emailRetryAfter, nil
+	}
+	return ipRetryAfter, nil
+}
+
+// invalidLink is returned for tokens from links which are malformed, expired or already used
+func invalidLink(message string) *apierror.Error {
+	return apierror.Validation("invalid_link", "%s", message)
+}
+
+// emailDisabled is returned by endpoints which send emails while MAILER is disabled
+func emailDisabled() *apierror.Error {
+	return apierror.Unavailable("email_disabled", "Sending emails is disabled on this server")
+}
+
+func emailNotSent(err error) *apierror.Error {
+	return apierror.Upstream("email_not_sent", "Could not send email").Wrap(err)
+}
+
+func (h *Handlers) sendEmailVerification(ctx context.Context, user *models.User, email string) error {
+	config := configuration.EnvConfig
+	token, err := signEmailToken(user, emailVerificationType, config.EmailVerificationExpiresIn, jwt.MapClaims{
+		"email": email,
+	})
+	if err != nil {
+		return err
+	}
+
+	return h.Mailer.Send(ctx, mailer.Message{
+		To:      email,
+		Subject: "Verify your email",
+		Body: fmt.Sprintf("Hi %s,\n\nOpen this link to verify your email:\n%s\n\nLink expires in %s.\n",
+			user.Name, appLink("/verify-email", token), config.EmailVerificationExpiresIn),
+	})
+}
+
+// sendPasswordReset sends reset link to verified email of user
+func (h *Handlers) sendPasswordReset(ctx context.Context, user *models.User) error {
+	if user.Email == nil || !user.EmailVerified {
+		return fmt.Errorf("user %s has no verified email", user.Name)
+	}
+
+	config := configuration.EnvConfig
+	token, err := signEmailToken(user, passwordResetType, config.PasswordResetExpiresIn, jwt.MapClaims{
+		"pwd": passwordFingerprint(user),
+	})
+	if err != nil {
+		return err
+	}
+
+	return h.Mailer.Send(ctx, mailer.Message{
+		To:      *user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nOpen this link to set new password:\n%s\n\n"+
+			"Link expires in %s. If you did not request password reset, ignore this email.\n",
+			user.Name, appLink("/reset-password", token), config.PasswordResetExpiresIn),
+	})
+}
+
+func signEmailToken(user *models.User, tokenType string, expiresIn time.Duration, extra jwt.MapClaims) (string, error) {
+	now := time.Now().UTC()
+	claims := jwt.MapClaims{
+		"sub": user.ID.String(),
+		"typ": tokenType,
+		"aud": jwtkeys.Audience(tokenType),
+		"exp": now.Add(expiresIn).Unix(),
+		"iat": now.Unix(),
+		"nbf": now.Unix(),
+	}
+	for key, value := range extra {
+		claims[key] = value
+	}
+	return jwtkeys.Keys.Sign(claims)
+}
+
+func parseEmailToken(tokenString string, tokenType string) (jwt.MapClaims, error) {
+	token, err := jwtkeys.Keys.ParseFor(tokenString, jwtkeys.Audience(tokenType))
+	if err != nil {
+		return nil, err
+	}
+	claims := token.Claims.(jwt.MapClaims)
+	if claims["typ"] != tokenType {
+		return nil, fmt.Errorf("not a %s token", tokenType)
+	}
+	if _, ok := claims["sub"].(string); !ok {
+		return nil, fmt.Errorf("token has no subject")
+	}
+	return claims, nil
+}
+
+// passwordFingerprint changes with every password change, so reset link stops working once it was used
+func passwordFingerprint(user *models.User) string {
+	sum := sha256.Sum256([]byte(user.Password))
+	return hex.EncodeToString(sum[:8])
+}
+
+func appLink(path string, token string) string {
+	baseURL := strings.TrimRight(configuration.EnvConfig.AppBaseURL, "/")
+	return fmt.Sprintf("%s%s?token=%s", baseURL, path, url.QueryEscape(token))
+}
@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/google/uuid"
+	"math"
+	"strconv"
+	"strings"
+	"tiktok-arena/apierror"
+	"tiktok-arena/logging"
+	"tiktok-arena/models"
+	"tiktok-arena/store"
+	"time"
+)
+
+const problemContentType = "application/problem+json"
+
+// ProblemResponseType is body of every failed request, RFC 7807 problem details
+// with machine-readable code and ID of request in server logs
+type ProblemResponseType struct {
+	Type      string      `json:"type" example:"about:blank"`
+	Title     string      `json:"title" example:"Not Found"`
+	Status    int         `json:"status" example:"404"`
+	Detail    string      `json:"detail" example:"Could not get tournament with id 7c4a9b6e-6f0e-4c1a-9d53-0f4b2c1d8e7a"`
+	Instance  string      `json:"instance" example:"/api/tournament/7c4a9b6e-6f0e-4c1a-9d53-0f4b2c1d8e7a"`
+	Code      string      `json:"code" example:"tournament_not_found"`
+	RequestID string      `json:"requestId"`
+	Errors    interface{} `json:"errors,omitempty"`
+}
+
+// ErrorHandler responds to errors returned by handlers and middleware with problem details.
+// Status is taken from kind of apierror.Error, other errors are server errors and their message is only logged
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	var apiErr *apierror.Error
+	var fiberErr *fiber.Error
+	var status int
+	switch {
+	case errors.As(err, &apiErr):
+		status = apiErr.Kind.Status()
+	case errors.Is(err, store.ErrNotFound):
+		apiErr = apierror.NotFound("not_found", "Requested resource does not exist").Wrap(err)
+		status = fiber.StatusNotFound
+	case errors.Is(err, store.ErrConflict):
+		apiErr = apierror.Conflict("conflict", "Resource was changed by another request, try again").Wrap(err)
+		status = fiber.StatusConflict
+	case errors.Is(err, store.ErrForbidden):
+		apiErr = apierror.Forbidden("forbidden", "Action is not permitted").Wrap(err)
+		status = fiber.StatusForbidden
+	case errors.As(err, &fiberErr):
+		//	Errors of Fiber itself, e.g. unknown route or too large body
+		apiErr = apierror.BadRequest(statusCode(fiberErr.Code), "%s", fiberErr.Message)
+		status = fiberErr.Code
+	default:
+		apiErr = apierror.Internal(err)
+		status = fiber.StatusInternalServerError
+	}
+	logging.SetError(c, err)
+
+	err = c.Status(status).JSON(ProblemResponseType{
+		Type:   "about:blank",
+		Title:  utils.StatusMessage(status),
+		Status: status,
+		Detail: apiErr.Message,
+		//	Query is left out, notification stream gets access token in it
+		Instance:  c.Path(),
+		Code:      apiErr.Code,
+		RequestID: logging.RequestID(c.Context()),
+		Errors:    apiErr.Errors,
+	})
+	c.Set(fiber.HeaderContentType, problemContentType)
+	return err
+}
+
+// ErrorMiddleware responds to errors of next handlers with ErrorHandler, so logging,
+// metrics and tracing middleware registered before it see final status of response
+func ErrorMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		err := c.Next()
+		if err != nil {
+			return c.App().ErrorHandler(c, err)
+		}
+		return nil
+	}
+}
+
+// statusCode turns HTTP status into error code, e.g. 404 into not_found
+func statusCode(status int) string {
+	return strings.ReplaceAll(strings.ToLower(utils.StatusMessage(status)), " ", "_")
+}
+
+// tooManyRequests sets Retry-After header rounded up to whole seconds and returns error responded with 429
+func tooManyRequests(c *fiber.Ctx, retryAfter time.Duration, message string) error {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(seconds))
+	return apierror.TooManyRequests("too_many_requests", "%s", message)
+}
+
+// invalidBody is returned when request body can not be parsed
+func invalidBody(err error) *apierror.Error {
+	return apierror.BadRequest("invalid_body", "%v", err)
+}
+
+// validationFailed is returned when payload does not pass validation,
+// invalid fields are listed in errors of response so client can highlight them
+func validationFailed(err error) *apierror.Error {
+	var fieldErrs models.ValidationErrors
+	if errors.As(err, &fieldErrs) {
+		return apierror.Validation("validation_failed", "Payload has invalid fields").WithErrors(fieldErrs).Wrap(err)
+	}
+	return apierror.Validation("validation_failed", "%v", err)
+}
+
+// idParam parses UUID path parameter with given name, e.g. userId. Malformed id is rejected before it
+// reaches store, where Postgres would fail on it with server error and memory store would not find it
+func idParam(c *fiber.Ctx, name string) (uuid.UUID, error) {
+	id, err := uuid.Parse(c.Params(name))
+	if err != nil {
+		return uuid.UUID{}, apierror.Validation("invalid_id", "%s %s is not a valid id", name, c.Params(name))
+	}
+	return id, nil
+}
+
+// storeError returns notFound if record does not exist, other errors of stores are server errors
+func storeError(err error, notFound *apierror.Error) error {
+	if errors.Is(err, store.ErrNotFound) {
+		return notFound.Wrap(err)
+	}
+	return err
+}
@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"tiktok-arena/apierror"
+	"tiktok-arena/events"
+	"tiktok-arena/models"
+)
+
+// FollowUser
+//
+//	@Summary		Follow user
+//	@Description	Follow user to see their new tournaments and contest results in feed
+//	@Tags			user
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			name					path		string				true	"Username"
+//	@Success		200						{object}	MessageResponseType	"User followed"
+//	@Failure		403						{object}	ProblemResponseType	"User can not follow themselves"
+//	@Failure		404						{object}	ProblemResponseType	"User not found"
+//	@Router			/users/{name}/follow	[post]
+func (h *Handlers) FollowUser(c *fiber.Ctx) error {
+	follower, err := h.currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	name := c.Params("name")
+	followee, err := h.Users.GetUserByName(c.Context(), name)
+	if err != nil {
+		return storeError(err, userNotFound(name))
+	}
+	if followee.Banned {
+		return userNotFound(name)
+	}
+	if *followee.ID == *follower.ID {
+		return apierror.Forbidden("self_follow", "You can not follow yourself")
+	}
+
+	created, err := h.Users.FollowUser(c.Context(), &models.Follow{
+		FollowerID: follower.ID,
+		FolloweeID: followee.ID,
+	})
+	if err != nil {
+		return err
+	}
+	if !created {
+		return MessageResponse(c, fiber.StatusOK, fmt.Sprintf("You already follow %s", followee.Name))
+	}
+
+	h.Events.Publish(c.Context(), events.Event{
+		Type:        events.UserFollowed,
+		RecipientID: followee.ID,
+		ActorID:     follower.ID,
+		ActorName:   follower.Name,
+		TargetType:  "user",
+		TargetID:    followee.ID,
+		TargetName:  followee.Name,
+	})
+	return MessageResponse(c, fiber.StatusOK, fmt.Sprintf("You follow %s", followee.Name))
+}
+
+// UnfollowUser
+//
+//	@Summary		Unfollow user
+//	@Description	Stop following user
+//	@Tags			user
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			name					path		string				true	"Username"
+//	@Success		200						{object}	MessageResponseType	"User unfollowed"
+//	@Failure		404						{object}	ProblemResponseType	"User not found"
+//	@Router			/users/{name}/follow	[delete]
+func (h *Handlers) UnfollowUser(c *fiber.Ctx) error {
+	followerId, err := currentUserId(c)
+	if err != nil {
+		return err
+	}
+
+	name := c.Params("name")
+	followee, err := h.Users.GetUserByName(c.Context(), name)
+	if err != nil {
+		return storeError(err, userNotFound(name))
+	}
+
+	_, err = h.Users.UnfollowUser(c.Context(), followerId, followee.ID.String())
+	if err != nil {
+		return err
+	}
+	return MessageResponse(c, fiber.StatusOK, fmt.Sprintf("You do not follow %s", followee.Name))
+}
+
+// GetFeed
+//
+//	@Summary		Feed
+//	@Description	Get new public tournaments and contest results of followed users, newest first.
+//	@Description	Pass NextCursor of previous page as cursor to get older items
+//	@Tags			user
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			cursor		query		string				false	"Cursor from previous page"
+//	@Param			count		query		int					false	"Items per page"
+//	@Success		200			{object}	models.FeedPage		"Page of feed"
+//	@Failure		400			{object}	ProblemResponseType	"Invalid cursor"
+//	@Router			/feed		[get]
+func (h *Handlers) GetFeed(c *fiber.Ctx) error {
+	userId, err := currentUserId(c)
+	if err != nil {
+		return err
+	}
+
+	var cursor *models.FeedCursor
+	if c.Query("cursor") != "" {
+		parsedCursor, err := models.ParseFeedCursor(c.Query("cursor"))
+		if err != nil {
+			return apierror.BadRequest("invalid_cursor", "%v", err)
+		}
+		cursor = &parsedCursor
+	}
+
+	count := models.NewPagination(1, c.QueryInt("count", models.DefaultPageSize)).Count
+	items, err := h.Tournaments.GetFeed(c.Context(), userId, cursor, count)
+	if err != nil {
+		return err
+	}
+
+	page := models.FeedPage{Items: items}
+	if len(items) == count {
+		last := items[len(items)-1]
+		page.NextCursor = models.FeedCursor{CreatedAt: last.CreatedAt, ID: *last.ID}.Encode()
+	}
+	return c.Status(fiber.StatusOK).JSON(page)
+}
@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"tiktok-arena/events"
+	"tiktok-arena/health"
+	"tiktok-arena/mailer"
+	"tiktok-arena/notifications"
+	"tiktok-arena/store"
+	"tiktok-arena/webhooks"
+)
+
+// Handlers holds dependencies of HTTP handlers, every handler is a method of it.
+// Stores are injected, so API can be served from database or from memory in tests
+type Handlers struct {
+	Users         store.UserStore
+	Tournaments   store.TournamentStore
+	Tiktoks       store.TiktokStore
+	APITokens     store.APITokenStore
+	Moderation    store.ModerationStore
+	Notifications store.NotificationStore
+	Webhooks      store.WebhookStore
+
+	Events     events.Publisher
+	Mailer     mailer.Mailer
+	Dispatcher *webhooks.Dispatcher
+	// Live streams notifications to connected users
+	Live   *notifications.Hub
+	Health *health.Checker
+}
+
+func NewHandlers(
+	stores store.Stores,
+	publisher events.Publisher,
+	mail mailer.Mailer,
+	dispatcher *webhooks.Dispatcher,
+	live *notifications.Hub,
+	checker *health.Checker,
+) *Handlers {
+	return &Handlers{
+		Users:         stores.Users,
+		Tournaments:   stores.Tournaments,
+		Tiktoks:       stores.Tiktoks,
+		APITokens:     stores.APITokens,
+		Moderation:    stores.Moderation,
+		Notifications: stores.Notifications,
+		Webhooks:      stores.Webhooks,
+		Events:        publisher,
+		Mailer:        mail,
+		Dispatcher:    dispatcher,
+		Live:          live,
+		Health:        checker,
+	}
+}
@@ -0,0 +1,34 @@
+package controllers
+
+import (
+	"context"
+	"github.com/gofiber/fiber/v2"
+	"tiktok-arena/models"
+	"time"
+)
+
+// readinessTimeout bounds readiness checks, so unreachable database fails probe instead of hanging it
+const readinessTimeout = 3 * time.Second
+
+// Healthz reports that process is alive. Dependencies are not checked, so orchestrator restarts instance
+// only when it stops responding, not when database is down
+func (h *Handlers) Healthz(c *fiber.Ctx) error {
+	return MessageResponse(c, fiber.StatusOK, "OK")
+}
+
+// Readyz reports whether instance can serve traffic: database is reachable, its schema is current
+// and background workers are running. Instance that is shutting down is not ready
+func (h *Handlers) Readyz(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.Context(), readinessTimeout)
+	defer cancel()
+
+	checks, ready := h.Health.Check(ctx)
+	status := fiber.StatusOK
+	if !ready {
+		status = fiber.StatusServiceUnavailable
+	}
+	return c.Status(status).JSON(models.Readiness{
+		Ready:  ready,
+		Checks: checks,
+	})
+}
@@ -0,0 +1,14 @@
+package controllers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"tiktok-arena/jwtkeys"
+)
+
+// GetJWKS publishes public keys for verification of user tokens, so other services can verify them
+// without knowing any secret. Key set is empty when tokens are signed with HS256 secret.
+// Services must also check that aud claim is jwtkeys.AccessAudience, other tokens are signed with the same keys
+func (h *Handlers) GetJWKS(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.Status(fiber.StatusOK).JSON(jwtkeys.Keys.JWKS())
+}
@@ -0,0 +1,288 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"tiktok-arena/apierror"
+	"tiktok-arena/events"
+	"tiktok-arena/models"
+	"tiktok-arena/store"
+)
+
+// CreateReport
+//
+//	@Summary		Report content
+//	@Description	Report tournament or tiktok to moderators
+//	@Tags			moderation
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			payload	body		models.CreateReport	true	"Reported content and reason"
+//	@Success		201		{object}	models.Report		"Report created"
+//	@Failure		400		{object}	ProblemResponseType	"Invalid report"
+//	@Failure		404		{object}	ProblemResponseType	"Reported content not found"
+//	@Failure		409		{object}	ProblemResponseType	"Content is already reported by current user"
+//	@Router			/reports [post]
+func (h *Handlers) CreateReport(c *fiber.Ctx) error {
+	userId, err := currentUserId(c)
+	if err != nil {
+		return err
+	}
+	reporterId, err := uuid.Parse(userId)
+	if err != nil {
+		return invalidTokenSubject()
+	}
+
+	var payload *models.CreateReport
+
+	err = c.BodyParser(&payload)
+	if err != nil {
+		return invalidBody(err)
+	}
+
+	err = models.ValidateStruct(payload)
+	if err != nil {
+		return validationFailed(err)
+	}
+
+	if !models.CheckIfAllowedReportTargetType(payload.TargetType) {
+		return apierror.Validation("invalid_target_type", "%s is not allowed report target type", payload.TargetType)
+	}
+	if !models.CheckIfAllowedReportReason(payload.Reason) {
+		return apierror.Validation("invalid_reason", "%s is not allowed report reason", payload.Reason)
+	}
+
+	targetId, err := uuid.Parse(payload.TargetID)
+	if err != nil {
+		return apierror.Validation("invalid_target_id", "%s is not a valid target id", payload.TargetID)
+	}
+
+	_, err = h.Moderation.GetContentAuthorId(c.Context(), payload.TargetType, payload.TargetID)
+	if err != nil {
+		return storeError(err, apierror.NotFound("target_not_found",
+			"Could not get %s with id %s", payload.TargetType, payload.TargetID))
+	}
+
+	if h.Moderation.CheckIfOpenReportExists(c.Context(), userId, payload.TargetType, payload.TargetID) {
+		return apierror.Conflict("already_reported", "You already reported %s %s", payload.TargetType, payload.TargetID)
+	}
+
+	newReport := models.Report{
+		ReporterID: &reporterId,
+		TargetType: payload.TargetType,
+		TargetID:   &targetId,
+		Reason:     payload.Reason,
+		Details:    payload.Details,
+		Status:     models.ReportStatusOpen,
+	}
+	err = h.Moderation.CreateReport(c.Context(), &newReport)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(newReport)
+}
+
+// GetReports
+//
+//	@Summary		Moderation queue
+//	@Description	Get page of reports with given status, oldest first
+//	@Tags			moderation
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			status	query		string				false	"Report status, open by default"
+//	@Param			page	query		int					false	"Page number, starting from 1"
+//	@Param			count	query		int					false	"Reports per page"
+//	@Success		200		{object}	models.ReportsPage	"Page of reports"
+//	@Failure		400		{object}	ProblemResponseType	"Invalid status"
+//	@Router			/moderation/reports [get]
+func (h *Handlers) GetReports(c *fiber.Ctx) error {
+	status := c.Query("status", models.ReportStatusOpen)
+	if !models.CheckIfAllowedReportStatus(status) {
+		return apierror.BadRequest("invalid_status", "%s is not allowed report status", status)
+	}
+	pagination := models.NewPagination(c.QueryInt("page", 1), c.QueryInt("count", models.DefaultPageSize))
+
+	reports, total, err := h.Moderation.GetReports(c.Context(), status, pagination)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(models.ReportsPage{
+		Reports: reports,
+		Total:   total,
+	})
+}
+
+// ResolveReport
+//
+//	@Summary		Resolve report
+//	@Description	Close report as handled without acting on content
+//	@Tags			moderation
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			reportId	path		string					true	"Report id"
+//	@Param			payload		body		models.ModerationInput	false	"Moderator note"
+//	@Success		200			{object}	MessageResponseType		"Report resolved"
+//	@Failure		400			{object}	ProblemResponseType		"Invalid id"
+//	@Failure		404			{object}	ProblemResponseType		"Report not found"
+//	@Failure		409			{object}	ProblemResponseType		"Report is already closed"
+//	@Router			/moderation/reports/{reportId}/resolve [post]
+func (h *Handlers) ResolveReport(c *fiber.Ctx) error {
+	return h.closeReport(c, models.ModerationResolve, models.ReportStatusResolved)
+}
+
+// DismissReport
+//
+//	@Summary		Dismiss report
+//	@Description	Close report as unfounded
+//	@Tags			moderation
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			reportId	path		string					true	"Report id"
+//	@Param			payload		body		models.ModerationInput	false	"Moderator note"
+//	@Success		200			{object}	MessageResponseType		"Report dismissed"
+//	@Failure		400			{object}	ProblemResponseType		"Invalid id"
+//	@Failure		404			{object}	ProblemResponseType		"Report not found"
+//	@Failure		409			{object}	ProblemResponseType		"Report is already closed"
+//	@Router			/moderation/reports/{reportId}/dismiss [post]
+func (h *Handlers) DismissReport(c *fiber.Ctx) error {
+	return h.closeReport(c, models.ModerationDismiss, models.ReportStatusDismissed)
+}
+
+// ActOnReport
+//
+//	@Summary		Act on report
+//	@Description	Hide reported content, warn or ban its author and resolve report
+//	@Tags			moderation
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			reportId	path		string					true	"Report id"
+//	@Param			payload		body		models.ModerationInput	true	"Action (hide, warn or ban) and moderator note"
+//	@Success		200			{object}	MessageResponseType		"Action applied"
+//	@Failure		400			{object}	ProblemResponseType		"Invalid id or action"
+//	@Failure		403			{object}	ProblemResponseType		"Author can be banned only by administrator"
+//	@Failure		404			{object}	ProblemResponseType		"Report not found"
+//	@Failure		409			{object}	ProblemResponseType		"Report is already closed or author is unknown"
+//	@Router			/moderation/reports/{reportId}/action [post]
+func (h *Handlers) ActOnReport(c *fiber.Ctx) error {
+	return h.closeReport(c, "", models.ReportStatusResolved)
+}
+
+// GetModerationActions
+//
+//	@Summary		Moderation log
+//	@Description	Get page of recorded moderation actions, newest first
+//	@Tags			moderation
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			page	query		int								false	"Page number, starting from 1"
+//	@Param			count	query		int								false	"Actions per page"
+//	@Success		200		{object}	models.ModerationActionsPage	"Page of actions"
+//	@Failure		500		{object}	ProblemResponseType				"Failed to get actions"
+//	@Router			/moderation/actions [get]
+func (h *Handlers) GetModerationActions(c *fiber.Ctx) error {
+	pagination := models.NewPagination(c.QueryInt("page", 1), c.QueryInt("count", models.DefaultPageSize))
+
+	actions, total, err := h.Moderation.GetModerationActions(c.Context(), pagination)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(models.ModerationActionsPage{
+		Actions: actions,
+		Total:   total,
+	})
+}
+
+// closeReport records moderation action and closes report with given status.
+// Empty action means action is taken from payload and must be one of content actions
+func (h *Handlers) closeReport(c *fiber.Ctx, action string, status string) error {
+	userId, err := currentUserId(c)
+	if err != nil {
+		return err
+	}
+	moderatorId, err := uuid.Parse(userId)
+	if err != nil {
+		return invalidTokenSubject()
+	}
+
+	var payload models.ModerationInput
+	if len(c.Body()) > 0 {
+		err = c.BodyParser(&payload)
+		if err != nil {
+			return invalidBody(err)
+		}
+	}
+
+	err = models.ValidateStruct(payload)
+	if err != nil {
+		return validationFailed(err)
+	}
+
+	if action == "" {
+		if !models.CheckIfAllowedContentAction(payload.Action) {
+			return apierror.Validation("invalid_action", "%s is not allowed moderation action", payload.Action)
+		}
+		action = payload.Action
+	}
+
+	id, err := idParam(c, "reportId")
+	if err != nil {
+		return err
+	}
+	reportId := id.String()
+	report, err := h.Moderation.GetReportById(c.Context(), reportId)
+	if err != nil {
+		return storeError(err, apierror.NotFound("report_not_found", "Could not get report with id %s", reportId))
+	}
+	if report.Status != models.ReportStatusOpen {
+		return apierror.Conflict("report_closed", "Report %s is already %s", reportId, report.Status)
+	}
+
+	//	Content may be already deleted, author is unknown in that case
+	authorId, _ := h.Moderation.GetContentAuthorId(c.Context(), report.TargetType, report.TargetID.String())
+	if action == models.ModerationBanAuthor && authorId == nil {
+		return apierror.Conflict("author_not_found", "Could not find author of reported content")
+	}
+
+	moderationAction := models.ModerationAction{
+		ReportID:    report.ID,
+		ModeratorID: &moderatorId,
+		Action:      action,
+		TargetType:  report.TargetType,
+		TargetID:    report.TargetID,
+		AuthorID:    authorId,
+		Note:        payload.Note,
+	}
+	err = h.Moderation.CloseReport(c.Context(), &report, &moderationAction, status)
+	if errors.Is(err, store.ErrForbidden) {
+		return apierror.Forbidden("author_protected",
+			"Only administrators can ban moderators and administrators, moderators can not ban themselves").Wrap(err)
+	}
+	if err != nil {
+		return err
+	}
+
+	if authorId != nil && models.CheckIfAllowedContentAction(action) {
+		title, _ := h.Moderation.GetContentTitle(c.Context(), report.TargetType, report.TargetID.String())
+		h.Events.Publish(c.Context(), events.Event{
+			Type:        events.ModerationActionTaken,
+			RecipientID: authorId,
+			ActorID:     &moderatorId,
+			TargetType:  report.TargetType,
+			TargetID:    report.TargetID,
+			TargetName:  title,
+			Action:      action,
+			Note:        payload.Note,
+		})
+	}
+
+	return MessageResponse(c, fiber.StatusOK,
+		fmt.Sprintf("Report %s %s, action %s recorded", reportId, status, action))
+}
@@ -0,0 +1,203 @@
+package controllers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+	"tiktok-arena/apierror"
+	"tiktok-arena/configuration"
+	"tiktok-arena/middleware"
+	"tiktok-arena/models"
+	"time"
+)
+
+// GetNotifications
+//
+//	@Summary		Notifications
+//	@Description	Get page of notifications of current user, newest first
+//	@Tags			notifications
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			unread			query		bool						false	"Only unread notifications"
+//	@Param			page			query		int							false	"Page number, starting from 1"
+//	@Param			count			query		int							false	"Notifications per page"
+//	@Success		200				{object}	models.NotificationsPage	"Page of notifications"
+//	@Failure		500				{object}	ProblemResponseType			"Failed to get notifications"
+//	@Router			/notifications	[get]
+func (h *Handlers) GetNotifications(c *fiber.Ctx) error {
+	userId, err := currentUserId(c)
+	if err != nil {
+		return err
+	}
+
+	pagination := models.NewPagination(c.QueryInt("page", 1), c.QueryInt("count", models.DefaultPageSize))
+	notificationsPage, total, err := h.Notifications.GetNotifications(
+		c.Context(), userId, c.Query("unread") == "true", pagination)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(models.NotificationsPage{
+		Notifications: notificationsPage,
+		Total:         total,
+	})
+}
+
+// GetUnreadNotificationsCount
+//
+//	@Summary		Unread notifications count
+//	@Description	Get count of unread notifications of current user
+//	@Tags			notifications
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200								{object}	models.UnreadNotifications	"Count of unread notifications"
+//	@Failure		500								{object}	ProblemResponseType			"Failed to count notifications"
+//	@Router			/notifications/unread-count		[get]
+func (h *Handlers) GetUnreadNotificationsCount(c *fiber.Ctx) error {
+	userId, err := currentUserId(c)
+	if err != nil {
+		return err
+	}
+
+	unread, err := h.Notifications.CountUnreadNotifications(c.Context(), userId)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(models.UnreadNotifications{Unread: unread})
+}
+
+// MarkNotificationRead
+//
+//	@Summary		Mark notification read
+//	@Tags			notifications
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			notificationId							path		string				true	"Notification id"
+//	@Success		200										{object}	MessageResponseType	"Notification marked read"
+//	@Failure		400										{object}	ProblemResponseType	"Invalid id"
+//	@Failure		404										{object}	ProblemResponseType	"Notification not found"
+//	@Router			/notifications/{notificationId}/read	[post]
+func (h *Handlers) MarkNotificationRead(c *fiber.Ctx) error {
+	userId, err := currentUserId(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := idParam(c, "notificationId")
+	if err != nil {
+		return err
+	}
+	notificationId := id.String()
+	err = h.Notifications.MarkNotificationRead(c.Context(), userId, notificationId)
+	if err != nil {
+		return storeError(err, apierror.NotFound("notification_not_found",
+			"Notification with id %s not found", notificationId))
+	}
+	return MessageResponse(c, fiber.StatusOK, "Notification marked read")
+}
+
+// MarkAllNotificationsRead
+//
+//	@Summary		Mark all notifications read
+//	@Tags			notifications
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200							{object}	MessageResponseType	"Notifications marked read"
+//	@Failure		500							{object}	ProblemResponseType	"Failed to mark notifications read"
+//	@Router			/notifications/read-all		[post]
+func (h *Handlers) MarkAllNotificationsRead(c *fiber.Ctx) error {
+	userId, err := currentUserId(c)
+	if err != nil {
+		return err
+	}
+
+	err = h.Notifications.MarkAllNotificationsRead(c.Context(), userId)
+	if err != nil {
+		return err
+	}
+	return MessageResponse(c, fiber.StatusOK, "Notifications marked read")
+}
+
+// StreamNotifications
+//
+//	@Summary		Live notifications
+//	@Description	Server-sent events stream: "unread" event with count of unread notifications on connect,
+//	@Description	then "notification" event for every new notification. Browsers can pass token in query.
+//	@Description	Stream is closed once user is banned or their sessions are revoked
+//	@Tags			notifications
+//	@Produce		text/event-stream
+//	@Security		ApiKeyAuth
+//	@Param			token						query		string	false	"JWT, for clients which can not set Authorization header"
+//	@Success		200							{string}	string	"Event stream"
+//	@Router			/notifications/stream		[get]
+func (h *Handlers) StreamNotifications(c *fiber.Ctx) error {
+	userId, err := currentUserId(c)
+	if err != nil {
+		return err
+	}
+
+	unread, err := h.Notifications.CountUnreadNotifications(c.Context(), userId)
+	if err != nil {
+		return err
+	}
+
+	//	Claims outlive request, stream checks them against current state of user on every keep-alive
+	claims := c.Locals("user").(*jwt.Token).Claims.(jwt.MapClaims)
+	stream, closeStream := h.Live.Subscribe(userId)
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer closeStream()
+
+		keepAlive := time.NewTicker(configuration.EnvConfig.NotificationStreamKeepAlive)
+		defer keepAlive.Stop()
+
+		err := writeServerEvent(w, "unread", models.UnreadNotifications{Unread: unread})
+		for err == nil {
+			select {
+			case notification, open := <-stream:
+				if !open {
+					return
+				}
+				err = writeServerEvent(w, "notification", notification)
+			case <-keepAlive.C:
+				err = h.checkStreamAccess(userId, claims)
+				if err == nil {
+					_, err = w.WriteString(": keep-alive\n\n")
+				}
+				if err == nil {
+					err = w.Flush()
+				}
+			}
+		}
+	})
+	return nil
+}
+
+// checkStreamAccess checks that user of stream is still allowed to receive notifications
+func (h *Handlers) checkStreamAccess(userId string, claims jwt.MapClaims) error {
+	state, err := h.Users.GetUserAuthState(context.Background(), userId)
+	if err != nil {
+		return err
+	}
+	return middleware.CheckAuthState(claims, state)
+}
+
+// writeServerEvent writes and flushes one server-sent event, error means client is gone
+func writeServerEvent(w *bufio.Writer, event string, data interface{}) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, encoded)
+	if err != nil {
+		return err
+	}
+	return w.Flush()
+}
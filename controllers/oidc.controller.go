@@ -0,0 +1,299 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/exp/slog"
+	"math/rand"
+	"regexp"
+	"strings"
+	"tiktok-arena/apierror"
+	"tiktok-arena/configuration"
+	"tiktok-arena/jwtkeys"
+	"tiktok-arena/models"
+	"tiktok-arena/oidc"
+	"tiktok-arena/store"
+	"time"
+)
+
+const (
+	oidcFlowType      = "oidc_flow"
+	oidcFlowExpiresIn = 10 * time.Minute
+)
+
+var notAllowedUsernameCharacters = regexp.MustCompile(`[^a-zA-Z0-9_.-]`)
+
+// OIDCAuthorize
+//
+//	@Summary		Start OpenID Connect login
+//	@Description	Get provider authorization URL, flow token must be sent to callback together with code and state
+//	@Tags			auth
+//	@Produce		json
+//	@Success		200					{object}	models.OIDCAuthorization	"Authorization URL"
+//	@Failure		404					{object}	ProblemResponseType			"OpenID Connect is not configured"
+//	@Failure		502					{object}	ProblemResponseType			"Provider is unavailable"
+//	@Router			/auth/oidc/authorize	[get]
+func (h *Handlers) OIDCAuthorize(c *fiber.Ctx) error {
+	return startOIDCFlow(c, "")
+}
+
+// OIDCLink
+//
+//	@Summary		Start linking OpenID Connect identity
+//	@Description	Get provider authorization URL to attach provider identity to current account
+//	@Tags			auth
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200				{object}	models.OIDCAuthorization	"Authorization URL"
+//	@Failure		404				{object}	ProblemResponseType			"OpenID Connect is not configured"
+//	@Failure		502				{object}	ProblemResponseType			"Provider is unavailable"
+//	@Router			/auth/oidc/link	[post]
+func (h *Handlers) OIDCLink(c *fiber.Ctx) error {
+	userId, err := currentUserId(c)
+	if err != nil {
+		return err
+	}
+	return startOIDCFlow(c, userId)
+}
+
+// OIDCCallback
+//
+//	@Summary		Finish OpenID Connect login
+//	@Description	Exchange authorization code for user token. New account is created for unknown identity.
+//	@Description	For link flow identity is attached to account which started the flow
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload					body		models.OIDCCallbackInput	true	"Code, state and flow token"
+//	@Success		200						{object}	models.UserAuthDetails		"Login success"
+//	@Success		202						{object}	models.TwoFactorChallenge	"Two-factor code required"
+//	@Failure		400						{object}	ProblemResponseType			"Invalid payload"
+//	@Failure		401						{object}	ProblemResponseType			"Invalid flow token or code"
+//	@Failure		403						{object}	ProblemResponseType			"User is banned or must change password"
+//	@Failure		409						{object}	ProblemResponseType			"Identity is linked to another account"
+//	@Failure		404						{object}	ProblemResponseType			"OpenID Connect is not configured"
+//	@Router			/auth/oidc/callback		[post]
+func (h *Handlers) OIDCCallback(c *fiber.Ctx) error {
+	if oidc.Default == nil {
+		return oidcNotConfigured()
+	}
+
+	var payload *models.OIDCCallbackInput
+
+	err := c.BodyParser(&payload)
+	if err != nil {
+		return invalidBody(err)
+	}
+
+	err = models.ValidateStruct(payload)
+	if err != nil {
+		return validationFailed(err)
+	}
+
+	flow, err := parseOIDCFlow(payload.FlowToken)
+	if err != nil || flow["state"] != payload.State {
+		return apierror.Unauthorized("invalid_flow", "Invalid or expired login flow")
+	}
+	nonce, _ := flow["nonce"].(string)
+	codeVerifier, _ := flow["verifier"].(string)
+	linkUserId, _ := flow["link"].(string)
+
+	claims, err := oidc.Default.Exchange(c.Context(), payload.Code, codeVerifier, nonce)
+	if err != nil {
+		//	Provider errors may carry provider internals, they are only logged
+		slog.WarnCtx(c.Context(), "OpenID Connect code exchange failed", "error", err)
+		return apierror.Unauthorized("oidc_login_failed", "OpenID Connect login failed").Wrap(err)
+	}
+
+	identity, err := h.Users.GetUserIdentity(c.Context(), claims.Issuer, claims.Subject)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return err
+	}
+	identityExists := err == nil
+
+	if linkUserId != "" {
+		return h.linkOIDCIdentity(c, linkUserId, claims, identityExists, &identity)
+	}
+
+	var user models.User
+	if identityExists {
+		user, err = h.Users.GetUserById(c.Context(), identity.UserID.String())
+		if err != nil {
+			return err
+		}
+	} else {
+		user, err = h.createOIDCUser(c.Context(), claims)
+		if err != nil {
+			return err
+		}
+	}
+
+	if user.Banned {
+		return apierror.Forbidden("user_banned", "User is banned")
+	}
+	if user.MustResetPassword {
+		return apierror.Forbidden("password_reset_required", "Password reset required, change password before logging in")
+	}
+	if user.TOTPEnabled {
+		return twoFactorChallengeResponse(c, &user)
+	}
+	return userAuthDetailsResponse(c, fiber.StatusOK, &user)
+}
+
+func startOIDCFlow(c *fiber.Ctx, linkUserId string) error {
+	if oidc.Default == nil {
+		return oidcNotConfigured()
+	}
+
+	state, err := oidc.RandomString()
+	if err != nil {
+		return err
+	}
+	nonce, err := oidc.RandomString()
+	if err != nil {
+		return err
+	}
+	codeVerifier, err := oidc.RandomString()
+	if err != nil {
+		return err
+	}
+
+	authorizationURL, err := oidc.Default.AuthCodeURL(c.Context(), state, nonce, codeVerifier)
+	if err != nil {
+		return apierror.Upstream("oidc_unavailable", "OpenID Connect provider is unavailable").Wrap(err)
+	}
+
+	now := time.Now().UTC()
+	flowClaims := jwt.MapClaims{
+		"typ":      oidcFlowType,
+		"aud":      jwtkeys.Audience(oidcFlowType),
+		"state":    state,
+		"nonce":    nonce,
+		"verifier": codeVerifier,
+		"exp":      now.Add(oidcFlowExpiresIn).Unix(),
+		"iat":      now.Unix(),
+		"nbf":      now.Unix(),
+	}
+	if linkUserId != "" {
+		flowClaims["link"] = linkUserId
+	}
+	flowToken, err := jwtkeys.Keys.Sign(flowClaims)
+	if err != nil {
+		return fmt.Errorf("generating JWT token failed: %w", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.OIDCAuthorization{
+		AuthorizationURL: authorizationURL,
+		State:            state,
+		FlowToken:        flowToken,
+	})
+}
+
+func oidcNotConfigured() *apierror.Error {
+	return apierror.NotFound("oidc_not_configured", "OpenID Connect login is not configured")
+}
+
+func parseOIDCFlow(flowToken string) (jwt.MapClaims, error) {
+	token, err := jwtkeys.Keys.ParseFor(flowToken, jwtkeys.Audience(oidcFlowType))
+	if err != nil {
+		return nil, err
+	}
+	claims := token.Claims.(jwt.MapClaims)
+	if claims["typ"] != oidcFlowType {
+		return nil, fmt.Errorf("not an OpenID Connect flow token")
+	}
+	return claims, nil
+}
+
+func (h *Handlers) linkOIDCIdentity(
+	c *fiber.Ctx,
+	userId string,
+	claims *oidc.IDTokenClaims,
+	identityExists bool,
+	identity *models.UserIdentity,
+) error {
+	if identityExists {
+		if identity.UserID.String() == userId {
+			return MessageResponse(c, fiber.StatusOK, "Identity is already linked to your account")
+		}
+		return apierror.Conflict("identity_linked", "Identity is already linked to another account")
+	}
+	if h.Users.CheckIfUserHasIdentity(c.Context(), userId, claims.Issuer) {
+		return apierror.Conflict("issuer_linked", "Your account already has identity from %s", claims.Issuer)
+	}
+
+	parsedUserId, err := uuid.Parse(userId)
+	if err != nil {
+		return invalidTokenSubject()
+	}
+	err = h.Users.CreateUserIdentity(c.Context(), &models.UserIdentity{
+		UserID:  &parsedUserId,
+		Issuer:  claims.Issuer,
+		Subject: claims.Subject,
+	})
+	if err != nil {
+		return err
+	}
+	return MessageResponse(c, fiber.StatusOK,
+		fmt.Sprintf("Identity from %s linked to your account", claims.Issuer))
+}
+
+// createOIDCUser creates account for new identity. Account gets random password,
+// so it can be used only through provider until user changes it with password reset
+func (h *Handlers) createOIDCUser(ctx context.Context, claims *oidc.IDTokenClaims) (models.User, error) {
+	randomPassword, err := oidc.RandomString()
+	if err != nil {
+		return models.User{}, err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(randomPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	newUser := models.User{
+		Name:     h.oidcUsername(ctx, claims),
+		Password: string(hashedPassword),
+		Role:     models.RoleUser,
+	}
+	newIdentity := models.UserIdentity{
+		Issuer:  claims.Issuer,
+		Subject: claims.Subject,
+	}
+	err = h.Users.CreateNewUserWithIdentity(ctx, &newUser, &newIdentity)
+	return newUser, err
+}
+
+// oidcUsername derives free username allowed by username policy from provider claims
+func (h *Handlers) oidcUsername(ctx context.Context, claims *oidc.IDTokenClaims) string {
+	emailName, _, _ := strings.Cut(claims.Email, "@")
+	maxLength := configuration.EnvConfig.UsernameMaxLength
+
+	for _, candidate := range []string{claims.PreferredUsername, emailName} {
+		base := notAllowedUsernameCharacters.ReplaceAllString(candidate, "")
+		if base == "" {
+			continue
+		}
+		for attempt := 0; attempt < 5; attempt++ {
+			name := base
+			if attempt > 0 {
+				suffix := fmt.Sprintf("_%04d", rand.Intn(10000))
+				if maxLength > 0 && len(name)+len(suffix) > maxLength {
+					if maxLength <= len(suffix) {
+						break
+					}
+					name = name[:maxLength-len(suffix)]
+				}
+				name += suffix
+			}
+			if models.ValidateUsername(name) == nil && !h.Users.CheckIfUserExists(ctx, name) {
+				return name
+			}
+		}
+	}
+	return fmt.Sprintf("user_%s", strings.ReplaceAll(uuid.NewString(), "-", "")[:12])
+}
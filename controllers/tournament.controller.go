@@ -1,14 +1,19 @@
 package controllers
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
 	"math"
 	"math/rand"
-	"tiktok-arena/database"
+	"tiktok-arena/apierror"
+	"tiktok-arena/events"
+	"tiktok-arena/metrics"
 	"tiktok-arena/models"
+	"tiktok-arena/transfer"
 	"time"
 )
 
@@ -22,46 +27,147 @@ import (
 //	@Security		ApiKeyAuth
 //	@Param			payload	body		models.CreateTournament	true	"Data to create tournament"
 //	@Success		200		{object}	MessageResponseType		"Tournament created"
-//	@Failure		400		{object}	MessageResponseType		"Error during tournament creation"
+//	@Failure		400		{object}	ProblemResponseType		"Invalid tournament"
+//	@Failure		409		{object}	ProblemResponseType		"Tournament already exists"
 //	@Router			/tournament [post]
-func CreateTournament(c *fiber.Ctx) error {
+func (h *Handlers) CreateTournament(c *fiber.Ctx) error {
 	user := c.Locals("user").(*jwt.Token)
 	claims := user.Claims.(jwt.MapClaims)
 
 	userId, err := uuid.Parse(claims["sub"].(string))
 
 	if err != nil {
-		return MessageResponse(c, fiber.StatusBadRequest, err.Error())
+		return invalidTokenSubject()
 	}
 
 	var payload *models.CreateTournament
 
 	err = c.BodyParser(&payload)
 	if err != nil {
-		return MessageResponse(c, fiber.StatusBadRequest, err.Error())
+		return invalidBody(err)
 	}
 
-	err = models.ValidateStruct(payload)
+	err = models.ValidateCreateTournament(payload)
+	if err != nil {
+		return validationFailed(err)
+	}
+
+	if h.Tournaments.CheckIfTournamentExists(c.Context(), payload.Name) {
+		return apierror.Conflict("tournament_exists", "Tournament %s already exists", payload.Name)
+	}
+
+	_, err = h.createTournament(c, userId, payload)
+	if err != nil {
+		return err
+	}
+
+	return MessageResponse(c, fiber.StatusOK,
+		fmt.Sprintf("Successfully created tournament %s", payload.Name))
+}
+
+// ImportTournament
+//
+//	@Summary		Import tournament
+//	@Description	Create tournament from JSON or CSV file. JSON has format of tournament creation payload or export.
+//	@Description	CSV has header line and url column, other columns are ignored. Every invalid row is reported
+//	@Tags			tournament
+//	@Accept			json,text/csv
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			format	query		string				false	"File format: json (default) or csv"
+//	@Param			name	query		string				false	"Tournament name, required for csv"
+//	@Param			size	query		int					false	"Tournament size for csv, count of rows by default"
+//	@Success		200		{object}	MessageResponseType	"Tournament imported"
+//	@Failure		400		{object}	ProblemResponseType	"Invalid import file, invalid rows are listed in errors"
+//	@Failure		409		{object}	ProblemResponseType	"Tournament already exists"
+//	@Router			/tournament/import [post]
+func (h *Handlers) ImportTournament(c *fiber.Ctx) error {
+	userId, err := currentUserId(c)
+	if err != nil {
+		return err
+	}
+	ownerId, err := uuid.Parse(userId)
+	if err != nil {
+		return invalidTokenSubject()
+	}
+
+	format := c.Query("format", models.TransferFormatJSON)
+	if !models.CheckIfAllowedTransferFormat(format) {
+		return apierror.BadRequest("invalid_format", "%s is not allowed import format", format)
+	}
+
+	var payload *models.CreateTournament
+	body := bytes.NewReader(c.Body())
+	if format == models.TransferFormatCSV {
+		payload, err = transfer.ImportCSV(body, c.Query("name"), c.QueryInt("size"))
+	} else {
+		payload, err = transfer.ImportJSON(body)
+	}
+	var importError *transfer.ImportError
+	if errors.As(err, &importError) {
+		return apierror.Validation("import_failed", "Tournament import failed").WithErrors(importError.Errors)
+	}
+	if err != nil {
+		return apierror.BadRequest("invalid_file", "%v", err)
+	}
+
+	if h.Tournaments.CheckIfTournamentExists(c.Context(), payload.Name) {
+		return apierror.Conflict("tournament_exists", "Tournament %s already exists", payload.Name)
+	}
+
+	newTournament, err := h.createTournament(c, ownerId, payload)
+	if err != nil {
+		return err
+	}
+
+	return MessageResponse(c, fiber.StatusOK,
+		fmt.Sprintf("Successfully imported tournament %s with id %s", newTournament.Name, newTournament.ID))
+}
+
+// ExportTournament
+//
+//	@Summary		Export tournament
+//	@Description	Download tournament with its tiktoks and their statistics as JSON or CSV
+//	@Tags			tournament
+//	@Accept			json
+//	@Produce		json,text/csv
+//	@Param			tournamentId	path		string					true	"Tournament id"
+//	@Param			format			query		string					false	"File format: json (default) or csv"
+//	@Success		200				{object}	models.TournamentExport	"Tournament export"
+//	@Failure		400				{object}	ProblemResponseType		"Invalid tournament id or format"
+//	@Failure		404				{object}	ProblemResponseType		"Tournament not found"
+//	@Router			/tournament/{tournamentId}/export [get]
+func (h *Handlers) ExportTournament(c *fiber.Ctx) error {
+	tournamentId, err := tournamentIdParam(c)
 	if err != nil {
-		return MessageResponse(c, fiber.StatusBadRequest, err.Error())
+		return err
 	}
 
-	if payload.Size != len(payload.Tiktoks) {
-		return MessageResponse(c, fiber.StatusBadRequest,
-			fmt.Sprintf("Tournament size and count of tiktoks mismatch (%d != %d)",
-				payload.Size,
-				len(payload.Tiktoks)),
-		)
+	format := c.Query("format", models.TransferFormatJSON)
+	if !models.CheckIfAllowedTransferFormat(format) {
+		return apierror.BadRequest("invalid_format", "%s is not allowed export format", format)
 	}
 
-	if database.CheckIfTournamentExists(payload.Name) {
-		return MessageResponse(c, fiber.StatusBadRequest,
-			fmt.Sprintf("Tournament %s already exists", payload.Name))
+	tournament, err := h.Tournaments.GetTournamentById(c.Context(), tournamentId.String())
+	if err != nil {
+		return storeError(err, tournamentNotFound(tournamentId.String()))
+	}
+	tiktoks, err := h.visibleTournamentTiktoks(c, tournamentId.String())
+	if err != nil {
+		return err
 	}
 
+	export := models.NewTournamentExport(&tournament, tiktoks)
+	c.Attachment(fmt.Sprintf("%s.%s", tournamentId, format))
+	c.Set(fiber.HeaderContentType, transfer.ContentType(format))
+	return transfer.Export(c.Status(fiber.StatusOK).Response().BodyWriter(), format, &export)
+}
+
+// createTournament creates tournament of user with its tiktoks and publishes TournamentCreated event
+func (h *Handlers) createTournament(c *fiber.Ctx, userId uuid.UUID, payload *models.CreateTournament) (models.Tournament, error) {
 	newTournamentId, err := uuid.NewRandom()
 	if err != nil {
-		return MessageResponse(c, fiber.StatusBadRequest, err.Error())
+		return models.Tournament{}, err
 	}
 
 	newTournament := models.Tournament{
@@ -70,9 +176,9 @@ func CreateTournament(c *fiber.Ctx) error {
 		UserID: &userId,
 		Size:   payload.Size,
 	}
-	err = database.CreateNewTournament(&newTournament)
+	err = h.Tournaments.CreateNewTournament(c.Context(), &newTournament)
 	if err != nil {
-		return MessageResponse(c, fiber.StatusBadRequest, err.Error())
+		return newTournament, err
 	}
 
 	for _, value := range payload.Tiktoks {
@@ -82,14 +188,24 @@ func CreateTournament(c *fiber.Ctx) error {
 			Wins:         0,
 			AvgPoints:    0,
 		}
-		err = database.CreateNewTiktok(&tiktok)
+		err = h.Tiktoks.CreateNewTiktok(c.Context(), &tiktok)
 		if err != nil {
-			return MessageResponse(c, fiber.StatusBadRequest, err.Error())
+			return newTournament, err
 		}
 	}
 
-	return MessageResponse(c, fiber.StatusOK,
-		fmt.Sprintf("Successfully created tournament %s", payload.Name))
+	metrics.TournamentsCreated.Inc()
+	h.Events.Publish(c.Context(), events.Event{
+		Type:          events.TournamentCreated,
+		ActorID:       &userId,
+		ActorName:     currentUserName(c),
+		TargetType:    models.ReportTargetTournament,
+		TargetID:      &newTournamentId,
+		TargetName:    newTournament.Name,
+		TargetOwnerID: &userId,
+		Details:       map[string]interface{}{"Size": newTournament.Size},
+	})
+	return newTournament, nil
 }
 
 // GetTournamentDetails
@@ -101,18 +217,21 @@ func CreateTournament(c *fiber.Ctx) error {
 //	@Produce		json
 //	@Param			tournamentId	path		string				true	"Tournament id"
 //	@Success		200				{object}	models.Tournament	"Tournament"
-//	@Failure		400				{object}	MessageResponseType	"Tournament not found"
+//	@Failure		400				{object}	ProblemResponseType	"Invalid tournament id"
+//	@Failure		404				{object}	ProblemResponseType	"Tournament not found"
 //	@Router			/tournament/{tournamentId} [get]
-func GetTournamentDetails(c *fiber.Ctx) error {
-	tournamentId := c.Params("tournamentId")
-	if tournamentId == "" {
-		return MessageResponse(c, fiber.StatusBadRequest,
-			fmt.Sprintf("%s is not a valid tournament id", tournamentId))
+func (h *Handlers) GetTournamentDetails(c *fiber.Ctx) error {
+	tournamentId, err := tournamentIdParam(c)
+	if err != nil {
+		return err
 	}
-	tournament, err := database.GetTournamentById(tournamentId)
+	tournament, err := h.Tournaments.GetTournamentById(c.Context(), tournamentId.String())
 	if err != nil {
-		return MessageResponse(c, fiber.StatusBadRequest,
-			fmt.Sprintf("Could not get tournament with id %s", tournamentId))
+		return storeError(err, tournamentNotFound(tournamentId.String()))
+	}
+	viewerId, moderator := currentViewer(c)
+	if !canViewTournament(&tournament, viewerId, moderator) {
+		return tournamentNotFound(tournamentId.String())
 	}
 	return c.Status(fiber.StatusOK).JSON(tournament)
 }
@@ -126,18 +245,17 @@ func GetTournamentDetails(c *fiber.Ctx) error {
 //	@Produce		json
 //	@Param			tournamentId	path		string				true	"Tournament id"
 //	@Success		200				{array}		models.Tiktok		"Tournament tiktoks"
-//	@Failure		400				{object}	MessageResponseType	"Tournament not found"
+//	@Failure		400				{object}	ProblemResponseType	"Invalid tournament id"
+//	@Failure		404				{object}	ProblemResponseType	"Tournament not found"
 //	@Router			/tournament/{tournamentId}/tiktoks [get]
-func GetTournamentTiktoks(c *fiber.Ctx) error {
-	tournamentId := c.Params("tournamentId")
-	if tournamentId == "" {
-		return MessageResponse(c, fiber.StatusBadRequest,
-			fmt.Sprintf("%s is not a valid tournament id", tournamentId))
+func (h *Handlers) GetTournamentTiktoks(c *fiber.Ctx) error {
+	tournamentId, err := tournamentIdParam(c)
+	if err != nil {
+		return err
 	}
-	tiktoks, err := database.GetTournamentTiktoksById(tournamentId)
+	tiktoks, err := h.visibleTournamentTiktoks(c, tournamentId.String())
 	if err != nil {
-		return MessageResponse(c, fiber.StatusBadRequest,
-			fmt.Sprintf("Could not get tiktoks for tournament with id %s", tournamentId))
+		return err
 	}
 	return c.Status(fiber.StatusOK).JSON(tiktoks)
 }
@@ -152,34 +270,171 @@ func GetTournamentTiktoks(c *fiber.Ctx) error {
 //	@Param			tournamentId	path		string					true	"Tournament id"
 //	@Param			payload			query		models.ContestPayload	true	"Contest type"
 //	@Success		200				{object}	models.Bracket			"Contest bracket"
-//	@Failure		400				{object}	MessageResponseType		"Failed to return tournament contest"
+//	@Failure		400				{object}	ProblemResponseType		"Invalid tournament id or contest type"
+//	@Failure		404				{object}	ProblemResponseType		"Tournament not found"
+//	@Failure		409				{object}	ProblemResponseType		"Tournament has not enough tiktoks"
 //	@Router			/tournament/{tournamentId}/contest [get]
-func GetTournamentContest(c *fiber.Ctx) error {
-	tournamentId := c.Params("tournamentId")
-	if tournamentId == "" {
-		return MessageResponse(c, fiber.StatusBadRequest,
-			fmt.Sprintf("%s is not a valid tournament id", tournamentId))
+func (h *Handlers) GetTournamentContest(c *fiber.Ctx) error {
+	tournamentId, err := tournamentIdParam(c)
+	if err != nil {
+		return err
 	}
 
 	contestType := c.Query("type")
 	if !models.CheckIfAllowedTournamentType(contestType) {
-		return MessageResponse(c, fiber.StatusBadRequest,
-			fmt.Sprintf("%s is not allowed tournament format", contestType),
-		)
+		return apierror.BadRequest("invalid_contest_type", "%s is not allowed tournament format", contestType)
 	}
-	tiktoks, err := database.GetTournamentTiktoksById(tournamentId)
+	tiktoks, err := h.visibleTournamentTiktoks(c, tournamentId.String())
 	if err != nil {
-		return MessageResponse(c, fiber.StatusBadRequest,
-			fmt.Sprintf("Could not get tiktoks for tournament with id %s", tournamentId))
+		return err
+	}
+	if len(tiktoks) < 2 {
+		return apierror.Conflict("not_enough_tiktoks",
+			"Tournament with id %s has not enough tiktoks for contest", tournamentId)
 	}
 	shuffleTiktok(tiktoks)
+	metrics.ContestsStarted.WithLabelValues(contestType).Inc()
 	if contestType == models.SingleElimination {
 		return c.Status(fiber.StatusOK).JSON(SingleElimination(tiktoks))
 	}
 	if contestType == models.KingOfTheHill {
 		return c.Status(fiber.StatusOK).JSON(KingOfTheHill(tiktoks))
 	}
-	return MessageResponse(c, fiber.StatusBadRequest, "Unknown error")
+	return apierror.BadRequest("invalid_contest_type", "%s is not allowed tournament format", contestType)
+}
+
+// SubmitContestResult
+//
+//	@Summary		Submit contest result
+//	@Description	Record finished contest and update statistics of played tiktoks
+//	@Tags			tournament
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			tournamentId	path		string					true	"Tournament id"
+//	@Param			payload			body		models.ContestResult	true	"Contest winner and points of played tiktoks"
+//	@Success		201				{object}	models.Contest			"Contest recorded"
+//	@Failure		400				{object}	ProblemResponseType		"Invalid contest result"
+//	@Failure		404				{object}	ProblemResponseType		"Tournament not found"
+//	@Router			/tournament/{tournamentId}/contest [post]
+func (h *Handlers) SubmitContestResult(c *fiber.Ctx) error {
+	userId, err := currentUserId(c)
+	if err != nil {
+		return err
+	}
+	playerId, err := uuid.Parse(userId)
+	if err != nil {
+		return invalidTokenSubject()
+	}
+
+	tournamentId, err := tournamentIdParam(c)
+	if err != nil {
+		return err
+	}
+
+	var payload *models.ContestResult
+
+	err = c.BodyParser(&payload)
+	if err != nil {
+		return invalidBody(err)
+	}
+
+	err = models.ValidateStruct(payload)
+	if err != nil {
+		return validationFailed(err)
+	}
+
+	if !models.CheckIfAllowedTournamentType(payload.ContestType) {
+		return apierror.Validation("invalid_contest_type", "%s is not allowed tournament format", payload.ContestType)
+	}
+
+	tiktoks, err := h.visibleTournamentTiktoks(c, tournamentId.String())
+	if err != nil {
+		return err
+	}
+	tiktokIds := make(map[string]uuid.UUID, len(tiktoks))
+	for _, tiktok := range tiktoks {
+		tiktokIds[tiktok.URL] = *tiktok.ID
+	}
+
+	points := make(map[uuid.UUID]float64, len(payload.Tiktoks))
+	for _, result := range payload.Tiktoks {
+		tiktokId, ok := tiktokIds[result.URL]
+		if !ok {
+			return apierror.Validation("unknown_tiktok", "%s is not a tiktok of tournament %s", result.URL, tournamentId)
+		}
+		if _, duplicate := points[tiktokId]; duplicate {
+			return apierror.Validation("duplicate_result", "Tiktok %s has more than one result", result.URL)
+		}
+		points[tiktokId] = result.Points
+	}
+
+	winnerId, ok := tiktokIds[payload.WinnerURL]
+	if _, played := points[winnerId]; !ok || !played {
+		return apierror.Validation("unknown_winner", "Winner %s is not among played tiktoks", payload.WinnerURL)
+	}
+
+	newContest := models.Contest{
+		TournamentID:   &tournamentId,
+		UserID:         &playerId,
+		ContestType:    payload.ContestType,
+		WinnerTiktokID: &winnerId,
+	}
+	err = h.Tournaments.SaveContestResult(c.Context(), &newContest, points)
+	if err != nil {
+		return err
+	}
+	metrics.ContestsFinished.WithLabelValues(newContest.ContestType).Inc()
+
+	tournament, err := h.Tournaments.GetTournamentById(c.Context(), tournamentId.String())
+	if err == nil && !tournament.Hidden {
+		h.Events.Publish(c.Context(), events.Event{
+			Type:          events.ContestFinished,
+			ActorID:       &playerId,
+			ActorName:     currentUserName(c),
+			TargetType:    models.ReportTargetTournament,
+			TargetID:      &tournamentId,
+			TargetName:    tournament.Name,
+			TargetOwnerID: tournament.UserID,
+			Details: map[string]interface{}{
+				"ContestID":   newContest.ID,
+				"ContestType": newContest.ContestType,
+				"WinnerURL":   payload.WinnerURL,
+			},
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(newContest)
+}
+
+// visibleTournamentTiktoks returns tiktoks of tournament visible to current viewer,
+// hidden tiktoks are included only for tournament owner and moderators
+func (h *Handlers) visibleTournamentTiktoks(c *fiber.Ctx, tournamentId string) ([]models.Tiktok, error) {
+	tournament, err := h.Tournaments.GetTournamentById(c.Context(), tournamentId)
+	if err != nil {
+		return nil, storeError(err, tournamentNotFound(tournamentId))
+	}
+	viewerId, moderator := currentViewer(c)
+	//	Hidden tournament is reported as missing, so its existence is not revealed
+	if !canViewTournament(&tournament, viewerId, moderator) {
+		return nil, tournamentNotFound(tournamentId)
+	}
+	owner := tournament.UserID != nil && tournament.UserID.String() == viewerId
+	return h.Tiktoks.GetTournamentTiktoksById(c.Context(), tournamentId, owner || moderator)
+}
+
+// tournamentIdParam returns tournament id from path, it must be UUID
+func tournamentIdParam(c *fiber.Ctx) (uuid.UUID, error) {
+	tournamentId, err := uuid.Parse(c.Params("tournamentId"))
+	if err != nil {
+		return uuid.UUID{}, apierror.BadRequest("invalid_tournament_id",
+			"%s is not a valid tournament id", c.Params("tournamentId"))
+	}
+	return tournamentId, nil
+}
+
+func tournamentNotFound(tournamentId string) *apierror.Error {
+	return apierror.NotFound("tournament_not_found", "Could not get tournament with id %s", tournamentId)
 }
 
 func shuffleTiktok(t []models.Tiktok) {
@@ -317,12 +572,13 @@ func KingOfTheHill(t []models.Tiktok) models.Bracket {
 //	@Accept			json
 //	@Produce		json
 //	@Success		200			{array}		models.Tournament	"Contest bracket"
-//	@Failure		400			{object}	MessageResponseType	"Failed to return tournament contest"
+//	@Failure		500			{object}	ProblemResponseType	"Failed to get tournaments"
 //	@Router			/tournament																[get]
-func GetAllTournaments(c *fiber.Ctx) error {
-	tournaments, err := database.GetAllTournaments()
+func (h *Handlers) GetAllTournaments(c *fiber.Ctx) error {
+	viewerId, moderator := currentViewer(c)
+	tournaments, err := h.Tournaments.GetAllTournaments(c.Context(), viewerId, moderator)
 	if err != nil {
-		return MessageResponse(c, fiber.StatusBadRequest, "Failed to get tournaments")
+		return err
 	}
 	return c.Status(fiber.StatusOK).JSON(tournaments)
 }
@@ -0,0 +1,331 @@
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+	"strings"
+	"tiktok-arena/apierror"
+	"tiktok-arena/configuration"
+	"tiktok-arena/jwtkeys"
+	"tiktok-arena/models"
+	"tiktok-arena/throttle"
+	"tiktok-arena/totp"
+	"time"
+)
+
+const (
+	twoFactorChallengeType = "2fa_challenge"
+	recoveryCodesCount     = 10
+)
+
+// LoginUserTwoFactor
+//
+//	@Summary		Finish two-factor login
+//	@Description	Exchange challenge token from login and TOTP or recovery code for user token
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload				body		models.TwoFactorLoginInput	true	"Challenge token and code"
+//	@Success		200					{object}	models.UserAuthDetails		"Login success"
+//	@Failure		400					{object}	ProblemResponseType			"Invalid payload"
+//	@Failure		401					{object}	ProblemResponseType			"Invalid challenge token or code"
+//	@Failure		403					{object}	ProblemResponseType			"User is banned or must change password"
+//	@Failure		429					{object}	ProblemResponseType			"Too many failed login attempts"
+//	@Router			/auth/login/2fa		[post]
+func (h *Handlers) LoginUserTwoFactor(c *fiber.Ctx) error {
+	var payload *models.TwoFactorLoginInput
+
+	err := c.BodyParser(&payload)
+	if err != nil {
+		return invalidBody(err)
+	}
+
+	err = models.ValidateStruct(payload)
+	if err != nil {
+		return validationFailed(err)
+	}
+
+	userId, err := parseTwoFactorChallenge(payload.ChallengeToken)
+	if err != nil {
+		return invalidChallenge().Wrap(err)
+	}
+
+	user, err := h.Users.GetUserById(c.Context(), userId)
+	if err != nil {
+		return storeError(err, invalidChallenge())
+	}
+	if !user.TOTPEnabled {
+		return invalidChallenge()
+	}
+
+	userKey := strings.ToLower(user.Name)
+	ipKey := c.IP()
+
+	retryAfter, err := loginRetryAfter(userKey, ipKey)
+	if err != nil {
+		return err
+	}
+	if retryAfter > 0 {
+		return tooManyRequests(c, retryAfter, "Too many failed login attempts")
+	}
+
+	valid, err := h.checkTwoFactorCode(c.Context(), &user, payload.Code)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		err = loginFailed(userKey, ipKey)
+		if err != nil {
+			return err
+		}
+		return apierror.Unauthorized("invalid_two_factor_code", "Invalid two-factor code")
+	}
+
+	if user.Banned {
+		return apierror.Forbidden("user_banned", "User is banned")
+	}
+	if user.MustResetPassword {
+		return apierror.Forbidden("password_reset_required", "Password reset required, change password before logging in")
+	}
+
+	err = throttle.LoginByUser.Reset(userKey)
+	if err != nil {
+		return err
+	}
+
+	return userAuthDetailsResponse(c, fiber.StatusOK, &user)
+}
+
+// EnrollTwoFactor
+//
+//	@Summary		Enroll two-factor authentication
+//	@Description	Generate TOTP secret and recovery codes, two-factor is enabled after confirmation with first code
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200					{object}	models.TwoFactorEnrollment	"TOTP secret and recovery codes"
+//	@Failure		409					{object}	ProblemResponseType			"Two-factor is already enabled"
+//	@Router			/auth/2fa/enroll	[post]
+func (h *Handlers) EnrollTwoFactor(c *fiber.Ctx) error {
+	user, err := h.currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	if user.TOTPEnabled {
+		return apierror.Conflict("two_factor_enabled", "Two-factor authentication is already enabled")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return err
+	}
+
+	recoveryCodes, recoveryCodeHashes, err := generateRecoveryCodes()
+	if err != nil {
+		return err
+	}
+
+	err = h.Users.SetUserTOTPSecret(c.Context(), user.ID.String(), secret)
+	if err != nil {
+		return err
+	}
+
+	err = h.Users.ReplaceRecoveryCodes(c.Context(), user.ID, recoveryCodeHashes)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.TwoFactorEnrollment{
+		Secret:        secret,
+		URI:           totp.URI(configuration.EnvConfig.TOTPIssuer, user.Name, secret),
+		RecoveryCodes: recoveryCodes,
+	})
+}
+
+// ConfirmTwoFactor
+//
+//	@Summary		Confirm two-factor authentication
+//	@Description	Enable two-factor authentication with first code from authenticator app
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			payload				body		models.TwoFactorCodeInput	true	"TOTP code"
+//	@Success		200					{object}	MessageResponseType			"Two-factor enabled"
+//	@Failure		400					{object}	ProblemResponseType			"Invalid code"
+//	@Failure		409					{object}	ProblemResponseType			"Two-factor is already enabled or not enrolled"
+//	@Router			/auth/2fa/confirm	[post]
+func (h *Handlers) ConfirmTwoFactor(c *fiber.Ctx) error {
+	user, err := h.currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	var payload *models.TwoFactorCodeInput
+
+	err = c.BodyParser(&payload)
+	if err != nil {
+		return invalidBody(err)
+	}
+
+	err = models.ValidateStruct(payload)
+	if err != nil {
+		return validationFailed(err)
+	}
+
+	if user.TOTPEnabled {
+		return apierror.Conflict("two_factor_enabled", "Two-factor authentication is already enabled")
+	}
+	if user.TOTPSecret == "" {
+		return apierror.Conflict("two_factor_not_enrolled", "Two-factor authentication is not enrolled")
+	}
+
+	step, valid := totp.Validate(user.TOTPSecret, payload.Code, time.Now())
+	if !valid {
+		return apierror.Validation("invalid_two_factor_code", "Invalid two-factor code")
+	}
+
+	err = h.Users.EnableUserTOTP(c.Context(), user.ID.String(), step)
+	if err != nil {
+		return err
+	}
+
+	return MessageResponse(c, fiber.StatusOK, "Two-factor authentication enabled")
+}
+
+// DisableTwoFactor
+//
+//	@Summary		Disable two-factor authentication
+//	@Description	Disable two-factor authentication with TOTP or recovery code
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			payload				body		models.TwoFactorCodeInput	true	"TOTP or recovery code"
+//	@Success		200					{object}	MessageResponseType			"Two-factor disabled"
+//	@Failure		400					{object}	ProblemResponseType			"Invalid code"
+//	@Failure		409					{object}	ProblemResponseType			"Two-factor is not enabled"
+//	@Router			/auth/2fa/disable	[post]
+func (h *Handlers) DisableTwoFactor(c *fiber.Ctx) error {
+	user, err := h.currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	var payload *models.TwoFactorCodeInput
+
+	err = c.BodyParser(&payload)
+	if err != nil {
+		return invalidBody(err)
+	}
+
+	err = models.ValidateStruct(payload)
+	if err != nil {
+		return validationFailed(err)
+	}
+
+	if !user.TOTPEnabled {
+		return apierror.Conflict("two_factor_not_enabled", "Two-factor authentication is not enabled")
+	}
+
+	valid, err := h.checkTwoFactorCode(c.Context(), &user, payload.Code)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		return apierror.Validation("invalid_two_factor_code", "Invalid two-factor code")
+	}
+
+	err = h.Users.DisableUserTOTP(c.Context(), user.ID.String())
+	if err != nil {
+		return err
+	}
+
+	return MessageResponse(c, fiber.StatusOK, "Two-factor authentication disabled")
+}
+
+func twoFactorChallengeResponse(c *fiber.Ctx, user *models.User) error {
+	now := time.Now().UTC()
+	expiresIn := configuration.EnvConfig.TwoFactorChallengeExpiresIn
+
+	tokenString, err := jwtkeys.Keys.Sign(jwt.MapClaims{
+		"sub": user.ID,
+		"typ": twoFactorChallengeType,
+		"aud": jwtkeys.Audience(twoFactorChallengeType),
+		"exp": now.Add(expiresIn).Unix(),
+		"iat": now.Unix(),
+		"nbf": now.Unix(),
+	})
+	if err != nil {
+		return fmt.Errorf("generating JWT token failed: %w", err)
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(models.TwoFactorChallenge{
+		ChallengeToken: tokenString,
+		ExpiresIn:      int(expiresIn.Seconds()),
+	})
+}
+
+func invalidChallenge() *apierror.Error {
+	return apierror.Unauthorized("invalid_challenge", "Invalid or expired challenge token")
+}
+
+// parseTwoFactorChallenge validates challenge token and returns id of user who passed password check
+func parseTwoFactorChallenge(tokenString string) (string, error) {
+	token, err := jwtkeys.Keys.ParseFor(tokenString, jwtkeys.Audience(twoFactorChallengeType))
+	if err != nil {
+		return "", err
+	}
+
+	claims := token.Claims.(jwt.MapClaims)
+	if claims["typ"] != twoFactorChallengeType {
+		return "", fmt.Errorf("not a two-factor challenge token")
+	}
+	userId, ok := claims["sub"].(string)
+	if !ok {
+		return "", fmt.Errorf("challenge token has no subject")
+	}
+	return userId, nil
+}
+
+// checkTwoFactorCode accepts either unused TOTP code or unused recovery code
+func (h *Handlers) checkTwoFactorCode(ctx context.Context, user *models.User, code string) (bool, error) {
+	step, valid := totp.Validate(user.TOTPSecret, code, time.Now())
+	if valid {
+		return h.Users.UseUserTOTPStep(ctx, user.ID.String(), step)
+	}
+	return h.Users.UseRecoveryCode(ctx, user.ID.String(), hashRecoveryCode(code))
+}
+
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodesCount)
+	hashes := make([]string, 0, recoveryCodesCount)
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+	for i := 0; i < recoveryCodesCount; i++ {
+		random := make([]byte, 6)
+		_, err := rand.Read(random)
+		if err != nil {
+			return nil, nil, err
+		}
+		encoded := strings.ToLower(encoding.EncodeToString(random))
+		code := encoded[:5] + "-" + encoded[5:]
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode normalizes code, so it can be typed with any case and without dash
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"tiktok-arena/apierror"
+	"tiktok-arena/events"
+	"tiktok-arena/metrics"
+	"tiktok-arena/models"
+)
+
+// UpvoteTournament
+//
+//	@Summary		Upvote tournament
+//	@Description	Upvote tournament of another user, each user can upvote tournament once
+//	@Tags			tournament
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			tournamentId						path		string						true	"Tournament id"
+//	@Success		200									{object}	models.TournamentUpvotes	"Count of tournament upvotes"
+//	@Failure		400									{object}	ProblemResponseType			"Invalid tournament id"
+//	@Failure		403									{object}	ProblemResponseType			"Tournament belongs to current user"
+//	@Failure		404									{object}	ProblemResponseType			"Tournament not found"
+//	@Router			/tournament/{tournamentId}/upvote	[post]
+func (h *Handlers) UpvoteTournament(c *fiber.Ctx) error {
+	userId, err := currentUserId(c)
+	if err != nil {
+		return err
+	}
+
+	tournamentId, err := tournamentIdParam(c)
+	if err != nil {
+		return err
+	}
+
+	tournament, err := h.Tournaments.GetTournamentById(c.Context(), tournamentId.String())
+	if err != nil {
+		return storeError(err, tournamentNotFound(tournamentId.String()))
+	}
+	if tournament.Hidden {
+		return tournamentNotFound(tournamentId.String())
+	}
+	if tournament.UserID.String() == userId {
+		return apierror.Forbidden("own_tournament", "You can not upvote your own tournament")
+	}
+
+	parsedUserId, err := uuid.Parse(userId)
+	if err != nil {
+		return invalidTokenSubject()
+	}
+	created, err := h.Tournaments.AddTournamentUpvote(c.Context(), &models.TournamentUpvote{
+		TournamentID: &tournamentId,
+		UserID:       &parsedUserId,
+	})
+	if err != nil {
+		return err
+	}
+	if created {
+		metrics.VotesCast.Inc()
+	}
+
+	upvotes, err := h.Tournaments.CountTournamentUpvotes(c.Context(), tournamentId.String())
+	if err != nil {
+		return err
+	}
+
+	milestone := false
+	if created && events.IsUpvoteMilestone(upvotes) {
+		//	Milestone is notified once, even if upvotes drop below it and reach it again
+		milestone, err = h.Tournaments.RaiseUpvoteMilestone(c.Context(), tournamentId.String(), upvotes)
+		if err != nil {
+			return err
+		}
+	}
+	if milestone {
+		h.Events.Publish(c.Context(), events.Event{
+			Type:        events.TournamentUpvoteMilestone,
+			RecipientID: tournament.UserID,
+			ActorID:     &parsedUserId,
+			TargetType:  models.ReportTargetTournament,
+			TargetID:    &tournamentId,
+			TargetName:  tournament.Name,
+			Count:       upvotes,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.TournamentUpvotes{Upvotes: upvotes})
+}
+
+// RemoveTournamentUpvote
+//
+//	@Summary		Remove tournament upvote
+//	@Description	Remove upvote given to tournament by current user
+//	@Tags			tournament
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			tournamentId						path		string						true	"Tournament id"
+//	@Success		200									{object}	models.TournamentUpvotes	"Count of tournament upvotes"
+//	@Failure		400									{object}	ProblemResponseType			"Invalid tournament id"
+//	@Router			/tournament/{tournamentId}/upvote	[delete]
+func (h *Handlers) RemoveTournamentUpvote(c *fiber.Ctx) error {
+	userId, err := currentUserId(c)
+	if err != nil {
+		return err
+	}
+
+	tournamentId, err := tournamentIdParam(c)
+	if err != nil {
+		return err
+	}
+
+	_, err = h.Tournaments.RemoveTournamentUpvote(c.Context(), tournamentId.String(), userId)
+	if err != nil {
+		return err
+	}
+
+	return h.tournamentUpvotesResponse(c, tournamentId.String())
+}
+
+func (h *Handlers) tournamentUpvotesResponse(c *fiber.Ctx, tournamentId string) error {
+	upvotes, err := h.Tournaments.CountTournamentUpvotes(c.Context(), tournamentId)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(models.TournamentUpvotes{Upvotes: upvotes})
+}
@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"net/url"
+	"tiktok-arena/apierror"
+	"tiktok-arena/models"
+)
+
+// GetUserProfile
+//
+//	@Summary		User profile
+//	@Description	Get public profile of user with stats and page of public tournaments, newest first
+//	@Tags			user
+//	@Produce		json
+//	@Param			name			path		string				true	"Username"
+//	@Param			page			query		int					false	"Page of tournaments, starting from 1"
+//	@Param			count			query		int					false	"Tournaments per page"
+//	@Success		200				{object}	models.UserProfile	"User profile"
+//	@Failure		404				{object}	ProblemResponseType	"User not found"
+//	@Router			/users/{name}	[get]
+func (h *Handlers) GetUserProfile(c *fiber.Ctx) error {
+	name := c.Params("name")
+
+	user, err := h.Users.GetUserByName(c.Context(), name)
+	if err != nil {
+		return storeError(err, userNotFound(name))
+	}
+	if user.Banned {
+		return userNotFound(name)
+	}
+
+	stats, err := h.Users.GetUserStats(c.Context(), user.ID.String())
+	if err != nil {
+		return err
+	}
+
+	pagination := models.NewPagination(c.QueryInt("page", 1), c.QueryInt("count", models.DefaultPageSize))
+	tournaments, err := h.Tournaments.GetUserPublicTournaments(c.Context(), user.ID.String(), pagination)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.UserProfile{
+		ID:          user.ID,
+		Name:        user.Name,
+		DisplayName: user.DisplayName,
+		Bio:         user.Bio,
+		AvatarURL:   user.AvatarURL,
+		CreatedAt:   user.CreatedAt,
+		Stats:       stats,
+		Tournaments: tournaments,
+	})
+}
+
+// EditProfile
+//
+//	@Summary		Edit profile
+//	@Description	Replace display name, bio and avatar URL of current user
+//	@Tags			user
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			payload			body		models.EditProfile	true	"Profile fields"
+//	@Success		200				{object}	MessageResponseType	"Profile updated"
+//	@Failure		400				{object}	ProblemResponseType	"Invalid profile fields"
+//	@Router			/users/me		[put]
+func (h *Handlers) EditProfile(c *fiber.Ctx) error {
+	userId, err := currentUserId(c)
+	if err != nil {
+		return err
+	}
+
+	var payload *models.EditProfile
+
+	err = c.BodyParser(&payload)
+	if err != nil {
+		return invalidBody(err)
+	}
+
+	err = models.ValidateStruct(payload)
+	if err != nil {
+		return validationFailed(err)
+	}
+
+	if payload.AvatarURL != "" {
+		avatarURL, err := url.Parse(payload.AvatarURL)
+		if err != nil || (avatarURL.Scheme != "http" && avatarURL.Scheme != "https") {
+			return apierror.Validation("invalid_avatar_url", "Avatar URL must be http or https link")
+		}
+	}
+
+	err = h.Users.UpdateUserProfile(c.Context(), userId, payload)
+	if err != nil {
+		return userUpdateError(userId, err)
+	}
+	return MessageResponse(c, fiber.StatusOK, "Profile updated")
+}
+
+func userNotFound(name string) *apierror.Error {
+	return apierror.NotFound("user_not_found", "User %s not found", name)
+}
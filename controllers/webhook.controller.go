@@ -0,0 +1,234 @@
+package controllers
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"net/url"
+	"strings"
+	"tiktok-arena/apierror"
+	"tiktok-arena/models"
+	"tiktok-arena/webhooks"
+)
+
+const maxWebhooksPerUser = 10
+
+// GetWebhooks
+//
+//	@Summary		Webhooks
+//	@Description	Get webhooks of current user, secrets are not returned
+//	@Tags			webhooks
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200				{array}		models.Webhook		"Webhooks"
+//	@Failure		500				{object}	ProblemResponseType	"Failed to get webhooks"
+//	@Router			/webhooks		[get]
+func (h *Handlers) GetWebhooks(c *fiber.Ctx) error {
+	userId, err := currentUserId(c)
+	if err != nil {
+		return err
+	}
+
+	userWebhooks, err := h.Webhooks.GetUserWebhooks(c.Context(), userId)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(userWebhooks)
+}
+
+// CreateWebhook
+//
+//	@Summary		Create webhook
+//	@Description	Register URL which receives POST request for every selected event: tournament.created, contest.finished.
+//	@Description	Only events caused by current user or happening on tournaments of current user are delivered.
+//	@Description	Requests are signed with HMAC-SHA256 of "timestamp.body" in X-Arena-Signature header,
+//	@Description	secret is shown only in this response
+//	@Tags			webhooks
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			payload			body		models.CreateWebhook	true	"URL and events"
+//	@Success		201				{object}	models.CreatedWebhook	"Created webhook"
+//	@Failure		400				{object}	ProblemResponseType		"Invalid URL or events"
+//	@Failure		409				{object}	ProblemResponseType		"Webhook limit reached"
+//	@Router			/webhooks		[post]
+func (h *Handlers) CreateWebhook(c *fiber.Ctx) error {
+	userId, err := currentUserId(c)
+	if err != nil {
+		return err
+	}
+	ownerId, err := uuid.Parse(userId)
+	if err != nil {
+		return invalidTokenSubject()
+	}
+
+	var payload *models.CreateWebhook
+
+	err = c.BodyParser(&payload)
+	if err != nil {
+		return invalidBody(err)
+	}
+
+	err = models.ValidateStruct(payload)
+	if err != nil {
+		return validationFailed(err)
+	}
+
+	webhookURL, err := url.Parse(payload.URL)
+	if err != nil || (webhookURL.Scheme != "http" && webhookURL.Scheme != "https") {
+		return apierror.Validation("invalid_webhook_url", "Webhook URL must be http or https link")
+	}
+
+	for _, event := range payload.Events {
+		if !models.CheckIfAllowedWebhookEvent(event) {
+			return apierror.Validation("invalid_event", "%s is not allowed webhook event", event)
+		}
+	}
+
+	userWebhooks, err := h.Webhooks.GetUserWebhooks(c.Context(), userId)
+	if err != nil {
+		return err
+	}
+	if len(userWebhooks) >= maxWebhooksPerUser {
+		return apierror.Conflict("webhook_limit_reached", "User can have at most %d webhooks", maxWebhooksPerUser)
+	}
+
+	secret, err := webhooks.GenerateSecret()
+	if err != nil {
+		return err
+	}
+
+	newWebhook := models.Webhook{
+		UserID: &ownerId,
+		URL:    payload.URL,
+		Secret: secret,
+		Events: strings.Join(payload.Events, " "),
+	}
+	err = h.Webhooks.CreateWebhook(c.Context(), &newWebhook)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(models.CreatedWebhook{
+		Webhook: newWebhook,
+		Secret:  secret,
+	})
+}
+
+// DeleteWebhook
+//
+//	@Summary		Delete webhook
+//	@Description	Delete webhook of current user with its delivery log
+//	@Tags			webhooks
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			webhookId				path		string				true	"Webhook id"
+//	@Success		200						{object}	MessageResponseType	"Webhook deleted"
+//	@Failure		400						{object}	ProblemResponseType	"Invalid id"
+//	@Failure		404						{object}	ProblemResponseType	"Webhook not found"
+//	@Router			/webhooks/{webhookId}	[delete]
+func (h *Handlers) DeleteWebhook(c *fiber.Ctx) error {
+	userId, err := currentUserId(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := idParam(c, "webhookId")
+	if err != nil {
+		return err
+	}
+	webhookId := id.String()
+	err = h.Webhooks.DeleteWebhook(c.Context(), userId, webhookId)
+	if err != nil {
+		return storeError(err, webhookNotFound(webhookId))
+	}
+	return MessageResponse(c, fiber.StatusOK, fmt.Sprintf("Webhook %s deleted", webhookId))
+}
+
+// GetWebhookDeliveries
+//
+//	@Summary		Webhook delivery log
+//	@Description	Get page of deliveries of webhook, newest first
+//	@Tags			webhooks
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			webhookId							path		string							true	"Webhook id"
+//	@Param			page								query		int								false	"Page number, starting from 1"
+//	@Param			count								query		int								false	"Deliveries per page"
+//	@Success		200									{object}	models.WebhookDeliveriesPage	"Page of deliveries"
+//	@Failure		400									{object}	ProblemResponseType				"Invalid id"
+//	@Failure		404									{object}	ProblemResponseType				"Webhook not found"
+//	@Router			/webhooks/{webhookId}/deliveries	[get]
+func (h *Handlers) GetWebhookDeliveries(c *fiber.Ctx) error {
+	webhook, err := h.currentUserWebhook(c)
+	if err != nil {
+		return err
+	}
+
+	pagination := models.NewPagination(c.QueryInt("page", 1), c.QueryInt("count", models.DefaultPageSize))
+	deliveries, total, err := h.Webhooks.GetWebhookDeliveries(c.Context(), webhook.ID.String(), pagination)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(models.WebhookDeliveriesPage{
+		Deliveries: deliveries,
+		Total:      total,
+	})
+}
+
+// ReplayWebhookDelivery
+//
+//	@Summary		Replay webhook delivery
+//	@Description	Queue new delivery with payload of earlier delivery, e.g. after receiver was fixed
+//	@Tags			webhooks
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			webhookId											path		string					true	"Webhook id"
+//	@Param			deliveryId											path		string					true	"Delivery id"
+//	@Success		202													{object}	models.WebhookDelivery	"Queued delivery"
+//	@Failure		400													{object}	ProblemResponseType		"Invalid id"
+//	@Failure		404													{object}	ProblemResponseType		"Webhook or delivery not found"
+//	@Router			/webhooks/{webhookId}/deliveries/{deliveryId}/replay	[post]
+func (h *Handlers) ReplayWebhookDelivery(c *fiber.Ctx) error {
+	webhook, err := h.currentUserWebhook(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := idParam(c, "deliveryId")
+	if err != nil {
+		return err
+	}
+	deliveryId := id.String()
+	delivery, err := h.Webhooks.GetWebhookDelivery(c.Context(), webhook.ID.String(), deliveryId)
+	if err != nil {
+		return storeError(err, apierror.NotFound("delivery_not_found", "Delivery with id %s not found", deliveryId))
+	}
+
+	replay, err := h.Dispatcher.Replay(c.Context(), &delivery)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusAccepted).JSON(replay)
+}
+
+// currentUserWebhook loads webhook from path if it belongs to current user
+func (h *Handlers) currentUserWebhook(c *fiber.Ctx) (models.Webhook, error) {
+	id, err := idParam(c, "webhookId")
+	if err != nil {
+		return models.Webhook{}, err
+	}
+	userId, err := currentUserId(c)
+	if err != nil {
+		return models.Webhook{}, err
+	}
+	webhook, err := h.Webhooks.GetUserWebhook(c.Context(), userId, id.String())
+	if err != nil {
+		return models.Webhook{}, storeError(err, webhookNotFound(id.String()))
+	}
+	return webhook, nil
+}
+
+func webhookNotFound(webhookId string) *apierror.Error {
+	return apierror.NotFound("webhook_not_found", "Webhook with id %s not found", webhookId)
+}
@@ -0,0 +1,42 @@
+package database
+
+import (
+	"context"
+	"tiktok-arena/models"
+	"tiktok-arena/store"
+	"time"
+)
+
+func (s *Store) CreateAPIToken(ctx context.Context, newToken *models.APIToken) error {
+	record := s.db.WithContext(ctx).Table("api_tokens").Create(&newToken)
+	return record.Error
+}
+
+func (s *Store) GetUserAPITokens(ctx context.Context, userId string) ([]models.APIToken, error) {
+	var tokens []models.APIToken
+	record := s.db.WithContext(ctx).Table("api_tokens").Order("created_at DESC").Find(&tokens, "user_id = ?", userId)
+	return tokens, record.Error
+}
+
+func (s *Store) GetAPITokenByHash(ctx context.Context, tokenHash string) (models.APIToken, error) {
+	var token models.APIToken
+	record := s.db.WithContext(ctx).Table("api_tokens").First(&token, "token_hash = ?", tokenHash)
+	return token, notFound(record.Error)
+}
+
+// DeleteAPIToken deletes token of user, returns store.ErrNotFound if user has no such token
+func (s *Store) DeleteAPIToken(ctx context.Context, userId string, tokenId string) error {
+	record := s.db.WithContext(ctx).Table("api_tokens").Where("id = ? AND user_id = ?", tokenId, userId).Delete(&models.APIToken{})
+	if record.Error != nil {
+		return record.Error
+	}
+	if record.RowsAffected == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+func (s *Store) UpdateAPITokenLastUsed(ctx context.Context, tokenId string, lastUsedAt time.Time) error {
+	record := s.db.WithContext(ctx).Table("api_tokens").Where("id = ?", tokenId).Update("last_used_at", lastUsedAt)
+	return record.Error
+}
@@ -0,0 +1,45 @@
+package database
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"tiktok-arena/models"
+)
+
+// SaveContestResult records contest and updates statistics of played tiktoks in one transaction.
+// Every played tiktok gets its average points recalculated, winner also gets a win
+func (s *Store) SaveContestResult(ctx context.Context, newContest *models.Contest, points map[uuid.UUID]float64) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for tiktokId, tiktokPoints := range points {
+			wins := 0
+			if tiktokId == *newContest.WinnerTiktokID {
+				wins = 1
+			}
+			err := tx.Table("tiktoks").Where("id = ?", tiktokId).Updates(map[string]interface{}{
+				"avg_points":   gorm.Expr("(avg_points * times_played + ?) / (times_played + 1)", tiktokPoints),
+				"times_played": gorm.Expr("times_played + 1"),
+				"wins":         gorm.Expr("wins + ?", wins),
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Table("contests").Create(&newContest).Error
+	})
+}
+
+// RecomputeTiktokWins sets wins of every tiktok to count of recorded contests it won,
+// returns count of tiktoks whose wins were wrong
+func (s *Store) RecomputeTiktokWins(ctx context.Context) (int64, error) {
+	record := s.db.WithContext(ctx).Exec(`
+		UPDATE tiktoks SET wins = won.count
+		FROM (
+			SELECT tiktoks.id, COUNT(contests.id) AS count
+			FROM tiktoks
+			LEFT JOIN contests ON contests.winner_tiktok_id = tiktoks.id
+			GROUP BY tiktoks.id
+		) AS won
+		WHERE tiktoks.id = won.id AND tiktoks.wins IS DISTINCT FROM won.count`)
+	return record.RowsAffected, record.Error
+}
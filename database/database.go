@@ -1,92 +1,436 @@
 package database
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"github.com/google/uuid"
+	"golang.org/x/exp/slog"
 	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
-	"log"
+	"gorm.io/gorm/schema"
+	"reflect"
+	"strings"
 	"tiktok-arena/configuration"
 	"tiktok-arena/models"
+	"tiktok-arena/store"
+	"time"
 )
 
-var DB *gorm.DB
-
-func ConnectDB(config *configuration.EnvConfigModel) {
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
-		config.DBHost,
-		config.DBUserName,
-		config.DBUserPassword,
-		config.DBName,
-		config.DBPort,
-	)
-	var err error
-	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+// Store implements storage interfaces of package store with GORM and Postgres
+type Store struct {
+	db *gorm.DB
+}
+
+func NewStore(db *gorm.DB) *Store {
+	return &Store{db: db}
+}
+
+// Stores returns all stores backed by this database
+func (s *Store) Stores() store.Stores {
+	return store.Stores{
+		Users:         s,
+		Tournaments:   s,
+		Tiktoks:       s,
+		APITokens:     s,
+		Moderation:    s,
+		Notifications: s,
+		Webhooks:      s,
+	}
+}
+
+// notFound converts GORM not found error to store.ErrNotFound, other errors are returned as is
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return store.ErrNotFound
+	}
+	return err
+}
+
+// Open connects to database selected by DBDriver without checking schema, used by migration commands
+func Open(config *configuration.EnvConfigModel) (*gorm.DB, error) {
+	var dialector gorm.Dialector
+	gormConfig := &gorm.Config{Logger: newGormLogger()}
+	switch config.DBDriver {
+	case "", "postgres":
+		dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
+			config.DBHost,
+			config.DBUserName,
+			config.DBUserPassword,
+			config.DBName,
+			config.DBPort,
+		)
+		dialector = postgres.Open(dsn)
+	case "sqlite":
+		//	Foreign keys are enforced like in Postgres. Transactions take write lock when they begin
+		//	and wait for other writers, instead of failing when read lock can not be upgraded
+		dsn := fmt.Sprintf("file:%s?_foreign_keys=on&_busy_timeout=5000&_journal_mode=WAL&_txlock=immediate",
+			config.SQLitePath)
+		dialector = sqlite.Open(dsn)
+		//	Timestamps are stored as text and compared as strings, so they must share time zone
+		gormConfig.NowFunc = func() time.Time {
+			return time.Now().UTC()
+		}
+	default:
+		return nil, fmt.Errorf("unknown database driver %s", config.DBDriver)
+	}
+
+	db, err := gorm.Open(dialector, gormConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the database: %w", err)
+	}
+	err = db.Callback().Create().Before("gorm:create").Register("app:assign_defaults", assignDefaults)
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(config.DBMaxOpenConns)
+	sqlDB.SetMaxIdleConns(config.DBMaxIdleConns)
+	sqlDB.SetConnMaxLifetime(config.DBConnMaxLifetime)
+	return db, nil
+}
+
+// assignDefaults generates UUID primary keys and creation times of created records,
+// so they do not depend on database defaults that differ between drivers
+func assignDefaults(db *gorm.DB) {
+	if db.Statement.Schema == nil {
+		return
+	}
+	ctx := db.Statement.Context
+	var fields []*schema.Field
+	primaryKey := db.Statement.Schema.PrioritizedPrimaryField
+	if primaryKey != nil && primaryKey.FieldType == reflect.TypeOf(&uuid.UUID{}) {
+		fields = append(fields, primaryKey)
+	}
+	for _, field := range db.Statement.Schema.FieldsWithDefaultDBValue {
+		if field.AutoCreateTime > 0 && field.FieldType == reflect.TypeOf(time.Time{}) {
+			fields = append(fields, field)
+		}
+	}
+
+	assign := func(record reflect.Value) {
+		for _, field := range fields {
+			if _, zero := field.ValueOf(ctx, record); !zero {
+				continue
+			}
+			if field == primaryKey {
+				id := uuid.New()
+				db.AddError(field.Set(ctx, record, &id))
+			} else {
+				db.AddError(field.Set(ctx, record, db.NowFunc()))
+			}
+		}
+	}
+	switch db.Statement.ReflectValue.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < db.Statement.ReflectValue.Len(); i++ {
+			assign(reflect.Indirect(db.Statement.ReflectValue.Index(i)))
+		}
+	case reflect.Struct:
+		assign(db.Statement.ReflectValue)
+	}
+}
+
+// ConnectDB connects to database and makes sure its schema is current, see migrateOnStartup
+func ConnectDB(config *configuration.EnvConfigModel) (*gorm.DB, error) {
+	db, err := Open(config)
 	if err != nil {
-		log.Fatal("Failed to connect to the Database!\n", err.Error())
+		return nil, err
 	}
-	//	Extension for postgresql uuid support
-	DB.Exec("CREATE EXTENSION IF NOT EXISTS \"uuid-ossp\"")
+	err = migrateOnStartup(db, config.DBAutoMigrate)
+	if err != nil {
+		return nil, fmt.Errorf("migration failed: %w", err)
+	}
+	slog.Info("Connected to the database", "driver", db.Dialector.Name())
+	return db, nil
+}
 
-	err = DB.AutoMigrate(
-		&models.User{},
-		&models.Tournament{},
-		&models.Tiktok{},
-	)
+// migrateOnStartup refuses to start with schema newer than this build. Pending migrations are applied
+// if autoMigrate is set, otherwise they must be applied before start
+func migrateOnStartup(db *gorm.DB, autoMigrate bool) error {
+	migrator, err := NewMigrator(db)
 	if err != nil {
-		log.Fatal("Migration Failed:\n", err.Error())
+		return err
+	}
+	err = migrator.Check()
+	if !errors.Is(err, ErrSchemaOutdated) {
+		return err
+	}
+	if !autoMigrate {
+		return fmt.Errorf("%w, apply them or enable DB_AUTO_MIGRATE", err)
+	}
+
+	applied, err := migrator.Up()
+	for _, migration := range applied {
+		slog.Info("Applied migration", "version", migration.Version, "name", migration.Name)
+	}
+	return err
+}
+
+func (s *Store) GetUserByName(ctx context.Context, username string) (models.User, error) {
+	var user models.User
+	record := s.db.WithContext(ctx).Table("users").First(&user, "LOWER(name) = LOWER(?)", username)
+	return user, notFound(record.Error)
+}
+
+func (s *Store) GetUserById(ctx context.Context, userId string) (models.User, error) {
+	var user models.User
+	record := s.db.WithContext(ctx).Table("users").First(&user, "id = ?", userId)
+	return user, notFound(record.Error)
+}
+
+func (s *Store) GetUserAuthState(ctx context.Context, userId string) (models.UserAuthState, error) {
+	var state models.UserAuthState
+	record := s.db.WithContext(ctx).Table("users").
+		Select([]string{"role", "banned", "sessions_revoked_at"}).
+		Where("id = ?", userId).
+		Take(&state)
+	return state, notFound(record.Error)
+}
+
+// SearchUsers returns page of users whose name contains search string, ignoring letter case
+func (s *Store) SearchUsers(ctx context.Context, search string, pagination models.Pagination) ([]models.UserSummary, int64, error) {
+	var users []models.UserSummary
+	var total int64
+
+	query := s.db.WithContext(ctx).Table("users")
+	if search != "" {
+		query = query.Where("LOWER(name) LIKE ? ESCAPE '\\'", "%"+escapeLike(strings.ToLower(search))+"%")
+	}
+	//	New session allows reusing conditions for both count and page queries
+	query = query.Session(&gorm.Session{})
+
+	record := query.Count(&total)
+	if record.Error != nil {
+		return nil, 0, record.Error
 	}
-	log.Println("Successfully connected to the database")
+
+	record = query.
+		Select([]string{"id", "name", "role", "banned", "must_reset_password", "totp_enabled"}).
+		Order("name").
+		Offset(pagination.Offset()).
+		Limit(pagination.Count).
+		Find(&users)
+	return users, total, record.Error
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_").Replace(s)
+}
+
+func (s *Store) SetUserBanned(ctx context.Context, userId string, banned bool) error {
+	return s.updateUser(ctx, userId, map[string]interface{}{"banned": banned})
 }
 
-func GetUserByName(username string) (models.User, error) {
+func (s *Store) SetUserRole(ctx context.Context, userId string, role string) error {
+	return s.updateUser(ctx, userId, map[string]interface{}{"role": role})
+}
+
+func (s *Store) SetUserMustResetPassword(ctx context.Context, userId string, mustReset bool) error {
+	columns := map[string]interface{}{"must_reset_password": mustReset}
+	if mustReset {
+		columns["sessions_revoked_at"] = time.Now().UTC()
+	}
+	return s.updateUser(ctx, userId, columns)
+}
+
+func (s *Store) UpdateUserPassword(ctx context.Context, userId string, hashedPassword string) error {
+	return s.updateUser(ctx, userId, map[string]interface{}{
+		"password":            hashedPassword,
+		"must_reset_password": false,
+		"sessions_revoked_at": time.Now().UTC(),
+	})
+}
+
+// GetUserByEmail finds user by verified email, emails are stored lowercase
+func (s *Store) GetUserByEmail(ctx context.Context, email string) (models.User, error) {
 	var user models.User
-	record := DB.Table("users").First(&user, "name = ?", username)
-	return user, record.Error
+	record := s.db.WithContext(ctx).Table("users").First(&user, "email = ? AND email_verified", strings.ToLower(email))
+	return user, notFound(record.Error)
+}
+
+func (s *Store) CheckIfEmailTaken(ctx context.Context, email string, exceptUserId string) bool {
+	var count int64
+	s.db.WithContext(ctx).Table("users").
+		Where("email = ? AND id <> ?", strings.ToLower(email), exceptUserId).
+		Count(&count)
+	return count > 0
+}
+
+// SetUserEmail sets new unverified email of user
+func (s *Store) SetUserEmail(ctx context.Context, userId string, email string) error {
+	return s.updateUser(ctx, userId, map[string]interface{}{"email": strings.ToLower(email), "email_verified": false})
+}
+
+// VerifyUserEmail marks email of user verified if it was not changed since verification link was sent
+func (s *Store) VerifyUserEmail(ctx context.Context, userId string, email string) error {
+	record := s.db.WithContext(ctx).Table("users").
+		Where("id = ? AND email = ?", userId, strings.ToLower(email)).
+		Update("email_verified", true)
+	if record.Error != nil {
+		return record.Error
+	}
+	if record.RowsAffected == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+// updateUser updates given columns of user, returns store.ErrNotFound if there is no such user
+func (s *Store) updateUser(ctx context.Context, userId string, columns map[string]interface{}) error {
+	record := s.db.WithContext(ctx).Table("users").Where("id = ?", userId).Updates(columns)
+	if record.Error != nil {
+		return record.Error
+	}
+	if record.RowsAffected == 0 {
+		return store.ErrNotFound
+	}
+	return nil
 }
 
-func CheckIfUserExists(username string) bool {
+func (s *Store) CheckIfUserExists(ctx context.Context, username string) bool {
 	var user models.User
-	DB.Table("users").Select("id").First(&user, "name = ?", username)
+	s.db.WithContext(ctx).Table("users").Select("id").First(&user, "LOWER(name) = LOWER(?)", username)
 	return user.ID != nil
 }
 
-func CreateNewUser(newUser *models.User) error {
-	record := DB.Table("users").Create(&newUser)
+func (s *Store) CreateNewUser(ctx context.Context, newUser *models.User) error {
+	record := s.db.WithContext(ctx).Table("users").Create(&newUser)
 	return record.Error
 }
 
-func GetTournamentById(tournamentId string) (models.Tournament, error) {
+func (s *Store) GetTournamentById(ctx context.Context, tournamentId string) (models.Tournament, error) {
 	var tournament models.Tournament
-	record := DB.Table("tournaments").First(&tournament, "id = ?", tournamentId)
-	return tournament, record.Error
+	record := s.db.WithContext(ctx).Table("tournaments").First(&tournament, "id = ?", tournamentId)
+	return tournament, notFound(record.Error)
 }
 
-func CheckIfTournamentExists(tournamentName string) bool {
+func (s *Store) CheckIfTournamentExists(ctx context.Context, tournamentName string) bool {
 	var tournament models.Tournament
-	DB.Table("tournaments").Select("id").First(&tournament, "name = ?", tournamentName)
+	s.db.WithContext(ctx).Table("tournaments").Select("id").First(&tournament, "name = ?", tournamentName)
 	return tournament.ID != nil
 }
 
-func CreateNewTournament(newTournament *models.Tournament) error {
-	record := DB.Table("tournaments").Create(&newTournament)
+func (s *Store) CreateNewTournament(ctx context.Context, newTournament *models.Tournament) error {
+	record := s.db.WithContext(ctx).Table("tournaments").Create(&newTournament)
 	return record.Error
 }
 
-func CreateNewTiktok(newTiktok *models.Tiktok) error {
-	record := DB.Table("tiktoks").Create(&newTiktok)
+func (s *Store) CreateNewTiktok(ctx context.Context, newTiktok *models.Tiktok) error {
+	record := s.db.WithContext(ctx).Table("tiktoks").Create(&newTiktok)
 	return record.Error
 }
 
-func GetTournamentTiktoksById(tournamentId string) ([]models.Tiktok, error) {
+// GetTournamentTiktoksById returns tournament tiktoks, tiktoks hidden by moderators are included only if includeHidden is set
+func (s *Store) GetTournamentTiktoksById(ctx context.Context, tournamentId string, includeHidden bool) ([]models.Tiktok, error) {
 	var tiktoks []models.Tiktok
-	record := DB.Table("tiktoks").
-		Select([]string{"ID", "TournamentID", "URL", "Wins", "AvgPoints"}).
-		Find(&tiktoks, "tournament_id = ?", tournamentId)
+	query := s.db.WithContext(ctx).Table("tiktoks").
+		Select([]string{"ID", "TournamentID", "URL", "Wins", "AvgPoints", "Hidden"}).
+		Where("tournament_id = ?", tournamentId)
+	if !includeHidden {
+		query = query.Where("hidden = ?", false)
+	}
+	record := query.Find(&tiktoks)
 	return tiktoks, record.Error
 }
 
-func GetAllTournaments() ([]models.Tournament, error) {
+func (s *Store) DeleteTournament(ctx context.Context, tournamentId string) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Table("contests").Where("tournament_id = ?", tournamentId).Delete(&models.Contest{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Table("tournament_upvotes").Where("tournament_id = ?", tournamentId).
+			Delete(&models.TournamentUpvote{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Table("tiktoks").Where("tournament_id = ?", tournamentId).Delete(&models.Tiktok{}).Error
+		if err != nil {
+			return err
+		}
+		record := tx.Table("tournaments").Where("id = ?", tournamentId).Delete(&models.Tournament{})
+		if record.Error != nil {
+			return record.Error
+		}
+		if record.RowsAffected == 0 {
+			return store.ErrNotFound
+		}
+		return nil
+	})
+}
+
+// GetAllTournaments returns tournaments visible to viewer: hidden tournaments are returned only to their owner
+// or to moderators. Empty viewerId means anonymous viewer
+func (s *Store) GetAllTournaments(ctx context.Context, viewerId string, moderator bool) ([]models.Tournament, error) {
 	var tournaments []models.Tournament
-	record := DB.Table("tournaments").Select("*").Limit(100).Find(&tournaments)
+	query := s.db.WithContext(ctx).Table("tournaments").Select("*")
+	if !moderator {
+		if viewerId == "" {
+			query = query.Where("hidden = ?", false)
+		} else {
+			query = query.Where("hidden = ? OR user_id = ?", false, viewerId)
+		}
+	}
+	record := query.Limit(100).Find(&tournaments)
 	return tournaments, record.Error
 }
+
+func (s *Store) SetUserTOTPSecret(ctx context.Context, userId string, secret string) error {
+	record := s.db.WithContext(ctx).Table("users").Where("id = ?", userId).
+		Updates(map[string]interface{}{"totp_secret": secret, "totp_enabled": false})
+	return record.Error
+}
+
+func (s *Store) EnableUserTOTP(ctx context.Context, userId string, step int64) error {
+	record := s.db.WithContext(ctx).Table("users").Where("id = ?", userId).
+		Updates(map[string]interface{}{"totp_enabled": true, "totp_last_step": step})
+	return record.Error
+}
+
+func (s *Store) DisableUserTOTP(ctx context.Context, userId string) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Table("users").Where("id = ?", userId).
+			Updates(map[string]interface{}{"totp_secret": "", "totp_enabled": false, "totp_last_step": 0}).
+			Error
+		if err != nil {
+			return err
+		}
+		return tx.Table("recovery_codes").Where("user_id = ?", userId).Delete(&models.RecoveryCode{}).Error
+	})
+}
+
+// UseUserTOTPStep marks TOTP time step as used, returns false if this or later step was used before
+func (s *Store) UseUserTOTPStep(ctx context.Context, userId string, step int64) (bool, error) {
+	record := s.db.WithContext(ctx).Table("users").
+		Where("id = ? AND totp_last_step < ?", userId, step).
+		Update("totp_last_step", step)
+	return record.RowsAffected == 1, record.Error
+}
+
+func (s *Store) ReplaceRecoveryCodes(ctx context.Context, userId *uuid.UUID, codeHashes []string) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Table("recovery_codes").Where("user_id = ?", userId).Delete(&models.RecoveryCode{}).Error
+		if err != nil {
+			return err
+		}
+		codes := make([]models.RecoveryCode, 0, len(codeHashes))
+		for _, hash := range codeHashes {
+			codes = append(codes, models.RecoveryCode{UserID: userId, CodeHash: hash})
+		}
+		return tx.Table("recovery_codes").Create(&codes).Error
+	})
+}
+
+// UseRecoveryCode marks unused recovery code as used, returns false if there is no such code
+func (s *Store) UseRecoveryCode(ctx context.Context, userId string, codeHash string) (bool, error) {
+	record := s.db.WithContext(ctx).Table("recovery_codes").
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userId, codeHash).
+		Update("used_at", time.Now().UTC())
+	return record.RowsAffected == 1, record.Error
+}
@@ -0,0 +1,114 @@
+package database
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"gorm.io/gorm/clause"
+	"tiktok-arena/models"
+	"time"
+)
+
+// FollowUser creates follow, returns false if user already follows followee
+func (s *Store) FollowUser(ctx context.Context, follow *models.Follow) (bool, error) {
+	record := s.db.WithContext(ctx).Table("follows").
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(follow)
+	return record.RowsAffected > 0, record.Error
+}
+
+// UnfollowUser removes follow, returns false if user did not follow followee
+func (s *Store) UnfollowUser(ctx context.Context, followerId string, followeeId string) (bool, error) {
+	record := s.db.WithContext(ctx).Table("follows").
+		Where("follower_id = ? AND followee_id = ?", followerId, followeeId).
+		Delete(&models.Follow{})
+	return record.RowsAffected > 0, record.Error
+}
+
+type feedRow struct {
+	Type             string
+	ID               uuid.UUID
+	CreatedAt        time.Time
+	UserID           uuid.UUID
+	UserName         string
+	UserDisplayName  string
+	TournamentID     uuid.UUID
+	TournamentName   string
+	TournamentSize   int
+	ContestType      *string
+	ContestWinnerURL *string
+}
+
+// Feed merges tournaments created by followed users with contests they finished on public tournaments.
+// Items are ordered by (created_at, id), so cursor of last item splits feed without duplicates or gaps
+const feedQuery = `
+SELECT feed.type, feed.id, feed.created_at, feed.user_id,
+	users.name AS user_name, users.display_name AS user_display_name,
+	feed.tournament_id, tournaments.name AS tournament_name, tournaments.size AS tournament_size,
+	feed.contest_type, feed.contest_winner_url
+FROM (
+	SELECT 'tournament_created' AS type, tournaments.id, tournaments.created_at, tournaments.user_id,
+		tournaments.id AS tournament_id, CAST(NULL AS TEXT) AS contest_type, CAST(NULL AS TEXT) AS contest_winner_url
+	FROM tournaments
+	WHERE tournaments.user_id IN (SELECT followee_id FROM follows WHERE follower_id = @follower)
+	UNION ALL
+	SELECT 'contest_finished' AS type, contests.id, contests.created_at, contests.user_id,
+		contests.tournament_id, contests.contest_type, tiktoks.url AS contest_winner_url
+	FROM contests
+	LEFT JOIN tiktoks ON tiktoks.id = contests.winner_tiktok_id
+	WHERE contests.user_id IN (SELECT followee_id FROM follows WHERE follower_id = @follower)
+) AS feed
+JOIN users ON users.id = feed.user_id
+JOIN tournaments ON tournaments.id = feed.tournament_id
+WHERE tournaments.hidden = FALSE AND users.banned = FALSE
+	AND (@after = FALSE OR feed.created_at < @created_at OR (feed.created_at = @created_at AND feed.id < @id))
+ORDER BY feed.created_at DESC, feed.id DESC
+LIMIT @limit`
+
+// GetFeed returns up to limit feed items of follower older than cursor, newest first. Nil cursor starts from newest item
+func (s *Store) GetFeed(ctx context.Context, followerId string, cursor *models.FeedCursor, limit int) ([]models.FeedItem, error) {
+	params := map[string]interface{}{
+		"follower":   followerId,
+		"after":      cursor != nil,
+		"created_at": time.Time{},
+		"id":         uuid.Nil,
+		"limit":      limit,
+	}
+	if cursor != nil {
+		params["created_at"] = cursor.CreatedAt
+		params["id"] = cursor.ID
+	}
+
+	var rows []feedRow
+	record := s.db.WithContext(ctx).Raw(feedQuery, params).Scan(&rows)
+	if record.Error != nil {
+		return nil, record.Error
+	}
+
+	items := make([]models.FeedItem, 0, len(rows))
+	for i := range rows {
+		row := &rows[i]
+		item := models.FeedItem{
+			Type:      row.Type,
+			ID:        &row.ID,
+			CreatedAt: row.CreatedAt,
+			User: models.FeedUser{
+				ID:          &row.UserID,
+				Name:        row.UserName,
+				DisplayName: row.UserDisplayName,
+			},
+			Tournament: models.FeedTournament{
+				ID:   &row.TournamentID,
+				Name: row.TournamentName,
+				Size: row.TournamentSize,
+			},
+		}
+		if row.ContestType != nil {
+			item.Contest = &models.FeedContest{ContestType: *row.ContestType}
+			if row.ContestWinnerURL != nil {
+				item.Contest.WinnerURL = *row.ContestWinnerURL
+			}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
@@ -0,0 +1,36 @@
+package database
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"tiktok-arena/models"
+)
+
+func (s *Store) GetUserIdentity(ctx context.Context, issuer string, subject string) (models.UserIdentity, error) {
+	var identity models.UserIdentity
+	record := s.db.WithContext(ctx).Table("user_identities").First(&identity, "issuer = ? AND subject = ?", issuer, subject)
+	return identity, notFound(record.Error)
+}
+
+func (s *Store) CheckIfUserHasIdentity(ctx context.Context, userId string, issuer string) bool {
+	var identity models.UserIdentity
+	s.db.WithContext(ctx).Table("user_identities").Select("id").First(&identity, "user_id = ? AND issuer = ?", userId, issuer)
+	return identity.ID != nil
+}
+
+func (s *Store) CreateUserIdentity(ctx context.Context, newIdentity *models.UserIdentity) error {
+	record := s.db.WithContext(ctx).Table("user_identities").Create(&newIdentity)
+	return record.Error
+}
+
+// CreateNewUserWithIdentity creates user and links external identity to it in one transaction
+func (s *Store) CreateNewUserWithIdentity(ctx context.Context, newUser *models.User, newIdentity *models.UserIdentity) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Table("users").Create(&newUser).Error
+		if err != nil {
+			return err
+		}
+		newIdentity.UserID = newUser.ID
+		return tx.Table("user_identities").Create(&newIdentity).Error
+	})
+}
@@ -0,0 +1,75 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"golang.org/x/exp/slog"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"time"
+)
+
+// slowQueryThreshold is duration after which query is logged as slow, same as default GORM logger
+const slowQueryThreshold = 200 * time.Millisecond
+
+// gormLogger writes GORM logs with slog, so queries run with context of request are logged with its
+// request ID. Failed queries are logged as errors, slow ones as warnings and every query at debug level
+type gormLogger struct {
+	level logger.LogLevel
+}
+
+func newGormLogger() logger.Interface {
+	return gormLogger{level: logger.Info}
+}
+
+func (l gormLogger) LogMode(level logger.LogLevel) logger.Interface {
+	l.level = level
+	return l
+}
+
+func (l gormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Info {
+		slog.InfoCtx(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l gormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Warn {
+		slog.WarnCtx(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l gormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Error {
+		slog.ErrorCtx(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	elapsed := time.Since(begin)
+	switch {
+	case l.level <= logger.Silent:
+		return
+	//	Missing records are expected, stores turn them into store.ErrNotFound
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.level >= logger.Error:
+		sql, rows := fc()
+		slog.ErrorCtx(ctx, "Query failed", "error", err, "sql", sql, "rows", rows,
+			"duration_ms", durationMilliseconds(elapsed))
+	case elapsed > slowQueryThreshold && l.level >= logger.Warn:
+		sql, rows := fc()
+		slog.WarnCtx(ctx, "Slow query", "sql", sql, "rows", rows, "duration_ms", durationMilliseconds(elapsed))
+	case l.level >= logger.Info && slog.Default().Enabled(ctx, slog.LevelDebug):
+		sql, rows := fc()
+		slog.DebugCtx(ctx, "Query", "sql", sql, "rows", rows, "duration_ms", durationMilliseconds(elapsed))
+	}
+}
+
+// ParamsFilter drops query parameters from logged SQL, they hold password hashes, tokens and emails
+func (l gormLogger) ParamsFilter(_ context.Context, sql string, _ ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
+
+func durationMilliseconds(duration time.Duration) float64 {
+	return float64(duration.Microseconds()) / 1000
+}
@@ -0,0 +1,373 @@
+package database
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/*/*.sql
+var migrationFiles embed.FS
+
+// migrationFileName matches files like 0002_add_tournament_tags.up.sql
+var migrationFileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// migrationsLockId is key of advisory lock held while migration is applied,
+// so instances started at the same time do not apply the same migration twice
+const migrationsLockId = 7291640251
+
+// ErrSchemaTooNew is returned when database was migrated by newer build, which may have changed schema
+// in ways this build does not understand
+var ErrSchemaTooNew = errors.New("database schema is newer than this build")
+
+// ErrSchemaOutdated is returned when database has pending migrations
+var ErrSchemaOutdated = errors.New("database schema has pending migrations")
+
+// Migration is one schema change with SQL to apply and to revert it
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// AppliedMigration is row of schema_migrations table
+type AppliedMigration struct {
+	Version   int
+	Name      string
+	AppliedAt time.Time
+}
+
+// MigrationStatus describes known migration and whether it is applied to database
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// Migrations returns migrations of SQL dialect embedded into binary ordered by version.
+// Every dialect has its own directory and every migration must have both up and down file
+func Migrations(dialect string) ([]Migration, error) {
+	dir := path.Join("migrations", dialect)
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("unexpected file %s in %s", entry.Name(), dir)
+		}
+		version, _ := strconv.Atoi(match[1])
+		content, err := migrationFiles.ReadFile(path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has files with different names: %s and %s",
+				version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s must have both up and down file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// Migrator applies and reverts embedded migrations, applied versions are recorded in schema_migrations table
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+func NewMigrator(db *gorm.DB) (*Migrator, error) {
+	migrations, err := Migrations(db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Latest returns version of newest migration known to this build
+func (m *Migrator) Latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Version returns version of database schema, zero for empty database
+func (m *Migrator) Version() (int, error) {
+	err := m.prepare()
+	if err != nil {
+		return 0, err
+	}
+	return currentVersion(m.db)
+}
+
+// Check returns ErrSchemaTooNew if database was migrated by newer build
+// and ErrSchemaOutdated if database has pending migrations
+func (m *Migrator) Check() error {
+	version, err := m.Version()
+	if err != nil {
+		return err
+	}
+	return m.compare(version)
+}
+
+// CheckApplied is Check which only reads database, it neither creates schema_migrations table
+// nor takes migrations lock, so it can be used by frequent probes while migrations are applied.
+// Database without schema_migrations table has pending migrations
+func (m *Migrator) CheckApplied() error {
+	if !m.db.Migrator().HasTable("schema_migrations") {
+		return fmt.Errorf("%w: database has no schema_migrations table", ErrSchemaOutdated)
+	}
+	version, err := currentVersion(m.db)
+	if err != nil {
+		return err
+	}
+	return m.compare(version)
+}
+
+func (m *Migrator) compare(version int) error {
+	if version > m.Latest() {
+		return fmt.Errorf("%w: database is at version %d, latest known migration is %d",
+			ErrSchemaTooNew, version, m.Latest())
+	}
+	if version < m.Latest() {
+		return fmt.Errorf("%w: database is at version %d, latest migration is %d",
+			ErrSchemaOutdated, version, m.Latest())
+	}
+	return nil
+}
+
+// Status returns all known migrations with time they were applied
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	err := m.prepare()
+	if err != nil {
+		return nil, err
+	}
+	var applied []AppliedMigration
+	err = m.db.Table("schema_migrations").Find(&applied).Error
+	if err != nil {
+		return nil, err
+	}
+	appliedAt := make(map[int]time.Time, len(applied))
+	for _, migration := range applied {
+		appliedAt[migration.Version] = migration.AppliedAt
+	}
+
+	status := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		migrationStatus := MigrationStatus{Migration: migration}
+		if at, ok := appliedAt[migration.Version]; ok {
+			migrationStatus.AppliedAt = &at
+		}
+		status = append(status, migrationStatus)
+	}
+	return status, nil
+}
+
+// Up applies pending migrations in order, each in its own transaction, and returns applied ones.
+// It refuses to run against schema newer than this build
+func (m *Migrator) Up() ([]Migration, error) {
+	err := m.Check()
+	if err != nil && !errors.Is(err, ErrSchemaOutdated) {
+		return nil, err
+	}
+
+	var applied []Migration
+	for _, migration := range m.migrations {
+		done, err := m.apply(migration)
+		if err != nil {
+			return applied, fmt.Errorf("migration %04d_%s failed: %w", migration.Version, migration.Name, err)
+		}
+		if done {
+			applied = append(applied, migration)
+		}
+	}
+	return applied, nil
+}
+
+// Down reverts given count of latest applied migrations and returns reverted ones
+func (m *Migrator) Down(steps int) ([]Migration, error) {
+	err := m.Check()
+	if errors.Is(err, ErrSchemaTooNew) {
+		return nil, err
+	}
+
+	var reverted []Migration
+	for i := 0; i < steps; i++ {
+		migration, done, err := m.revertLatest()
+		if err != nil {
+			return reverted, err
+		}
+		if !done {
+			break
+		}
+		reverted = append(reverted, migration)
+	}
+	return reverted, nil
+}
+
+// apply runs migration unless it was applied already, returns false if there was nothing to do
+func (m *Migrator) apply(migration Migration) (bool, error) {
+	done := false
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		err := lock(tx)
+		if err != nil {
+			return err
+		}
+		//	Version is checked again under lock, other instance could apply migration meanwhile
+		version, err := currentVersion(tx)
+		if err != nil || version >= migration.Version {
+			return err
+		}
+
+		err = tx.Exec(migration.Up).Error
+		if err != nil {
+			return err
+		}
+		done = true
+		return tx.Table("schema_migrations").Create(&AppliedMigration{
+			Version:   migration.Version,
+			Name:      migration.Name,
+			AppliedAt: time.Now().UTC(),
+		}).Error
+	})
+	return done, err
+}
+
+// revertLatest reverts latest applied migration, returns false if no migration is applied
+func (m *Migrator) revertLatest() (Migration, bool, error) {
+	var reverted Migration
+	done := false
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		err := lock(tx)
+		if err != nil {
+			return err
+		}
+		version, err := currentVersion(tx)
+		if err != nil || version == 0 {
+			return err
+		}
+
+		migration, ok := m.find(version)
+		if !ok {
+			return fmt.Errorf("applied migration %d is not known to this build", version)
+		}
+		err = tx.Exec(migration.Down).Error
+		if err != nil {
+			return fmt.Errorf("reverting migration %04d_%s failed: %w", migration.Version, migration.Name, err)
+		}
+		reverted, done = migration, true
+		return tx.Table("schema_migrations").Where("version = ?", version).Delete(&AppliedMigration{}).Error
+	})
+	return reverted, done, err
+}
+
+func (m *Migrator) find(version int) (Migration, bool) {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return migration, true
+		}
+	}
+	return Migration{}, false
+}
+
+// createTable matches table names in CREATE TABLE statements of migration
+var createTable = regexp.MustCompile(`(?i)CREATE TABLE (?:IF NOT EXISTS )?(\w+)`)
+
+// ErrLegacySchemaIncomplete is returned when database without schema_migrations table has only part
+// of tables of first migration, so recording first migration as applied would leave tables missing
+var ErrLegacySchemaIncomplete = errors.New("database schema created before migrations is incomplete")
+
+// prepare creates schema_migrations table. Database created by AutoMigrate before migrations
+// were introduced is recorded at first migration, which describes the same schema,
+// later migrations are then applied to it as to any other database
+func (m *Migrator) prepare() error {
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		err := lock(tx)
+		if err != nil {
+			return err
+		}
+		if tx.Migrator().HasTable("schema_migrations") {
+			return nil
+		}
+
+		timestampType := "timestamptz"
+		if tx.Dialector.Name() == "sqlite" {
+			timestampType = "datetime"
+		}
+		err = tx.Exec(fmt.Sprintf(`CREATE TABLE schema_migrations (
+			version    bigint PRIMARY KEY,
+			name       text NOT NULL,
+			applied_at %s NOT NULL
+		)`, timestampType)).Error
+		if err != nil {
+			return err
+		}
+
+		if !tx.Migrator().HasTable("users") || len(m.migrations) == 0 {
+			return nil
+		}
+		var missing []string
+		for _, match := range createTable.FindAllStringSubmatch(m.migrations[0].Up, -1) {
+			if !tx.Migrator().HasTable(match[1]) {
+				missing = append(missing, match[1])
+			}
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("%w: tables %s of migration %04d_%s do not exist", ErrLegacySchemaIncomplete,
+				strings.Join(missing, ", "), m.migrations[0].Version, m.migrations[0].Name)
+		}
+		return tx.Table("schema_migrations").Create(&AppliedMigration{
+			Version:   m.migrations[0].Version,
+			Name:      m.migrations[0].Name,
+			AppliedAt: time.Now().UTC(),
+		}).Error
+	})
+}
+
+// lock takes advisory lock until end of transaction. SQLite needs no lock,
+// its transactions hold write lock of whole database
+func lock(tx *gorm.DB) error {
+	if tx.Dialector.Name() != "postgres" {
+		return nil
+	}
+	return tx.Exec("SELECT pg_advisory_xact_lock(?)", migrationsLockId).Error
+}
+
+func currentVersion(db *gorm.DB) (int, error) {
+	var version int
+	err := db.Table("schema_migrations").Select("COALESCE(MAX(version), 0)").Scan(&version).Error
+	return version, err
+}
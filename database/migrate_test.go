@@ -0,0 +1,232 @@
+package database_test
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"path/filepath"
+	"testing"
+	"tiktok-arena/configuration"
+	"tiktok-arena/database"
+	"tiktok-arena/models"
+)
+
+// Models as they were when schema was created by AutoMigrate, before migrations were introduced
+type baselineUser struct {
+	ID       *uuid.UUID `gorm:"type:uuid;primary_key"`
+	Name     string     `gorm:"not null"`
+	Password string     `gorm:"not null"`
+}
+
+type baselineTournament struct {
+	ID     *uuid.UUID    `gorm:"type:uuid;primary_key"`
+	Name   string        `gorm:"not null"`
+	Size   int           `gorm:"not null"`
+	UserID *uuid.UUID    `gorm:"not null"`
+	User   *baselineUser `gorm:"foreignKey:UserID"`
+}
+
+type baselineTiktok struct {
+	ID           *uuid.UUID          `gorm:"type:uuid;primary_key"`
+	TournamentID *uuid.UUID          `gorm:"not null"`
+	Tournament   *baselineTournament `gorm:"foreignKey:TournamentID"`
+	URL          string              `gorm:"not null"`
+	Wins         int
+	AvgPoints    float64
+	TimesPlayed  int
+}
+
+func (baselineUser) TableName() string       { return "users" }
+func (baselineTournament) TableName() string { return "tournaments" }
+func (baselineTiktok) TableName() string     { return "tiktoks" }
+
+func openSQLite(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := database.Open(&configuration.EnvConfigModel{
+		DBDriver:   "sqlite",
+		SQLitePath: filepath.Join(t.TempDir(), "arena.db"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		sqlDB, err := db.DB()
+		if err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+	return db
+}
+
+func newMigrator(t *testing.T, db *gorm.DB) *database.Migrator {
+	t.Helper()
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return migrator
+}
+
+func TestMigrationsUpgradeBaselineSchema(t *testing.T) {
+	db := openSQLite(t)
+	err := db.AutoMigrate(&baselineUser{}, &baselineTournament{}, &baselineTiktok{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	userId, tournamentId, tiktokId := uuid.New(), uuid.New(), uuid.New()
+	err = db.Create(&baselineUser{ID: &userId, Name: "alice", Password: "hash"}).Error
+	if err == nil {
+		err = db.Create(&baselineTournament{ID: &tournamentId, Name: "cats", Size: 4, UserID: &userId}).Error
+	}
+	if err == nil {
+		err = db.Create(&baselineTiktok{ID: &tiktokId, TournamentID: &tournamentId, URL: "https://tiktok.com/1",
+			Wins: 2}).Error
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	migrator := newMigrator(t, db)
+	applied, err := migrator.Up()
+	if err != nil {
+		t.Fatal(err)
+	}
+	//	Baseline schema is adopted as first migration, every later migration is applied to it
+	if len(applied) != migrator.Latest()-1 || applied[0].Version != 2 {
+		t.Fatalf("applied %+v, expected every migration but first", applied)
+	}
+	err = migrator.Check()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var user models.User
+	err = db.First(&user, "id = ?", userId).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Name != "alice" || user.Role != models.RoleUser || user.Banned || user.CreatedAt.IsZero() {
+		t.Fatalf("unexpected migrated user: %+v", user)
+	}
+	var tiktok models.Tiktok
+	err = db.First(&tiktok, "id = ?", tiktokId).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tiktok.Wins != 2 || tiktok.Hidden {
+		t.Fatalf("unexpected migrated tiktok: %+v", tiktok)
+	}
+
+	//	Usernames differing only in letter case are rejected once migrations are applied
+	err = db.Create(&models.User{Name: "ALICE", Password: "hash", Role: models.RoleUser}).Error
+	if err == nil {
+		t.Fatal("created user with name differing only in case")
+	}
+}
+
+func TestMigrationsRejectIncompleteBaselineSchema(t *testing.T) {
+	db := openSQLite(t)
+	err := db.AutoMigrate(&baselineUser{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = newMigrator(t, db).Up()
+	if !errors.Is(err, database.ErrLegacySchemaIncomplete) {
+		t.Fatalf("expected ErrLegacySchemaIncomplete, got %v", err)
+	}
+}
+
+// schemaModels are models stored by database package, migrated schema must have column for every field
+var schemaModels = []interface{}{
+	&models.User{}, &models.RecoveryCode{}, &models.UserIdentity{}, &models.LoginAttempt{},
+	&models.Tournament{}, &models.Tiktok{}, &models.Contest{}, &models.TournamentUpvote{}, &models.Follow{},
+	&models.Report{}, &models.ModerationAction{}, &models.APIToken{}, &models.Notification{},
+	&models.Webhook{}, &models.WebhookDelivery{},
+}
+
+func TestMigrationsUpAndDown(t *testing.T) {
+	db := openSQLite(t)
+	migrator := newMigrator(t, db)
+
+	applied, err := migrator.Up()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != migrator.Latest() {
+		t.Fatalf("applied %d migrations to empty database, expected %d", len(applied), migrator.Latest())
+	}
+	for _, model := range schemaModels {
+		statement := &gorm.Statement{DB: db}
+		err = statement.Parse(model)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, column := range statement.Schema.DBNames {
+			if !db.Migrator().HasColumn(model, column) {
+				t.Errorf("table %s has no column %s", statement.Schema.Table, column)
+			}
+		}
+	}
+	status, err := migrator.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, migration := range status {
+		if migration.AppliedAt == nil {
+			t.Errorf("migration %04d_%s is not applied", migration.Version, migration.Name)
+		}
+	}
+
+	//	Every migration can be reverted and applied again
+	reverted, err := migrator.Down(migrator.Latest())
+	if err != nil {
+		t.Fatal(err)
+	}
+	version, err := migrator.Version()
+	if err != nil || version != 0 || len(reverted) != migrator.Latest() {
+		t.Fatalf("reverted %d migrations to version %d (%v), expected all", len(reverted), version, err)
+	}
+	for _, model := range schemaModels {
+		if db.Migrator().HasTable(model) {
+			t.Errorf("table of %T exists after reverting every migration", model)
+		}
+	}
+	_, err = migrator.Up()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = migrator.Check()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckAppliedOnlyReadsDatabase(t *testing.T) {
+	db := openSQLite(t)
+	migrator := newMigrator(t, db)
+
+	err := migrator.CheckApplied()
+	if !errors.Is(err, database.ErrSchemaOutdated) {
+		t.Fatalf("expected ErrSchemaOutdated for empty database, got %v", err)
+	}
+	if db.Migrator().HasTable("schema_migrations") {
+		t.Fatal("CheckApplied created schema_migrations table")
+	}
+
+	_, err = migrator.Up()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = migrator.CheckApplied()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = migrator.Down(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = migrator.CheckApplied()
+	if !errors.Is(err, database.ErrSchemaOutdated) {
+		t.Fatalf("expected ErrSchemaOutdated after reverting migration, got %v", err)
+	}
+}
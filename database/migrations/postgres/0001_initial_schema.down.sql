@@ -0,0 +1,4 @@
+-- uuid-ossp extension is kept, it may be used by other schemas of the database
+DROP TABLE tiktoks;
+DROP TABLE tournaments;
+DROP TABLE users;
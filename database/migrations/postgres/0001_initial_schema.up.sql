@@ -0,0 +1,27 @@
+-- Schema created by GORM AutoMigrate before migrations were introduced.
+-- Databases created by AutoMigrate are adopted at this version without running it
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+
+CREATE TABLE users (
+    id       uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name     text NOT NULL,
+    password text NOT NULL
+);
+
+CREATE TABLE tournaments (
+    id      uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name    text   NOT NULL,
+    size    bigint NOT NULL,
+    user_id uuid   NOT NULL,
+    CONSTRAINT fk_tournaments_user FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+CREATE TABLE tiktoks (
+    id            uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tournament_id uuid NOT NULL,
+    url           text NOT NULL,
+    wins          bigint,
+    avg_points    decimal,
+    times_played  bigint,
+    CONSTRAINT fk_tiktoks_tournament FOREIGN KEY (tournament_id) REFERENCES tournaments (id)
+);
@@ -0,0 +1,2 @@
+-- Usernames are unique regardless of letter case
+CREATE UNIQUE INDEX idx_users_name_lower ON users (LOWER(name));
@@ -0,0 +1,5 @@
+CREATE TABLE login_attempts (
+    key          text PRIMARY KEY,
+    failures     bigint NOT NULL,
+    last_failure timestamptz NOT NULL
+);
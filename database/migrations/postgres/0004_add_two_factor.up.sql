@@ -0,0 +1,12 @@
+ALTER TABLE users ADD COLUMN totp_secret text;
+ALTER TABLE users ADD COLUMN totp_enabled boolean NOT NULL DEFAULT false;
+ALTER TABLE users ADD COLUMN totp_last_step bigint NOT NULL DEFAULT 0;
+
+CREATE TABLE recovery_codes (
+    id        uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id   uuid NOT NULL,
+    code_hash text NOT NULL,
+    used_at   timestamptz,
+    CONSTRAINT fk_recovery_codes_user FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX idx_recovery_codes_user_id ON recovery_codes (user_id);
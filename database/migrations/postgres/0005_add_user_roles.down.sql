@@ -0,0 +1,4 @@
+ALTER TABLE users DROP COLUMN sessions_revoked_at;
+ALTER TABLE users DROP COLUMN must_reset_password;
+ALTER TABLE users DROP COLUMN banned;
+ALTER TABLE users DROP COLUMN role;
@@ -0,0 +1,5 @@
+ALTER TABLE users ADD COLUMN role text NOT NULL DEFAULT 'user';
+ALTER TABLE users ADD COLUMN banned boolean NOT NULL DEFAULT false;
+ALTER TABLE users ADD COLUMN must_reset_password boolean NOT NULL DEFAULT false;
+-- JWT sessions issued before this time are rejected, set when administrator forces password reset
+ALTER TABLE users ADD COLUMN sessions_revoked_at timestamptz;
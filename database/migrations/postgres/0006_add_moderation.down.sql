@@ -0,0 +1,4 @@
+DROP TABLE moderation_actions;
+DROP TABLE reports;
+ALTER TABLE tiktoks DROP COLUMN hidden;
+ALTER TABLE tournaments DROP COLUMN hidden;
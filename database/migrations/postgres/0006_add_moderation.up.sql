@@ -0,0 +1,32 @@
+ALTER TABLE tournaments ADD COLUMN hidden boolean NOT NULL DEFAULT false;
+ALTER TABLE tiktoks ADD COLUMN hidden boolean NOT NULL DEFAULT false;
+
+CREATE TABLE reports (
+    id             uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    reporter_id    uuid NOT NULL,
+    target_type    text NOT NULL,
+    target_id      uuid NOT NULL,
+    reason         text NOT NULL,
+    details        text,
+    status         text NOT NULL DEFAULT 'open',
+    created_at     timestamptz,
+    resolved_at    timestamptz,
+    resolved_by_id uuid,
+    CONSTRAINT fk_reports_reporter FOREIGN KEY (reporter_id) REFERENCES users (id)
+);
+CREATE INDEX idx_reports_status ON reports (status);
+
+CREATE TABLE moderation_actions (
+    id           uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    report_id    uuid,
+    moderator_id uuid NOT NULL,
+    action       text NOT NULL,
+    target_type  text NOT NULL,
+    target_id    uuid NOT NULL,
+    author_id    uuid,
+    note         text,
+    created_at   timestamptz,
+    CONSTRAINT fk_moderation_actions_report FOREIGN KEY (report_id) REFERENCES reports (id),
+    CONSTRAINT fk_moderation_actions_moderator FOREIGN KEY (moderator_id) REFERENCES users (id)
+);
+CREATE INDEX idx_moderation_actions_report_id ON moderation_actions (report_id);
@@ -0,0 +1,14 @@
+CREATE TABLE api_tokens (
+    id           uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id      uuid NOT NULL,
+    name         text NOT NULL,
+    prefix       text NOT NULL,
+    token_hash   text NOT NULL,
+    scopes       text NOT NULL,
+    expires_at   timestamptz,
+    last_used_at timestamptz,
+    created_at   timestamptz,
+    CONSTRAINT fk_api_tokens_user FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX idx_api_tokens_user_id ON api_tokens (user_id);
+CREATE UNIQUE INDEX idx_api_tokens_token_hash ON api_tokens (token_hash);
@@ -0,0 +1,29 @@
+ALTER TABLE users ADD COLUMN display_name text NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN bio text NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN avatar_url text NOT NULL DEFAULT '';
+-- Existing users and tournaments get time of migration
+ALTER TABLE users ADD COLUMN created_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE tournaments ADD COLUMN created_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+CREATE TABLE tournament_upvotes (
+    tournament_id uuid,
+    user_id       uuid,
+    created_at    timestamptz,
+    PRIMARY KEY (tournament_id, user_id),
+    CONSTRAINT fk_tournament_upvotes_tournament FOREIGN KEY (tournament_id) REFERENCES tournaments (id),
+    CONSTRAINT fk_tournament_upvotes_user FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX idx_tournament_upvotes_user_id ON tournament_upvotes (user_id);
+
+CREATE TABLE contests (
+    id               uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tournament_id    uuid NOT NULL,
+    user_id          uuid NOT NULL,
+    contest_type     text NOT NULL,
+    winner_tiktok_id uuid NOT NULL,
+    created_at       timestamptz,
+    CONSTRAINT fk_contests_tournament FOREIGN KEY (tournament_id) REFERENCES tournaments (id),
+    CONSTRAINT fk_contests_user FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX idx_contests_tournament_id ON contests (tournament_id);
+CREATE INDEX idx_contests_user_id ON contests (user_id);
@@ -0,0 +1,2 @@
+ALTER TABLE tournaments DROP COLUMN upvote_milestone;
+DROP TABLE notifications;
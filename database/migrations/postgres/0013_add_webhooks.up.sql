@@ -0,0 +1,29 @@
+CREATE TABLE webhooks (
+    id         uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id    uuid NOT NULL,
+    url        text NOT NULL,
+    secret     text NOT NULL,
+    events     text NOT NULL,
+    created_at timestamptz,
+    CONSTRAINT fk_webhooks_user FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX idx_webhooks_user_id ON webhooks (user_id);
+
+CREATE TABLE webhook_deliveries (
+    id              uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    webhook_id      uuid   NOT NULL,
+    event_type      text   NOT NULL,
+    payload         text   NOT NULL,
+    status          text   NOT NULL,
+    attempts        bigint NOT NULL DEFAULT 0,
+    next_attempt_at timestamptz,
+    last_attempt_at timestamptz,
+    response_status bigint,
+    last_error      text,
+    replay_of_id    uuid,
+    created_at      timestamptz,
+    CONSTRAINT fk_webhook_deliveries_webhook FOREIGN KEY (webhook_id) REFERENCES webhooks (id)
+);
+CREATE INDEX idx_webhook_deliveries_webhook_id ON webhook_deliveries (webhook_id);
+CREATE INDEX idx_webhook_deliveries_status ON webhook_deliveries (status);
+CREATE INDEX idx_webhook_deliveries_next_attempt_at ON webhook_deliveries (next_attempt_at);
@@ -0,0 +1,3 @@
+DROP TABLE tiktoks;
+DROP TABLE tournaments;
+DROP TABLE users;
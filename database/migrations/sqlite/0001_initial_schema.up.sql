@@ -0,0 +1,26 @@
+-- Same schema as postgres/0001_initial_schema.up.sql. UUIDs are stored as text and generated by application,
+-- booleans and timestamps use type names go-sqlite3 converts back to Go values
+
+CREATE TABLE users (
+    id       text PRIMARY KEY,
+    name     text NOT NULL,
+    password text NOT NULL
+);
+
+CREATE TABLE tournaments (
+    id      text PRIMARY KEY,
+    name    text   NOT NULL,
+    size    bigint NOT NULL,
+    user_id text   NOT NULL,
+    CONSTRAINT fk_tournaments_user FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+CREATE TABLE tiktoks (
+    id            text PRIMARY KEY,
+    tournament_id text NOT NULL,
+    url           text NOT NULL,
+    wins          bigint,
+    avg_points    real,
+    times_played  bigint,
+    CONSTRAINT fk_tiktoks_tournament FOREIGN KEY (tournament_id) REFERENCES tournaments (id)
+);
@@ -0,0 +1,32 @@
+ALTER TABLE tournaments ADD COLUMN hidden boolean NOT NULL DEFAULT false;
+ALTER TABLE tiktoks ADD COLUMN hidden boolean NOT NULL DEFAULT false;
+
+CREATE TABLE reports (
+    id             text PRIMARY KEY,
+    reporter_id    text NOT NULL,
+    target_type    text NOT NULL,
+    target_id      text NOT NULL,
+    reason         text NOT NULL,
+    details        text,
+    status         text NOT NULL DEFAULT 'open',
+    created_at     datetime,
+    resolved_at    datetime,
+    resolved_by_id text,
+    CONSTRAINT fk_reports_reporter FOREIGN KEY (reporter_id) REFERENCES users (id)
+);
+CREATE INDEX idx_reports_status ON reports (status);
+
+CREATE TABLE moderation_actions (
+    id           text PRIMARY KEY,
+    report_id    text,
+    moderator_id text NOT NULL,
+    action       text NOT NULL,
+    target_type  text NOT NULL,
+    target_id    text NOT NULL,
+    author_id    text,
+    note         text,
+    created_at   datetime,
+    CONSTRAINT fk_moderation_actions_report FOREIGN KEY (report_id) REFERENCES reports (id),
+    CONSTRAINT fk_moderation_actions_moderator FOREIGN KEY (moderator_id) REFERENCES users (id)
+);
+CREATE INDEX idx_moderation_actions_report_id ON moderation_actions (report_id);
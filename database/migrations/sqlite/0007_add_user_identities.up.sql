@@ -0,0 +1,10 @@
+CREATE TABLE user_identities (
+    id         text PRIMARY KEY,
+    user_id    text NOT NULL,
+    issuer     text NOT NULL,
+    subject    text NOT NULL,
+    created_at datetime,
+    CONSTRAINT fk_user_identities_user FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX idx_user_identities_user_id ON user_identities (user_id);
+CREATE UNIQUE INDEX idx_user_identities_issuer_subject ON user_identities (issuer, subject);
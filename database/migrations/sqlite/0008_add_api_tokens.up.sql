@@ -0,0 +1,14 @@
+CREATE TABLE api_tokens (
+    id           text PRIMARY KEY,
+    user_id      text NOT NULL,
+    name         text NOT NULL,
+    prefix       text NOT NULL,
+    token_hash   text NOT NULL,
+    scopes       text NOT NULL,
+    expires_at   datetime,
+    last_used_at datetime,
+    created_at   datetime,
+    CONSTRAINT fk_api_tokens_user FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX idx_api_tokens_user_id ON api_tokens (user_id);
+CREATE UNIQUE INDEX idx_api_tokens_token_hash ON api_tokens (token_hash);
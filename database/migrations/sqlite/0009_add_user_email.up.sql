@@ -0,0 +1,3 @@
+ALTER TABLE users ADD COLUMN email text;
+ALTER TABLE users ADD COLUMN email_verified boolean NOT NULL DEFAULT false;
+CREATE UNIQUE INDEX idx_users_email ON users (email);
@@ -0,0 +1,7 @@
+DROP TABLE contests;
+DROP TABLE tournament_upvotes;
+ALTER TABLE tournaments DROP COLUMN created_at;
+ALTER TABLE users DROP COLUMN created_at;
+ALTER TABLE users DROP COLUMN avatar_url;
+ALTER TABLE users DROP COLUMN bio;
+ALTER TABLE users DROP COLUMN display_name;
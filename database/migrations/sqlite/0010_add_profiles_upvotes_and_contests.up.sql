@@ -0,0 +1,32 @@
+ALTER TABLE users ADD COLUMN display_name text NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN bio text NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN avatar_url text NOT NULL DEFAULT '';
+-- SQLite can not add column with CURRENT_TIMESTAMP default, existing users and tournaments
+-- get time of migration by update instead. New rows get created_at from application
+ALTER TABLE users ADD COLUMN created_at datetime NOT NULL DEFAULT '1970-01-01 00:00:00';
+UPDATE users SET created_at = CURRENT_TIMESTAMP;
+ALTER TABLE tournaments ADD COLUMN created_at datetime NOT NULL DEFAULT '1970-01-01 00:00:00';
+UPDATE tournaments SET created_at = CURRENT_TIMESTAMP;
+
+CREATE TABLE tournament_upvotes (
+    tournament_id text,
+    user_id       text,
+    created_at    datetime,
+    PRIMARY KEY (tournament_id, user_id),
+    CONSTRAINT fk_tournament_upvotes_tournament FOREIGN KEY (tournament_id) REFERENCES tournaments (id),
+    CONSTRAINT fk_tournament_upvotes_user FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX idx_tournament_upvotes_user_id ON tournament_upvotes (user_id);
+
+CREATE TABLE contests (
+    id               text PRIMARY KEY,
+    tournament_id    text NOT NULL,
+    user_id          text NOT NULL,
+    contest_type     text NOT NULL,
+    winner_tiktok_id text NOT NULL,
+    created_at       datetime,
+    CONSTRAINT fk_contests_tournament FOREIGN KEY (tournament_id) REFERENCES tournaments (id),
+    CONSTRAINT fk_contests_user FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX idx_contests_tournament_id ON contests (tournament_id);
+CREATE INDEX idx_contests_user_id ON contests (user_id);
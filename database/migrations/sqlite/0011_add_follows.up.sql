@@ -0,0 +1,9 @@
+CREATE TABLE follows (
+    follower_id text,
+    followee_id text,
+    created_at  datetime,
+    PRIMARY KEY (follower_id, followee_id),
+    CONSTRAINT fk_follows_follower FOREIGN KEY (follower_id) REFERENCES users (id),
+    CONSTRAINT fk_follows_followee FOREIGN KEY (followee_id) REFERENCES users (id)
+);
+CREATE INDEX idx_follows_followee_id ON follows (followee_id);
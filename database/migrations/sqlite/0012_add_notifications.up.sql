@@ -0,0 +1,20 @@
+CREATE TABLE notifications (
+    id          text PRIMARY KEY,
+    user_id     text NOT NULL,
+    type        text NOT NULL,
+    message     text NOT NULL,
+    actor_id    text,
+    target_type text,
+    target_id   text,
+    read_at     datetime,
+    created_at  datetime,
+    CONSTRAINT fk_notifications_user FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX idx_notifications_user_created ON notifications (user_id, created_at);
+
+-- Highest upvote milestone owner was notified about, so milestone is not notified again after upvote is removed
+-- and given again. Milestones up to current count of upvotes of existing tournaments were already notified
+ALTER TABLE tournaments ADD COLUMN upvote_milestone bigint NOT NULL DEFAULT 0;
+UPDATE tournaments SET upvote_milestone = (
+    SELECT COUNT(*) FROM tournament_upvotes WHERE tournament_upvotes.tournament_id = tournaments.id
+);
@@ -0,0 +1,172 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"tiktok-arena/models"
+	"tiktok-arena/store"
+	"time"
+)
+
+// reportTargetTables maps report target type to table with reported content
+var reportTargetTables = map[string]string{
+	models.ReportTargetTournament: "tournaments",
+	models.ReportTargetTiktok:     "tiktoks",
+}
+
+func (s *Store) CreateReport(ctx context.Context, newReport *models.Report) error {
+	record := s.db.WithContext(ctx).Table("reports").Create(&newReport)
+	return record.Error
+}
+
+func (s *Store) CheckIfOpenReportExists(ctx context.Context, reporterId string, targetType string, targetId string) bool {
+	var report models.Report
+	s.db.WithContext(ctx).Table("reports").Select("id").First(&report,
+		"reporter_id = ? AND target_type = ? AND target_id = ? AND status = ?",
+		reporterId, targetType, targetId, models.ReportStatusOpen)
+	return report.ID != nil
+}
+
+func (s *Store) GetReportById(ctx context.Context, reportId string) (models.Report, error) {
+	var report models.Report
+	record := s.db.WithContext(ctx).Table("reports").First(&report, "id = ?", reportId)
+	return report, notFound(record.Error)
+}
+
+// GetReports returns page of reports with given status, oldest first, so queue is handled in order
+func (s *Store) GetReports(ctx context.Context, status string, pagination models.Pagination) ([]models.Report, int64, error) {
+	var reports []models.Report
+	var total int64
+
+	query := s.db.WithContext(ctx).Table("reports").Where("status = ?", status).Session(&gorm.Session{})
+
+	record := query.Count(&total)
+	if record.Error != nil {
+		return nil, 0, record.Error
+	}
+
+	record = query.
+		Order("created_at").
+		Offset(pagination.Offset()).
+		Limit(pagination.Count).
+		Find(&reports)
+	return reports, total, record.Error
+}
+
+func (s *Store) GetModerationActions(ctx context.Context, pagination models.Pagination) ([]models.ModerationAction, int64, error) {
+	var actions []models.ModerationAction
+	var total int64
+
+	record := s.db.WithContext(ctx).Table("moderation_actions").Count(&total)
+	if record.Error != nil {
+		return nil, 0, record.Error
+	}
+
+	record = s.db.WithContext(ctx).Table("moderation_actions").
+		Order("created_at DESC").
+		Offset(pagination.Offset()).
+		Limit(pagination.Count).
+		Find(&actions)
+	return actions, total, record.Error
+}
+
+// GetContentAuthorId returns id of user who created reported content
+func (s *Store) GetContentAuthorId(ctx context.Context, targetType string, targetId string) (*uuid.UUID, error) {
+	var tournament models.Tournament
+	var record *gorm.DB
+	switch targetType {
+	case models.ReportTargetTournament:
+		record = s.db.WithContext(ctx).Table("tournaments").Select("user_id").First(&tournament, "id = ?", targetId)
+	case models.ReportTargetTiktok:
+		record = s.db.WithContext(ctx).Table("tournaments").Select("tournaments.user_id").
+			Joins("JOIN tiktoks ON tiktoks.tournament_id = tournaments.id").
+			First(&tournament, "tiktoks.id = ?", targetId)
+	default:
+		return nil, fmt.Errorf("unknown report target type %s", targetType)
+	}
+	return tournament.UserID, notFound(record.Error)
+}
+
+// GetContentTitle returns tournament name or tiktok URL, used to refer to content in notifications
+func (s *Store) GetContentTitle(ctx context.Context, targetType string, targetId string) (string, error) {
+	var title string
+	var record *gorm.DB
+	switch targetType {
+	case models.ReportTargetTournament:
+		record = s.db.WithContext(ctx).Table("tournaments").Select("name").Where("id = ?", targetId).Scan(&title)
+	case models.ReportTargetTiktok:
+		record = s.db.WithContext(ctx).Table("tiktoks").Select("url").Where("id = ?", targetId).Scan(&title)
+	default:
+		return "", fmt.Errorf("unknown report target type %s", targetType)
+	}
+	return title, record.Error
+}
+
+// CloseReport applies moderation action, records it and closes report with given status in one transaction
+func (s *Store) CloseReport(ctx context.Context, report *models.Report, action *models.ModerationAction, status string) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UTC()
+
+		record := tx.Table("reports").
+			Where("id = ? AND status = ?", report.ID, models.ReportStatusOpen).
+			Updates(map[string]interface{}{
+				"status":         status,
+				"resolved_at":    now,
+				"resolved_by_id": action.ModeratorID,
+			})
+		if record.Error != nil {
+			return record.Error
+		}
+		if record.RowsAffected == 0 {
+			return fmt.Errorf("report %s is already closed: %w", report.ID, store.ErrConflict)
+		}
+
+		err := applyModerationAction(tx, action)
+		if err != nil {
+			return err
+		}
+
+		return tx.Table("moderation_actions").Create(&action).Error
+	})
+}
+
+func applyModerationAction(tx *gorm.DB, action *models.ModerationAction) error {
+	switch action.Action {
+	case models.ModerationHide:
+		table, ok := reportTargetTables[action.TargetType]
+		if !ok {
+			return fmt.Errorf("unknown report target type %s", action.TargetType)
+		}
+		return tx.Table(table).Where("id = ?", action.TargetID).Update("hidden", true).Error
+	case models.ModerationBanAuthor:
+		err := checkCanBan(tx, action)
+		if err != nil {
+			return err
+		}
+		return tx.Table("users").Where("id = ?", action.AuthorID).Update("banned", true).Error
+	}
+	return nil
+}
+
+// checkCanBan reads roles of moderator and author in the same transaction as ban,
+// so role changed by concurrent request is respected
+func checkCanBan(tx *gorm.DB, action *models.ModerationAction) error {
+	if action.AuthorID == nil || action.ModeratorID == nil || *action.AuthorID == *action.ModeratorID {
+		return fmt.Errorf("author of content can not be banned by this moderator: %w", store.ErrForbidden)
+	}
+	var moderatorRole, authorRole string
+	record := tx.Table("users").Select("role").Where("id = ?", action.ModeratorID).Scan(&moderatorRole)
+	if record.Error != nil {
+		return record.Error
+	}
+	record = tx.Table("users").Select("role").Where("id = ?", action.AuthorID).Scan(&authorRole)
+	if record.Error != nil {
+		return record.Error
+	}
+	if !models.CanBan(moderatorRole, authorRole) {
+		return fmt.Errorf("%s can not ban %s %s: %w", moderatorRole, authorRole, action.AuthorID, store.ErrForbidden)
+	}
+	return nil
+}
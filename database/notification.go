@@ -0,0 +1,64 @@
+package database
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"tiktok-arena/models"
+	"tiktok-arena/store"
+	"time"
+)
+
+func (s *Store) CreateNotification(ctx context.Context, notification *models.Notification) error {
+	return s.db.WithContext(ctx).Table("notifications").Create(notification).Error
+}
+
+// GetNotifications returns page of notifications of user, newest first
+func (s *Store) GetNotifications(ctx context.Context, userId string, unreadOnly bool, pagination models.Pagination) ([]models.Notification, int64, error) {
+	var notifications []models.Notification
+	var total int64
+
+	query := s.db.WithContext(ctx).Table("notifications").Where("user_id = ?", userId)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	query = query.Session(&gorm.Session{})
+
+	record := query.Count(&total)
+	if record.Error != nil {
+		return nil, 0, record.Error
+	}
+
+	record = query.
+		Order("created_at DESC").
+		Offset(pagination.Offset()).
+		Limit(pagination.Count).
+		Find(&notifications)
+	return notifications, total, record.Error
+}
+
+func (s *Store) CountUnreadNotifications(ctx context.Context, userId string) (int64, error) {
+	var count int64
+	record := s.db.WithContext(ctx).Table("notifications").Where("user_id = ? AND read_at IS NULL", userId).Count(&count)
+	return count, record.Error
+}
+
+// MarkNotificationRead marks notification of user read, returns store.ErrNotFound if user has no such notification
+func (s *Store) MarkNotificationRead(ctx context.Context, userId string, notificationId string) error {
+	var count int64
+	record := s.db.WithContext(ctx).Table("notifications").Where("id = ? AND user_id = ?", notificationId, userId).Count(&count)
+	if record.Error != nil {
+		return record.Error
+	}
+	if count == 0 {
+		return store.ErrNotFound
+	}
+	return s.db.WithContext(ctx).Table("notifications").
+		Where("id = ? AND read_at IS NULL", notificationId).
+		Update("read_at", time.Now().UTC()).Error
+}
+
+func (s *Store) MarkAllNotificationsRead(ctx context.Context, userId string) error {
+	return s.db.WithContext(ctx).Table("notifications").
+		Where("user_id = ? AND read_at IS NULL", userId).
+		Update("read_at", time.Now().UTC()).Error
+}
@@ -0,0 +1,60 @@
+package database
+
+import (
+	"context"
+	"tiktok-arena/models"
+)
+
+// GetUserStats counts public tournaments of user, contests user played, upvotes given to user tournaments
+// and followers of user
+func (s *Store) GetUserStats(ctx context.Context, userId string) (models.UserStats, error) {
+	var stats models.UserStats
+
+	record := s.db.WithContext(ctx).Table("tournaments").
+		Where("user_id = ? AND hidden = ?", userId, false).
+		Count(&stats.TournamentsCreated)
+	if record.Error != nil {
+		return stats, record.Error
+	}
+
+	record = s.db.WithContext(ctx).Table("contests").Where("user_id = ?", userId).Count(&stats.ContestsPlayed)
+	if record.Error != nil {
+		return stats, record.Error
+	}
+
+	record = s.db.WithContext(ctx).Table("tournament_upvotes").
+		Joins("JOIN tournaments ON tournaments.id = tournament_upvotes.tournament_id").
+		Where("tournaments.user_id = ? AND tournaments.hidden = ?", userId, false).
+		Count(&stats.UpvotesReceived)
+	if record.Error != nil {
+		return stats, record.Error
+	}
+
+	record = s.db.WithContext(ctx).Table("follows").Where("followee_id = ?", userId).Count(&stats.Followers)
+	if record.Error != nil {
+		return stats, record.Error
+	}
+
+	record = s.db.WithContext(ctx).Table("follows").Where("follower_id = ?", userId).Count(&stats.Following)
+	return stats, record.Error
+}
+
+// GetUserPublicTournaments returns page of tournaments of user not hidden by moderators, newest first
+func (s *Store) GetUserPublicTournaments(ctx context.Context, userId string, pagination models.Pagination) ([]models.Tournament, error) {
+	var tournaments []models.Tournament
+	record := s.db.WithContext(ctx).Table("tournaments").
+		Where("user_id = ? AND hidden = ?", userId, false).
+		Order("created_at DESC").
+		Offset(pagination.Offset()).
+		Limit(pagination.Count).
+		Find(&tournaments)
+	return tournaments, record.Error
+}
+
+func (s *Store) UpdateUserProfile(ctx context.Context, userId string, profile *models.EditProfile) error {
+	return s.updateUser(ctx, userId, map[string]interface{}{
+		"display_name": profile.DisplayName,
+		"bio":          profile.Bio,
+		"avatar_url":   profile.AvatarURL,
+	})
+}
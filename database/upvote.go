@@ -0,0 +1,38 @@
+package database
+
+import (
+	"context"
+	"gorm.io/gorm/clause"
+	"tiktok-arena/models"
+)
+
+// AddTournamentUpvote upvotes tournament, returns false if user already upvoted it
+func (s *Store) AddTournamentUpvote(ctx context.Context, upvote *models.TournamentUpvote) (bool, error) {
+	record := s.db.WithContext(ctx).Table("tournament_upvotes").
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(upvote)
+	return record.RowsAffected > 0, record.Error
+}
+
+// RemoveTournamentUpvote removes upvote, returns false if user did not upvote tournament
+func (s *Store) RemoveTournamentUpvote(ctx context.Context, tournamentId string, userId string) (bool, error) {
+	record := s.db.WithContext(ctx).Table("tournament_upvotes").
+		Where("tournament_id = ? AND user_id = ?", tournamentId, userId).
+		Delete(&models.TournamentUpvote{})
+	return record.RowsAffected > 0, record.Error
+}
+
+// RaiseUpvoteMilestone records milestone reached by tournament, returns false if tournament already
+// reached the same or higher milestone. Conditional update lets only one of concurrent upvotes raise it
+func (s *Store) RaiseUpvoteMilestone(ctx context.Context, tournamentId string, milestone int64) (bool, error) {
+	record := s.db.WithContext(ctx).Table("tournaments").
+		Where("id = ? AND upvote_milestone < ?", tournamentId, milestone).
+		Update("upvote_milestone", milestone)
+	return record.RowsAffected > 0, record.Error
+}
+
+func (s *Store) CountTournamentUpvotes(ctx context.Context, tournamentId string) (int64, error) {
+	var count int64
+	record := s.db.WithContext(ctx).Table("tournament_upvotes").Where("tournament_id = ?", tournamentId).Count(&count)
+	return count, record.Error
+}
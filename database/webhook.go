@@ -0,0 +1,125 @@
+package database
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"tiktok-arena/models"
+	"tiktok-arena/store"
+	"time"
+)
+
+func (s *Store) CreateWebhook(ctx context.Context, newWebhook *models.Webhook) error {
+	return s.db.WithContext(ctx).Table("webhooks").Create(newWebhook).Error
+}
+
+func (s *Store) GetUserWebhooks(ctx context.Context, userId string) ([]models.Webhook, error) {
+	var webhooks []models.Webhook
+	record := s.db.WithContext(ctx).Table("webhooks").Where("user_id = ?", userId).Order("created_at").Find(&webhooks)
+	return webhooks, record.Error
+}
+
+func (s *Store) GetUserWebhook(ctx context.Context, userId string, webhookId string) (models.Webhook, error) {
+	var webhook models.Webhook
+	record := s.db.WithContext(ctx).Table("webhooks").First(&webhook, "id = ? AND user_id = ?", webhookId, userId)
+	return webhook, notFound(record.Error)
+}
+
+func (s *Store) GetWebhookById(ctx context.Context, webhookId string) (models.Webhook, error) {
+	var webhook models.Webhook
+	record := s.db.WithContext(ctx).Table("webhooks").First(&webhook, "id = ?", webhookId)
+	return webhook, notFound(record.Error)
+}
+
+// GetWebhooksForEvent returns webhooks subscribed to event type of given users who are not banned
+func (s *Store) GetWebhooksForEvent(ctx context.Context, eventType string, userIds []string) ([]models.Webhook, error) {
+	var webhooks []models.Webhook
+	record := s.db.WithContext(ctx).Table("webhooks").
+		Select("webhooks.*").
+		Joins("JOIN users ON users.id = webhooks.user_id").
+		Where("webhooks.user_id IN ?", userIds).
+		Where("users.banned = ?", false).
+		Where("' ' || webhooks.events || ' ' LIKE ? ESCAPE '\\'", "% "+escapeLike(eventType)+" %").
+		Find(&webhooks)
+	return webhooks, record.Error
+}
+
+// DeleteWebhook deletes webhook of user with its delivery log
+func (s *Store) DeleteWebhook(ctx context.Context, userId string, webhookId string) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		err := tx.Table("webhooks").Where("id = ? AND user_id = ?", webhookId, userId).Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return store.ErrNotFound
+		}
+		err = tx.Table("webhook_deliveries").Where("webhook_id = ?", webhookId).Delete(&models.WebhookDelivery{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Table("webhooks").Where("id = ?", webhookId).Delete(&models.Webhook{}).Error
+	})
+}
+
+func (s *Store) CreateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	return s.db.WithContext(ctx).Table("webhook_deliveries").Create(delivery).Error
+}
+
+func (s *Store) GetWebhookDelivery(ctx context.Context, webhookId string, deliveryId string) (models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	record := s.db.WithContext(ctx).Table("webhook_deliveries").First(&delivery, "id = ? AND webhook_id = ?", deliveryId, webhookId)
+	return delivery, notFound(record.Error)
+}
+
+// GetWebhookDeliveries returns page of delivery log of webhook, newest first
+func (s *Store) GetWebhookDeliveries(ctx context.Context, webhookId string, pagination models.Pagination) ([]models.WebhookDelivery, int64, error) {
+	var deliveries []models.WebhookDelivery
+	var total int64
+
+	query := s.db.WithContext(ctx).Table("webhook_deliveries").Where("webhook_id = ?", webhookId).Session(&gorm.Session{})
+
+	record := query.Count(&total)
+	if record.Error != nil {
+		return nil, 0, record.Error
+	}
+
+	record = query.
+		Order("created_at DESC").
+		Offset(pagination.Offset()).
+		Limit(pagination.Count).
+		Find(&deliveries)
+	return deliveries, total, record.Error
+}
+
+// GetDueWebhookDeliveries returns pending deliveries whose next attempt is due, oldest first
+func (s *Store) GetDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	record := s.db.WithContext(ctx).Table("webhook_deliveries").
+		Where("status = ? AND next_attempt_at <= ?", models.DeliveryPending, now).
+		Order("next_attempt_at").
+		Limit(limit).
+		Find(&deliveries)
+	return deliveries, record.Error
+}
+
+// ClaimWebhookDelivery moves next attempt of due delivery to leaseUntil, so other workers skip it while
+// it is being sent. Returns false if delivery was claimed by another worker
+func (s *Store) ClaimWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery, leaseUntil time.Time) (bool, error) {
+	record := s.db.WithContext(ctx).Table("webhook_deliveries").
+		Where("id = ? AND status = ? AND next_attempt_at = ?", delivery.ID, models.DeliveryPending, delivery.NextAttemptAt).
+		Update("next_attempt_at", leaseUntil)
+	return record.RowsAffected > 0, record.Error
+}
+
+// SaveWebhookDeliveryAttempt records result of delivery attempt
+func (s *Store) SaveWebhookDeliveryAttempt(ctx context.Context, delivery *models.WebhookDelivery) error {
+	return s.db.WithContext(ctx).Table("webhook_deliveries").Where("id = ?", delivery.ID).Updates(map[string]interface{}{
+		"status":          delivery.Status,
+		"attempts":        delivery.Attempts,
+		"next_attempt_at": delivery.NextAttemptAt,
+		"last_attempt_at": delivery.LastAttemptAt,
+		"response_status": delivery.ResponseStatus,
+		"last_error":      delivery.LastError,
+	}).Error
+}
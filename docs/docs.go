@@ -15,9 +15,14 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/auth/login": {
-            "post": {
-                "description": "Login user with given credentials",
+        "/admin/tournaments/{tournamentId}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Delete any tournament with its tiktoks",
                 "consumes": [
                     "application/json"
                 ],
@@ -25,39 +30,96 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
                 ],
-                "summary": "Login user",
+                "summary": "Delete tournament",
                 "parameters": [
                     {
-                        "description": "Data to login user",
-                        "name": "payload",
-                        "in": "body",
-                        "required": true,
+                        "type": "string",
+                        "description": "Tournament id",
+                        "name": "tournamentId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tournament deleted",
                         "schema": {
-                            "$ref": "#/definitions/models.AuthInput"
+                            "$ref": "#/definitions/controllers.MessageResponseType"
+                        }
+                    },
+                    "404": {
+                        "description": "Tournament not found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
                         }
                     }
+                }
+            }
+        },
+        "/admin/users": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get page of users, optionally filtered by part of name",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List users",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Part of username",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number, starting from 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Users per page",
+                        "name": "count",
+                        "in": "query"
+                    }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Login success",
+                        "description": "Page of users",
                         "schema": {
-                            "$ref": "#/definitions/models.UserAuthDetails"
+                            "$ref": "#/definitions/models.UsersPage"
                         }
                     },
-                    "400": {
-                        "description": "Error logging in",
+                    "500": {
+                        "description": "Failed to get users",
                         "schema": {
-                            "$ref": "#/definitions/controllers.MessageResponseType"
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
                         }
                     }
                 }
             }
         },
-        "/auth/register": {
+        "/admin/users/{userId}/ban": {
             "post": {
-                "description": "Register new user with given credentials",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Ban user, banned user can not log in or use existing tokens",
                 "consumes": [
                     "application/json"
                 ],
@@ -65,44 +127,54 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
                 ],
-                "summary": "Register user",
+                "summary": "Ban user",
                 "parameters": [
                     {
-                        "description": "Data to register user",
-                        "name": "payload",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.AuthInput"
-                        }
+                        "type": "string",
+                        "description": "User id",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Register success",
+                        "description": "User banned",
                         "schema": {
-                            "$ref": "#/definitions/models.UserAuthDetails"
+                            "$ref": "#/definitions/controllers.MessageResponseType"
                         }
                     },
                     "400": {
-                        "description": "Failed to register user",
+                        "description": "Invalid id",
                         "schema": {
-                            "$ref": "#/definitions/controllers.MessageResponseType"
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "403": {
+                        "description": "User can not ban themselves",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
                         }
                     }
                 }
             }
         },
-        "/auth/whoami": {
-            "get": {
+        "/admin/users/{userId}/reset-password": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Get current user id and name",
+                "description": "Require user to change password before next login and log user out of existing sessions,\nreset link is sent to verified email of user unless sending emails is disabled",
                 "consumes": [
                     "application/json"
                 ],
@@ -110,28 +182,54 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
+                ],
+                "summary": "Force password reset",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User id",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Authenticated user details",
                 "responses": {
                     "200": {
-                        "description": "User details",
+                        "description": "Password reset forced",
                         "schema": {
-                            "$ref": "#/definitions/models.UserAuthDetails"
+                            "$ref": "#/definitions/controllers.MessageResponseType"
                         }
                     },
                     "400": {
-                        "description": "Error getting user data",
+                        "description": "Invalid id",
                         "schema": {
-                            "$ref": "#/definitions/controllers.MessageResponseType"
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "502": {
+                        "description": "Reset link could not be sent",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
                         }
                     }
                 }
             }
         },
-        "/tournament": {
-            "get": {
-                "description": "Get all tournaments",
+        "/admin/users/{userId}/unban": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lift ban from user",
                 "consumes": [
                     "application/json"
                 ],
@@ -139,34 +237,48 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tournament"
+                    "admin"
+                ],
+                "summary": "Unban user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User id",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "All tournaments",
                 "responses": {
                     "200": {
-                        "description": "Contest bracket",
+                        "description": "User unbanned",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Tournament"
-                            }
+                            "$ref": "#/definitions/controllers.MessageResponseType"
                         }
                     },
                     "400": {
-                        "description": "Failed to return tournament contest",
+                        "description": "Invalid id",
                         "schema": {
-                            "$ref": "#/definitions/controllers.MessageResponseType"
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/auth/2fa/confirm": {
             "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Create new tournament for current user",
+                "description": "Enable two-factor authentication with first code from authenticator app",
                 "consumes": [
                     "application/json"
                 ],
@@ -174,39 +286,50 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tournament"
+                    "auth"
                 ],
-                "summary": "Create new tournament",
+                "summary": "Confirm two-factor authentication",
                 "parameters": [
                     {
-                        "description": "Data to create tournament",
+                        "description": "TOTP code",
                         "name": "payload",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.CreateTournament"
+                            "$ref": "#/definitions/models.TwoFactorCodeInput"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Tournament created",
+                        "description": "Two-factor enabled",
                         "schema": {
                             "$ref": "#/definitions/controllers.MessageResponseType"
                         }
                     },
                     "400": {
-                        "description": "Error during tournament creation",
+                        "description": "Invalid code",
                         "schema": {
-                            "$ref": "#/definitions/controllers.MessageResponseType"
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "409": {
+                        "description": "Two-factor is already enabled or not enrolled",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
                         }
                     }
                 }
             }
         },
-        "/tournament/{tournamentId}": {
-            "get": {
-                "description": "Get tournament details by its id",
+        "/auth/2fa/disable": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Disable two-factor authentication with TOTP or recovery code",
                 "consumes": [
                     "application/json"
                 ],
@@ -214,37 +337,50 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tournament"
+                    "auth"
                 ],
-                "summary": "Tournament details",
+                "summary": "Disable two-factor authentication",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Tournament id",
-                        "name": "tournamentId",
-                        "in": "path",
-                        "required": true
+                        "description": "TOTP or recovery code",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TwoFactorCodeInput"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Tournament",
+                        "description": "Two-factor disabled",
                         "schema": {
-                            "$ref": "#/definitions/models.Tournament"
+                            "$ref": "#/definitions/controllers.MessageResponseType"
                         }
                     },
                     "400": {
-                        "description": "Tournament not found",
+                        "description": "Invalid code",
                         "schema": {
-                            "$ref": "#/definitions/controllers.MessageResponseType"
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "409": {
+                        "description": "Two-factor is not enabled",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
                         }
                     }
                 }
             }
         },
-        "/tournament/{tournamentId}/contest": {
-            "get": {
-                "description": "Get tournament contest",
+        "/auth/2fa/enroll": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Generate TOTP secret and recovery codes, two-factor is enabled after confirmation with first code",
                 "consumes": [
                     "application/json"
                 ],
@@ -252,43 +388,33 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tournament"
-                ],
-                "summary": "Tournament contest",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Tournament id",
-                        "name": "tournamentId",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "name": "contestType",
-                        "in": "query",
-                        "required": true
-                    }
+                    "auth"
                 ],
+                "summary": "Enroll two-factor authentication",
                 "responses": {
                     "200": {
-                        "description": "Contest bracket",
+                        "description": "TOTP secret and recovery codes",
                         "schema": {
-                            "$ref": "#/definitions/models.Bracket"
+                            "$ref": "#/definitions/models.TwoFactorEnrollment"
                         }
                     },
-                    "400": {
-                        "description": "Failed to return tournament contest",
+                    "409": {
+                        "description": "Two-factor is already enabled",
                         "schema": {
-                            "$ref": "#/definitions/controllers.MessageResponseType"
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
                         }
                     }
                 }
             }
         },
-        "/tournament/{tournamentId}/tiktoks": {
-            "get": {
-                "description": "Get tournament tiktoks",
+        "/auth/email": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Set email of current user and send verification link to it, email is unverified until link is opened",
                 "consumes": [
                     "application/json"
                 ],
@@ -296,204 +422,3331 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tournament"
+                    "auth"
                 ],
-                "summary": "Tournament tiktoks",
+                "summary": "Set email",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Tournament id",
-                        "name": "tournamentId",
-                        "in": "path",
-                        "required": true
+                        "description": "New email",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.EmailInput"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Tournament tiktoks",
+                        "description": "Verification link sent",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Tiktok"
-                            }
+                            "$ref": "#/definitions/controllers.MessageResponseType"
                         }
                     },
                     "400": {
-                        "description": "Tournament not found",
+                        "description": "Invalid email",
                         "schema": {
-                            "$ref": "#/definitions/controllers.MessageResponseType"
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "409": {
+                        "description": "Email is used by another account",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "502": {
+                        "description": "Failed to send email",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "503": {
+                        "description": "Sending emails is disabled",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "controllers.MessageResponseType": {
-            "type": "object",
+        },
+        "/auth/email/resend": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Send new verification link to unverified email of current user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Resend email verification",
+                "responses": {
+                    "200": {
+                        "description": "Verification link sent",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.MessageResponseType"
+                        }
+                    },
+                    "409": {
+                        "description": "Email is not set or already verified",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "502": {
+                        "description": "Failed to send email",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "503": {
+                        "description": "Sending emails is disabled",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/email/verify": {
+            "post": {
+                "description": "Verify email with token from verification link",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Verify email",
+                "parameters": [
+                    {
+                        "description": "Token from verification link",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.EmailVerificationInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Email verified",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.MessageResponseType"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid or expired link",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login": {
+            "post": {
+                "description": "Login user with given credentials",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Login user",
+                "parameters": [
+                    {
+                        "description": "Data to login user",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AuthInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Login success",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserAuthDetails"
+                        }
+                    },
+                    "202": {
+                        "description": "Two-factor code required",
+                        "schema": {
+                            "$ref": "#/definitions/models.TwoFactorChallenge"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid payload",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "403": {
+                        "description": "User is banned or must change password",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed login attempts",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login/2fa": {
+            "post": {
+                "description": "Exchange challenge token from login and TOTP or recovery code for user token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Finish two-factor login",
+                "parameters": [
+                    {
+                        "description": "Challenge token and code",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TwoFactorLoginInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Login success",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserAuthDetails"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid payload",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid challenge token or code",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "403": {
+                        "description": "User is banned or must change password",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed login attempts",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/oidc/authorize": {
+            "get": {
+                "description": "Get provider authorization URL, flow token must be sent to callback together with code and state",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Start OpenID Connect login",
+                "responses": {
+                    "200": {
+                        "description": "Authorization URL",
+                        "schema": {
+                            "$ref": "#/definitions/models.OIDCAuthorization"
+                        }
+                    },
+                    "404": {
+                        "description": "OpenID Connect is not configured",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "502": {
+                        "description": "Provider is unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/oidc/callback": {
+            "post": {
+                "description": "Exchange authorization code for user token. New account is created for unknown identity.\nFor link flow identity is attached to account which started the flow",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Finish OpenID Connect login",
+                "parameters": [
+                    {
+                        "description": "Code, state and flow token",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.OIDCCallbackInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Login success",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserAuthDetails"
+                        }
+                    },
+                    "202": {
+                        "description": "Two-factor code required",
+                        "schema": {
+                            "$ref": "#/definitions/models.TwoFactorChallenge"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid payload",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid flow token or code",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "403": {
+                        "description": "User is banned or must change password",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "404": {
+                        "description": "OpenID Connect is not configured",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "409": {
+                        "description": "Identity is linked to another account",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/oidc/link": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get provider authorization URL to attach provider identity to current account",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Start linking OpenID Connect identity",
+                "responses": {
+                    "200": {
+                        "description": "Authorization URL",
+                        "schema": {
+                            "$ref": "#/definitions/models.OIDCAuthorization"
+                        }
+                    },
+                    "404": {
+                        "description": "OpenID Connect is not configured",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "502": {
+                        "description": "Provider is unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/password": {
+            "post": {
+                "description": "Change password with current credentials, also used when administrator forced password reset.\nExisting sessions and API tokens of user are revoked",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Change password",
+                "parameters": [
+                    {
+                        "description": "Current credentials and new password",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ChangePasswordInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Password changed",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.MessageResponseType"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid new password",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "403": {
+                        "description": "User is banned",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed login attempts",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/password/forgot": {
+            "post": {
+                "description": "Send password reset link to verified email. Response is the same whether account exists or not",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Request password reset",
+                "parameters": [
+                    {
+                        "description": "Account email",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ForgotPasswordInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Reset link sent if account exists",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.MessageResponseType"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid email",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many password reset requests",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "502": {
+                        "description": "Failed to send email",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "503": {
+                        "description": "Sending emails is disabled",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/password/reset": {
+            "post": {
+                "description": "Set new password with token from password reset link, link can be used only once.\nExisting sessions and API tokens of user are revoked",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Reset password",
+                "parameters": [
+                    {
+                        "description": "Token from reset link and new password",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ResetPasswordInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Password changed",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.MessageResponseType"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid or expired link or invalid password",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "403": {
+                        "description": "User is banned",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/register": {
+            "post": {
+                "description": "Register new user with given credentials",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Register user",
+                "parameters": [
+                    {
+                        "description": "Data to register user",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AuthInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Register success",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserAuthDetails"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid username or password",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "409": {
+                        "description": "User already exists",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/tokens": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get personal API tokens of current user, plaintext tokens are not returned",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Personal API tokens",
+                "responses": {
+                    "200": {
+                        "description": "API tokens",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.APIToken"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get tokens",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create personal API token with given scopes, plaintext token is shown only in this response",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Create personal API token",
+                "parameters": [
+                    {
+                        "description": "Token name, scopes and lifetime",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateAPIToken"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created token",
+                        "schema": {
+                            "$ref": "#/definitions/models.CreatedAPIToken"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid token name, scopes or lifetime",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/tokens/{tokenId}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Delete personal API token of current user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Revoke personal API token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token id",
+                        "name": "tokenId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Token revoked",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.MessageResponseType"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid id",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "404": {
+                        "description": "Token not found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/whoami": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get current user id and name",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Authenticated user details",
+                "responses": {
+                    "200": {
+                        "description": "User details",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserAuthDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or expired token",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/feed": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get new public tournaments and contest results of followed users, newest first.\nPass NextCursor of previous page as cursor to get older items",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Feed",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cursor from previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page",
+                        "name": "count",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of feed",
+                        "schema": {
+                            "$ref": "#/definitions/models.FeedPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid cursor",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/moderation/actions": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get page of recorded moderation actions, newest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "moderation"
+                ],
+                "summary": "Moderation log",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number, starting from 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Actions per page",
+                        "name": "count",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of actions",
+                        "schema": {
+                            "$ref": "#/definitions/models.ModerationActionsPage"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get actions",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/moderation/reports": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get page of reports with given status, oldest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "moderation"
+                ],
+                "summary": "Moderation queue",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Report status, open by default",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number, starting from 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Reports per page",
+                        "name": "count",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of reports",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReportsPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid status",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/moderation/reports/{reportId}/action": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Hide reported content, warn or ban its author and resolve report",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "moderation"
+                ],
+                "summary": "Act on report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Report id",
+                        "name": "reportId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Action (hide, warn or ban) and moderator note",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ModerationInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Action applied",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.MessageResponseType"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid id or action",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "403": {
+                        "description": "Author can be banned only by administrator",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "404": {
+                        "description": "Report not found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "409": {
+                        "description": "Report is already closed or author is unknown",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/moderation/reports/{reportId}/dismiss": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Close report as unfounded",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "moderation"
+                ],
+                "summary": "Dismiss report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Report id",
+                        "name": "reportId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Moderator note",
+                        "name": "payload",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ModerationInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Report dismissed",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.MessageResponseType"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid id",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "404": {
+                        "description": "Report not found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "409": {
+                        "description": "Report is already closed",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/moderation/reports/{reportId}/resolve": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Close report as handled without acting on content",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "moderation"
+                ],
+                "summary": "Resolve report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Report id",
+                        "name": "reportId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Moderator note",
+                        "name": "payload",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ModerationInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Report resolved",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.MessageResponseType"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid id",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "404": {
+                        "description": "Report not found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "409": {
+                        "description": "Report is already closed",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get page of notifications of current user, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Notifications",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Only unread notifications",
+                        "name": "unread",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number, starting from 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Notifications per page",
+                        "name": "count",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of notifications",
+                        "schema": {
+                            "$ref": "#/definitions/models.NotificationsPage"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get notifications",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/read-all": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Mark all notifications read",
+                "responses": {
+                    "200": {
+                        "description": "Notifications marked read",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.MessageResponseType"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to mark notifications read",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/stream": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Server-sent events stream: \"unread\" event with count of unread notifications on connect,\nthen \"notification\" event for every new notification. Browsers can pass token in query.\nStream is closed once user is banned or their sessions are revoked",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Live notifications",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT, for clients which can not set Authorization header",
+                        "name": "token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Event stream",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/unread-count": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get count of unread notifications of current user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Unread notifications count",
+                "responses": {
+                    "200": {
+                        "description": "Count of unread notifications",
+                        "schema": {
+                            "$ref": "#/definitions/models.UnreadNotifications"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to count notifications",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/{notificationId}/read": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Mark notification read",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Notification id",
+                        "name": "notificationId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Notification marked read",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.MessageResponseType"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid id",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "404": {
+                        "description": "Notification not found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Report tournament or tiktok to moderators",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "moderation"
+                ],
+                "summary": "Report content",
+                "parameters": [
+                    {
+                        "description": "Reported content and reason",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateReport"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Report created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Report"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid report",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "404": {
+                        "description": "Reported content not found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "409": {
+                        "description": "Content is already reported by current user",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/tournament": {
+            "get": {
+                "description": "Get all tournaments",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tournament"
+                ],
+                "summary": "All tournaments",
+                "responses": {
+                    "200": {
+                        "description": "Contest bracket",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Tournament"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get tournaments",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create new tournament for current user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tournament"
+                ],
+                "summary": "Create new tournament",
+                "parameters": [
+                    {
+                        "description": "Data to create tournament",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateTournament"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tournament created",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.MessageResponseType"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid tournament",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "409": {
+                        "description": "Tournament already exists",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/tournament/import": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create tournament from JSON or CSV file. JSON has format of tournament creation payload or export.\nCSV has header line and url column, other columns are ignored. Every invalid row is reported",
+                "consumes": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tournament"
+                ],
+                "summary": "Import tournament",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "File format: json (default) or csv",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tournament name, required for csv",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Tournament size for csv, count of rows by default",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tournament imported",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.MessageResponseType"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid import file, invalid rows are listed in errors",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "409": {
+                        "description": "Tournament already exists",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/tournament/{tournamentId}": {
+            "get": {
+                "description": "Get tournament details by its id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tournament"
+                ],
+                "summary": "Tournament details",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tournament id",
+                        "name": "tournamentId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tournament",
+                        "schema": {
+                            "$ref": "#/definitions/models.Tournament"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid tournament id",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "404": {
+                        "description": "Tournament not found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/tournament/{tournamentId}/contest": {
+            "get": {
+                "description": "Get tournament contest",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tournament"
+                ],
+                "summary": "Tournament contest",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tournament id",
+                        "name": "tournamentId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "name": "contestType",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Contest bracket",
+                        "schema": {
+                            "$ref": "#/definitions/models.Bracket"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid tournament id or contest type",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "404": {
+                        "description": "Tournament not found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "409": {
+                        "description": "Tournament has not enough tiktoks",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Record finished contest and update statistics of played tiktoks",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tournament"
+                ],
+                "summary": "Submit contest result",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tournament id",
+                        "name": "tournamentId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Contest winner and points of played tiktoks",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ContestResult"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Contest recorded",
+                        "schema": {
+                            "$ref": "#/definitions/models.Contest"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid contest result",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "404": {
+                        "description": "Tournament not found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/tournament/{tournamentId}/export": {
+            "get": {
+                "description": "Download tournament with its tiktoks and their statistics as JSON or CSV",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "tournament"
+                ],
+                "summary": "Export tournament",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tournament id",
+                        "name": "tournamentId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "File format: json (default) or csv",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tournament export",
+                        "schema": {
+                            "$ref": "#/definitions/models.TournamentExport"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid tournament id or format",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "404": {
+                        "description": "Tournament not found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/tournament/{tournamentId}/tiktoks": {
+            "get": {
+                "description": "Get tournament tiktoks",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tournament"
+                ],
+                "summary": "Tournament tiktoks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tournament id",
+                        "name": "tournamentId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tournament tiktoks",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Tiktok"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid tournament id",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "404": {
+                        "description": "Tournament not found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/tournament/{tournamentId}/upvote": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Upvote tournament of another user, each user can upvote tournament once",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tournament"
+                ],
+                "summary": "Upvote tournament",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tournament id",
+                        "name": "tournamentId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Count of tournament upvotes",
+                        "schema": {
+                            "$ref": "#/definitions/models.TournamentUpvotes"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid tournament id",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "403": {
+                        "description": "Tournament belongs to current user",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "404": {
+                        "description": "Tournament not found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Remove upvote given to tournament by current user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tournament"
+                ],
+                "summary": "Remove tournament upvote",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tournament id",
+                        "name": "tournamentId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Count of tournament upvotes",
+                        "schema": {
+                            "$ref": "#/definitions/models.TournamentUpvotes"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid tournament id",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Replace display name, bio and avatar URL of current user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Edit profile",
+                "parameters": [
+                    {
+                        "description": "Profile fields",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.EditProfile"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Profile updated",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.MessageResponseType"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid profile fields",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{name}": {
+            "get": {
+                "description": "Get public profile of user with stats and page of public tournaments, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "User profile",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page of tournaments, starting from 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Tournaments per page",
+                        "name": "count",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User profile",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserProfile"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{name}/follow": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Follow user to see their new tournaments and contest results in feed",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Follow user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User followed",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.MessageResponseType"
+                        }
+                    },
+                    "403": {
+                        "description": "User can not follow themselves",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Stop following user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Unfollow user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User unfollowed",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.MessageResponseType"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get webhooks of current user, secrets are not returned",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Webhooks",
+                "responses": {
+                    "200": {
+                        "description": "Webhooks",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Webhook"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get webhooks",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Register URL which receives POST request for every selected event: tournament.created, contest.finished.\nOnly events caused by current user or happening on tournaments of current user are delivered.\nRequests are signed with HMAC-SHA256 of \"timestamp.body\" in X-Arena-Signature header,\nsecret is shown only in this response",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Create webhook",
+                "parameters": [
+                    {
+                        "description": "URL and events",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateWebhook"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created webhook",
+                        "schema": {
+                            "$ref": "#/definitions/models.CreatedWebhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid URL or events",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "409": {
+                        "description": "Webhook limit reached",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{webhookId}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Delete webhook of current user with its delivery log",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Delete webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook id",
+                        "name": "webhookId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Webhook deleted",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.MessageResponseType"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid id",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook not found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{webhookId}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get page of deliveries of webhook, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Webhook delivery log",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook id",
+                        "name": "webhookId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number, starting from 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Deliveries per page",
+                        "name": "count",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of deliveries",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookDeliveriesPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid id",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook not found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{webhookId}/deliveries/{deliveryId}/replay": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Queue new delivery with payload of earlier delivery, e.g. after receiver was fixed",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Replay webhook delivery",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook id",
+                        "name": "webhookId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Delivery id",
+                        "name": "deliveryId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Queued delivery",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookDelivery"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid id",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook or delivery not found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProblemResponseType"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "controllers.MessageResponseType": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.ProblemResponseType": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "tournament_not_found"
+                },
+                "detail": {
+                    "type": "string",
+                    "example": "Could not get tournament with id 7c4a9b6e-6f0e-4c1a-9d53-0f4b2c1d8e7a"
+                },
+                "errors": {},
+                "instance": {
+                    "type": "string",
+                    "example": "/api/tournament/7c4a9b6e-6f0e-4c1a-9d53-0f4b2c1d8e7a"
+                },
+                "requestId": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 404
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Not Found"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "about:blank"
+                }
+            }
+        },
+        "models.APIToken": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "lastUsedAt": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "string"
+                },
+                "userID": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AuthInput": {
+            "type": "object",
+            "required": [
+                "name",
+                "password"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Bracket": {
+            "type": "object",
+            "properties": {
+                "countMatches": {
+                    "type": "integer"
+                },
+                "rounds": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Round"
+                    }
+                }
+            }
+        },
+        "models.ChangePasswordInput": {
+            "type": "object",
+            "required": [
+                "name",
+                "newPassword",
+                "password"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "newPassword": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Contest": {
+            "type": "object",
+            "properties": {
+                "contestType": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "tournamentID": {
+                    "type": "string"
+                },
+                "userID": {
+                    "type": "string"
+                },
+                "winnerTiktokID": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ContestResult": {
+            "type": "object",
+            "required": [
+                "contestType",
+                "tiktoks",
+                "winnerURL"
+            ],
+            "properties": {
+                "contestType": {
+                    "type": "string"
+                },
+                "tiktoks": {
+                    "type": "array",
+                    "minItems": 2,
+                    "items": {
+                        "$ref": "#/definitions/models.ContestTiktokResult"
+                    }
+                },
+                "winnerURL": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ContestTiktokResult": {
+            "type": "object",
+            "required": [
+                "url"
+            ],
+            "properties": {
+                "points": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateAPIToken": {
+            "type": "object",
+            "required": [
+                "name",
+                "scopes"
+            ],
+            "properties": {
+                "expiresInDays": {
+                    "description": "ExpiresInDays is token lifetime, zero means token never expires",
+                    "type": "integer",
+                    "maximum": 365,
+                    "minimum": 0
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "scopes": {
+                    "description": "Scopes granted to token: read, tournaments:create, results:submit",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.CreateReport": {
+            "type": "object",
+            "required": [
+                "reason",
+                "targetID",
+                "targetType"
+            ],
+            "properties": {
+                "details": {
+                    "type": "string",
+                    "maxLength": 1000
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "targetID": {
+                    "type": "string"
+                },
+                "targetType": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateTiktok": {
+            "type": "object",
+            "required": [
+                "url"
+            ],
+            "properties": {
+                "url": {
+                    "type": "string",
+                    "maxLength": 2048
+                }
+            }
+        },
+        "models.CreateTournament": {
+            "type": "object",
+            "required": [
+                "name",
+                "tiktoks"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer",
+                    "maximum": 64,
+                    "minimum": 4
+                },
+                "tiktoks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CreateTiktok"
+                    }
+                }
+            }
+        },
+        "models.CreateWebhook": {
+            "type": "object",
+            "required": [
+                "events",
+                "url"
+            ],
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "url": {
+                    "type": "string",
+                    "maxLength": 2048
+                }
+            }
+        },
+        "models.CreatedAPIToken": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "lastUsedAt": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "userID": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreatedWebhook": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "events": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                },
+                "userID": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.EditProfile": {
+            "type": "object",
+            "properties": {
+                "avatarURL": {
+                    "type": "string",
+                    "maxLength": 2048
+                },
+                "bio": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "displayName": {
+                    "type": "string",
+                    "maxLength": 64
+                }
+            }
+        },
+        "models.EmailInput": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.EmailVerificationInput": {
+            "type": "object",
+            "required": [
+                "token"
+            ],
+            "properties": {
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.FeedContest": {
+            "type": "object",
+            "properties": {
+                "contestType": {
+                    "type": "string"
+                },
+                "winnerURL": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.FeedItem": {
+            "type": "object",
+            "properties": {
+                "contest": {
+                    "$ref": "#/definitions/models.FeedContest"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "tournament": {
+                    "$ref": "#/definitions/models.FeedTournament"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/models.FeedUser"
+                }
+            }
+        },
+        "models.FeedPage": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FeedItem"
+                    }
+                },
+                "nextCursor": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.FeedTournament": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.FeedUser": {
+            "type": "object",
+            "properties": {
+                "displayName": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ForgotPasswordInput": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Match": {
+            "type": "object",
+            "properties": {
+                "firstOption": {},
+                "matchID": {
+                    "type": "string"
+                },
+                "secondOption": {}
+            }
+        },
+        "models.ModerationAction": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "authorID": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "moderatorID": {
+                    "type": "string"
+                },
+                "note": {
+                    "type": "string"
+                },
+                "reportID": {
+                    "type": "string"
+                },
+                "targetID": {
+                    "type": "string"
+                },
+                "targetType": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ModerationActionsPage": {
+            "type": "object",
+            "properties": {
+                "actions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ModerationAction"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ModerationInput": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "note": {
+                    "type": "string",
+                    "maxLength": 1000
+                }
+            }
+        },
+        "models.Notification": {
+            "type": "object",
             "properties": {
+                "actorID": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
                 "message": {
                     "type": "string"
+                },
+                "readAt": {
+                    "type": "string"
+                },
+                "targetID": {
+                    "type": "string"
+                },
+                "targetType": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "userID": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.NotificationsPage": {
+            "type": "object",
+            "properties": {
+                "notifications": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Notification"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.OIDCAuthorization": {
+            "type": "object",
+            "properties": {
+                "authorizationURL": {
+                    "type": "string"
+                },
+                "flowToken": {
+                    "description": "FlowToken must be sent back with authorization code, it keeps PKCE verifier and nonce of this flow",
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.OIDCCallbackInput": {
+            "type": "object",
+            "required": [
+                "code",
+                "flowToken",
+                "state"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "flowToken": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Report": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "details": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "reporterID": {
+                    "type": "string"
+                },
+                "resolvedAt": {
+                    "type": "string"
+                },
+                "resolvedByID": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "targetID": {
+                    "type": "string"
+                },
+                "targetType": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ReportsPage": {
+            "type": "object",
+            "properties": {
+                "reports": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Report"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ResetPasswordInput": {
+            "type": "object",
+            "required": [
+                "newPassword",
+                "token"
+            ],
+            "properties": {
+                "newPassword": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Round": {
+            "type": "object",
+            "properties": {
+                "matches": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Match"
+                    }
+                },
+                "round": {
+                    "type": "integer"
                 }
             }
         },
-        "models.AuthInput": {
+        "models.Tiktok": {
             "type": "object",
-            "required": [
-                "name",
-                "password"
-            ],
             "properties": {
-                "name": {
+                "avgPoints": {
+                    "type": "number"
+                },
+                "hidden": {
+                    "type": "boolean"
+                },
+                "id": {
                     "type": "string"
                 },
-                "password": {
+                "timesPlayed": {
+                    "type": "integer"
+                },
+                "tournament": {
+                    "$ref": "#/definitions/models.Tournament"
+                },
+                "tournamentID": {
                     "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                },
+                "wins": {
+                    "type": "integer"
                 }
             }
         },
-        "models.Bracket": {
+        "models.TiktokExport": {
             "type": "object",
             "properties": {
-                "countMatches": {
+                "avgPoints": {
+                    "type": "number"
+                },
+                "timesPlayed": {
                     "type": "integer"
                 },
-                "rounds": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.Round"
-                    }
+                "url": {
+                    "type": "string"
+                },
+                "wins": {
+                    "type": "integer"
                 }
             }
         },
-        "models.CreateTiktok": {
+        "models.Tournament": {
             "type": "object",
-            "required": [
-                "url"
-            ],
             "properties": {
-                "url": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "hidden": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "user": {
+                    "$ref": "#/definitions/models.User"
+                },
+                "userID": {
                     "type": "string"
                 }
             }
         },
-        "models.CreateTournament": {
+        "models.TournamentExport": {
             "type": "object",
-            "required": [
-                "name",
-                "tiktoks"
-            ],
             "properties": {
                 "name": {
                     "type": "string"
                 },
                 "size": {
-                    "type": "integer",
-                    "maximum": 64,
-                    "minimum": 4
+                    "type": "integer"
                 },
                 "tiktoks": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.CreateTiktok"
+                        "$ref": "#/definitions/models.TiktokExport"
                     }
                 }
             }
         },
-        "models.Match": {
+        "models.TournamentUpvotes": {
             "type": "object",
             "properties": {
-                "firstOption": {},
-                "matchID": {
+                "upvotes": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.TwoFactorChallenge": {
+            "type": "object",
+            "properties": {
+                "challengeToken": {
                     "type": "string"
                 },
-                "secondOption": {}
+                "expiresIn": {
+                    "type": "integer"
+                }
             }
         },
-        "models.Round": {
+        "models.TwoFactorCodeInput": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.TwoFactorEnrollment": {
             "type": "object",
             "properties": {
-                "matches": {
+                "recoveryCodes": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.Match"
+                        "type": "string"
                     }
                 },
-                "round": {
+                "secret": {
+                    "type": "string"
+                },
+                "uri": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.TwoFactorLoginInput": {
+            "type": "object",
+            "required": [
+                "challengeToken",
+                "code"
+            ],
+            "properties": {
+                "challengeToken": {
+                    "type": "string"
+                },
+                "code": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.UnreadNotifications": {
+            "type": "object",
+            "properties": {
+                "unread": {
                     "type": "integer"
                 }
             }
         },
-        "models.Tiktok": {
+        "models.User": {
             "type": "object",
             "properties": {
-                "avgPoints": {
-                    "type": "number"
+                "avatarURL": {
+                    "type": "string"
+                },
+                "banned": {
+                    "type": "boolean"
+                },
+                "bio": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "displayName": {
+                    "type": "string"
+                },
+                "emailVerified": {
+                    "type": "boolean"
                 },
                 "id": {
                     "type": "string"
                 },
-                "timesPlayed": {
-                    "type": "integer"
+                "mustResetPassword": {
+                    "type": "boolean"
                 },
-                "tournament": {
-                    "$ref": "#/definitions/models.Tournament"
+                "name": {
+                    "type": "string"
                 },
-                "tournamentID": {
+                "password": {
                     "type": "string"
                 },
-                "url": {
+                "role": {
                     "type": "string"
                 },
-                "wins": {
+                "totpenabled": {
+                    "type": "boolean"
+                },
+                "totplastStep": {
                     "type": "integer"
                 }
             }
         },
-        "models.Tournament": {
+        "models.UserAuthDetails": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.UserProfile": {
             "type": "object",
             "properties": {
+                "avatarURL": {
+                    "type": "string"
+                },
+                "bio": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "displayName": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "string"
                 },
                 "name": {
                     "type": "string"
                 },
-                "size": {
+                "stats": {
+                    "$ref": "#/definitions/models.UserStats"
+                },
+                "tournaments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Tournament"
+                    }
+                }
+            }
+        },
+        "models.UserStats": {
+            "type": "object",
+            "properties": {
+                "contestsPlayed": {
                     "type": "integer"
                 },
-                "user": {
-                    "$ref": "#/definitions/models.User"
+                "followers": {
+                    "type": "integer"
                 },
-                "userID": {
-                    "type": "string"
+                "following": {
+                    "type": "integer"
+                },
+                "tournamentsCreated": {
+                    "type": "integer"
+                },
+                "upvotesReceived": {
+                    "type": "integer"
                 }
             }
         },
-        "models.User": {
+        "models.UserSummary": {
             "type": "object",
             "properties": {
+                "banned": {
+                    "type": "boolean"
+                },
                 "id": {
                     "type": "string"
                 },
+                "mustResetPassword": {
+                    "type": "boolean"
+                },
                 "name": {
                     "type": "string"
                 },
-                "password": {
+                "role": {
                     "type": "string"
+                },
+                "totpenabled": {
+                    "type": "boolean"
                 }
             }
         },
-        "models.UserAuthDetails": {
+        "models.UsersPage": {
+            "type": "object",
+            "properties": {
+                "total": {
+                    "type": "integer"
+                },
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.UserSummary"
+                    }
+                }
+            }
+        },
+        "models.Webhook": {
             "type": "object",
             "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "events": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "string"
                 },
-                "token": {
+                "url": {
                     "type": "string"
                 },
-                "username": {
+                "userID": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.WebhookDeliveriesPage": {
+            "type": "object",
+            "properties": {
+                "deliveries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.WebhookDelivery"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "eventType": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "lastAttemptAt": {
+                    "type": "string"
+                },
+                "lastError": {
+                    "type": "string"
+                },
+                "nextAttemptAt": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "string"
+                },
+                "replayOfID": {
+                    "type": "string"
+                },
+                "responseStatus": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "webhookID": {
                     "type": "string"
                 }
             }
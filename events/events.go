@@ -0,0 +1,83 @@
+// Package events delivers domain events from controllers to subsystems interested in them,
+// e.g. notifications, so producers do not depend on consumers
+package events
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"sync"
+	"time"
+)
+
+const (
+	TournamentCreated         = "tournament.created"
+	ContestFinished           = "contest.finished"
+	TournamentUpvoteMilestone = "tournament.upvote_milestone"
+	ModerationActionTaken     = "moderation.action_taken"
+	UserFollowed              = "user.followed"
+)
+
+// Event describes something that happened to content of recipient, fields not relevant for event type are empty.
+// Public events, e.g. new tournament, have no recipient. TargetOwnerID is owner of target, e.g. author of
+// tournament contest was played on. Details hold data specific to event type
+type Event struct {
+	Type          string
+	RecipientID   *uuid.UUID
+	ActorID       *uuid.UUID             `json:",omitempty"`
+	ActorName     string                 `json:",omitempty"`
+	TargetType    string                 `json:",omitempty"`
+	TargetID      *uuid.UUID             `json:",omitempty"`
+	TargetName    string                 `json:",omitempty"`
+	TargetOwnerID *uuid.UUID             `json:",omitempty"`
+	Count         int64                  `json:",omitempty"`
+	Action        string                 `json:",omitempty"`
+	Note          string                 `json:",omitempty"`
+	Details       map[string]interface{} `json:",omitempty"`
+	CreatedAt     time.Time
+}
+
+type Publisher interface {
+	Publish(ctx context.Context, event Event)
+}
+
+type Handler func(ctx context.Context, event Event)
+
+// Bus is in-process Publisher which calls subscribed handlers synchronously in order of subscription
+type Bus struct {
+	mu       sync.RWMutex
+	handlers []Handler
+}
+
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+func (b *Bus) Subscribe(handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, handler)
+}
+
+func (b *Bus) Publish(ctx context.Context, event Event) {
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now().UTC()
+	}
+	b.mu.RLock()
+	handlers := b.handlers
+	b.mu.RUnlock()
+	for _, handler := range handlers {
+		handler(ctx, event)
+	}
+}
+
+// Default is bus used by controllers to publish events
+var Default = NewBus()
+
+// IsUpvoteMilestone reports whether tournament reaching given count of upvotes is worth notifying owner
+func IsUpvoteMilestone(upvotes int64) bool {
+	switch upvotes {
+	case 1, 10, 25, 50, 100, 250, 500:
+		return true
+	}
+	return upvotes >= 1000 && upvotes%1000 == 0
+}
@@ -0,0 +1,84 @@
+// Package health tracks whether instance is ready to serve traffic. Readiness is reported by /readyz,
+// so load balancer stops sending requests to instance which lost database or is shutting down
+package health
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"tiktok-arena/models"
+)
+
+// ErrShuttingDown is reported once shutdown starts, so traffic moves to other instances while requests drain
+var ErrShuttingDown = errors.New("instance is shutting down")
+
+// Check returns error when dependency of instance is not usable
+type Check func(ctx context.Context) error
+
+type Checker struct {
+	mu           sync.RWMutex
+	checks       map[string]Check
+	shuttingDown bool
+}
+
+func NewChecker() *Checker {
+	return &Checker{checks: make(map[string]Check)}
+}
+
+// Add registers check under name, check with the same name is replaced
+func (c *Checker) Add(name string, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks[name] = check
+}
+
+// ShutDown marks instance not ready regardless of checks
+func (c *Checker) ShutDown() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.shuttingDown = true
+}
+
+// Check runs all checks concurrently and returns their results ordered by name,
+// ready is false if any check failed or instance is shutting down
+func (c *Checker) Check(ctx context.Context) (results []models.ReadinessCheck, ready bool) {
+	c.mu.RLock()
+	checks := make(map[string]Check, len(c.checks))
+	for name, check := range c.checks {
+		checks[name] = check
+	}
+	shuttingDown := c.shuttingDown
+	c.mu.RUnlock()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	ready = !shuttingDown
+	if shuttingDown {
+		results = append(results, models.ReadinessCheck{Name: "shutdown", Error: ErrShuttingDown.Error()})
+	}
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check Check) {
+			defer wg.Done()
+			result := models.ReadinessCheck{Name: name}
+			err := check(ctx)
+			if err != nil {
+				result.Error = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			results = append(results, result)
+			if err != nil {
+				ready = false
+			}
+		}(name, check)
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+	return results, ready
+}
@@ -0,0 +1,365 @@
+// Package jwtkeys holds keys used to sign and verify user JWTs.
+// Tokens are signed with single active key, while verification accepts every configured key,
+// so signing key can be rotated without invalidating tokens issued with previous one
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v4"
+	"os"
+	"strings"
+	"tiktok-arena/configuration"
+	"time"
+)
+
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// AccessAudience is aud claim of access tokens. Tokens for other purposes, e.g. two-factor challenge
+// or password reset, are signed with the same keys, so each purpose has its own audience
+// and services verifying access tokens with JWKS do not accept them
+const AccessAudience = "tiktok-arena"
+
+// Audience returns aud claim of tokens issued for given purpose
+func Audience(purpose string) string {
+	return AccessAudience + ":" + purpose
+}
+
+type verificationKey struct {
+	method jwt.SigningMethod
+	key    crypto.PublicKey
+}
+
+type KeySet struct {
+	method     jwt.SigningMethod
+	signingKey interface{}
+	signingKid string
+	// secret verifies HS256 tokens, empty if HS256 tokens are not accepted
+	secret []byte
+	// secretUntil is deadline of legacy HS256 tokens after switch to asymmetric algorithm, zero for HS256 algorithm
+	secretUntil time.Time
+	// audienceUntil is deadline of access tokens issued without aud claim, zero if they are not accepted
+	audienceUntil time.Time
+	// publicKeys verify asymmetric tokens by kid header
+	publicKeys map[string]verificationKey
+	// kids keeps order of public keys for JWKS
+	kids []string
+}
+
+var Keys *KeySet
+
+// Setup loads keys from configuration
+func Setup(config *configuration.EnvConfigModel) error {
+	keySet, err := NewKeySet(config)
+	if err != nil {
+		return err
+	}
+	Keys = keySet
+	return nil
+}
+
+// NewKeySet creates key set from configuration. With HS256 algorithm tokens are signed with JWT secret.
+// With RS256 or EdDSA algorithm tokens are signed with private key from PEM file. HS256 tokens signed
+// before the switch are accepted only if JWT_LEGACY_HS256_UNTIL is set and only until that time
+func NewKeySet(config *configuration.EnvConfigModel) (*KeySet, error) {
+	keySet := &KeySet{publicKeys: make(map[string]verificationKey)}
+
+	switch config.JwtAlgorithm {
+	case "", AlgorithmHS256:
+		if config.JwtSecret == "" {
+			return nil, errors.New("JWT_SECRET_KEY is required for HS256 algorithm")
+		}
+		keySet.secret = []byte(config.JwtSecret)
+		keySet.method = jwt.SigningMethodHS256
+		keySet.signingKey = keySet.secret
+	case AlgorithmRS256, AlgorithmEdDSA:
+		err := keySet.loadSigningKey(config.JwtAlgorithm, config.JwtPrivateKeyFile, config.JwtKeyID)
+		if err != nil {
+			return nil, err
+		}
+		err = keySet.acceptLegacySecret(config.JwtSecret, config.JwtLegacyHS256Until)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown JWT algorithm %s", config.JwtAlgorithm)
+	}
+
+	err := keySet.loadPublicKeys(config.JwtPublicKeyFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.JwtLegacyAudienceUntil != "" {
+		keySet.audienceUntil, err = time.Parse(time.RFC3339, config.JwtLegacyAudienceUntil)
+		if err != nil {
+			return nil, fmt.Errorf("JWT_LEGACY_AUDIENCE_UNTIL must be RFC 3339 time: %w", err)
+		}
+	}
+	return keySet, nil
+}
+
+// acceptLegacySecret keeps verifying HS256 tokens until given RFC 3339 time, empty until disables them
+func (k *KeySet) acceptLegacySecret(secret string, until string) error {
+	if until == "" {
+		return nil
+	}
+	deadline, err := time.Parse(time.RFC3339, until)
+	if err != nil {
+		return fmt.Errorf("JWT_LEGACY_HS256_UNTIL must be RFC 3339 time: %w", err)
+	}
+	if secret == "" {
+		return errors.New("JWT_SECRET_KEY is required to accept legacy HS256 tokens")
+	}
+	k.secret = []byte(secret)
+	k.secretUntil = deadline
+	return nil
+}
+
+func (k *KeySet) loadSigningKey(algorithm string, path string, kid string) error {
+	if path == "" {
+		return fmt.Errorf("JWT_PRIVATE_KEY_FILE is required for %s algorithm", algorithm)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	privateKey, err := parsePrivateKey(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse private key %s: %w", path, err)
+	}
+
+	var publicKey crypto.PublicKey
+	switch key := privateKey.(type) {
+	case *rsa.PrivateKey:
+		if algorithm != AlgorithmRS256 {
+			return fmt.Errorf("private key %s is RSA key, but algorithm is %s", path, algorithm)
+		}
+		k.method = jwt.SigningMethodRS256
+		publicKey = key.Public()
+	case ed25519.PrivateKey:
+		if algorithm != AlgorithmEdDSA {
+			return fmt.Errorf("private key %s is Ed25519 key, but algorithm is %s", path, algorithm)
+		}
+		k.method = jwt.SigningMethodEdDSA
+		publicKey = key.Public()
+	default:
+		return fmt.Errorf("private key %s has unsupported type %T", path, privateKey)
+	}
+
+	if kid == "" {
+		kid, err = thumbprint(publicKey)
+		if err != nil {
+			return err
+		}
+	}
+	k.signingKey = privateKey
+	k.signingKid = kid
+	return k.addPublicKey(kid, publicKey)
+}
+
+// loadPublicKeys loads additional verification keys from comma separated list of kid=path pairs
+func (k *KeySet) loadPublicKeys(list string) error {
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		kid, path, found := strings.Cut(entry, "=")
+		if !found {
+			return fmt.Errorf("public key %s must be in kid=path format", entry)
+		}
+		data, err := os.ReadFile(strings.TrimSpace(path))
+		if err != nil {
+			return err
+		}
+		publicKey, err := parsePublicKey(data)
+		if err != nil {
+			return fmt.Errorf("failed to parse public key %s: %w", path, err)
+		}
+		err = k.addPublicKey(strings.TrimSpace(kid), publicKey)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (k *KeySet) addPublicKey(kid string, publicKey crypto.PublicKey) error {
+	if _, exists := k.publicKeys[kid]; exists {
+		return fmt.Errorf("duplicate JWT key id %s", kid)
+	}
+	var method jwt.SigningMethod
+	switch publicKey.(type) {
+	case *rsa.PublicKey:
+		method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		method = jwt.SigningMethodEdDSA
+	default:
+		return fmt.Errorf("public key %s has unsupported type %T", kid, publicKey)
+	}
+	k.publicKeys[kid] = verificationKey{method: method, key: publicKey}
+	k.kids = append(k.kids, kid)
+	return nil
+}
+
+// Sign signs claims with active signing key, kid header is set for asymmetric keys
+func (k *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.method, claims)
+	if k.signingKid != "" {
+		token.Header["kid"] = k.signingKid
+	}
+	return token.SignedString(k.signingKey)
+}
+
+// Parse parses token and verifies its signature with one of configured keys
+func (k *KeySet) Parse(tokenString string) (*jwt.Token, error) {
+	return jwt.Parse(tokenString, k.Keyfunc)
+}
+
+// ParseFor parses token like Parse and checks that it was issued for given audience
+func (k *KeySet) ParseFor(tokenString string, audience string) (*jwt.Token, error) {
+	token, err := k.Parse(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !claims.VerifyAudience(audience, true) {
+		return nil, fmt.Errorf("token is not issued for %s: %w", audience, jwt.ErrTokenInvalidAudience)
+	}
+	return token, nil
+}
+
+// IsAccessToken checks that claims were issued for AccessAudience. Access tokens issued before audiences
+// were introduced have neither aud nor typ claim, they are accepted until JWT_LEGACY_AUDIENCE_UNTIL
+func (k *KeySet) IsAccessToken(claims jwt.MapClaims) bool {
+	if claims.VerifyAudience(AccessAudience, true) {
+		return true
+	}
+	_, hasAudience := claims["aud"]
+	_, hasType := claims["typ"]
+	return !hasAudience && !hasType && time.Now().Before(k.audienceUntil)
+}
+
+// Keyfunc chooses verification key by token algorithm and kid header
+func (k *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+		if k.secret == nil || token.Method != jwt.SigningMethodHS256 {
+			return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+		}
+		if !k.secretUntil.IsZero() && time.Now().After(k.secretUntil) {
+			return nil, fmt.Errorf("legacy HS256 tokens are accepted only until %s", k.secretUntil.Format(time.RFC3339))
+		}
+		return k.secret, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	publicKey, ok := k.publicKeys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %s", kid)
+	}
+	if publicKey.method.Alg() != token.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %v for key %s", token.Header["alg"], kid)
+	}
+	return publicKey.key, nil
+}
+
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns public verification keys, HS256 secret is never published
+func (k *KeySet) JWKS() JWKSet {
+	set := JWKSet{Keys: make([]JWK, 0, len(k.kids))}
+	for _, kid := range k.kids {
+		publicKey := k.publicKeys[kid]
+		jwk := JWK{Kid: kid, Use: "sig", Alg: publicKey.method.Alg()}
+		switch key := publicKey.key.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(key.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(bigEndian(key.E))
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(key)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+func parsePrivateKey(data []byte) (crypto.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	return nil, fmt.Errorf("unsupported PEM block type %s", block.Type)
+}
+
+func parsePublicKey(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	switch block.Type {
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	case "CERTIFICATE":
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return certificate.PublicKey, nil
+	}
+	return nil, fmt.Errorf("unsupported PEM block type %s", block.Type)
+}
+
+// thumbprint derives key id from public key, so restarting with the same key keeps kid stable
+func thumbprint(publicKey crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(der)
+	return base64.RawURLEncoding.EncodeToString(sum[:12]), nil
+}
+
+func bigEndian(value int) []byte {
+	var bytes []byte
+	for value > 0 {
+		bytes = append([]byte{byte(value & 0xff)}, bytes...)
+		value >>= 8
+	}
+	return bytes
+}
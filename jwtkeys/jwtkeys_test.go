@@ -0,0 +1,269 @@
+package jwtkeys_test
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"github.com/golang-jwt/jwt/v4"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"tiktok-arena/configuration"
+	"tiktok-arena/jwtkeys"
+	"time"
+)
+
+const testSecret = "test-secret-which-is-long-enough-for-hs256"
+
+// writeKeyPair writes PKCS #8 private key and PKIX public key to PEM files in temporary directory
+func writeKeyPair(t *testing.T, name string, privateKey crypto.Signer) (string, string) {
+	t.Helper()
+	privateDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(privateKey.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	privatePath := filepath.Join(dir, name+".pem")
+	publicPath := filepath.Join(dir, name+".pub.pem")
+	err = os.WriteFile(privatePath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(publicPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return privatePath, publicPath
+}
+
+func newRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func newEd25519Key(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func newKeySet(t *testing.T, config configuration.EnvConfigModel) *jwtkeys.KeySet {
+	t.Helper()
+	keySet, err := jwtkeys.NewKeySet(&config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keySet
+}
+
+func sign(t *testing.T, keySet *jwtkeys.KeySet, claims jwt.MapClaims) string {
+	t.Helper()
+	if claims == nil {
+		claims = jwt.MapClaims{"sub": "alice", "aud": jwtkeys.AccessAudience}
+	}
+	claims["exp"] = time.Now().Add(time.Hour).Unix()
+	token, err := keySet.Sign(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestAsymmetricAlgorithms(t *testing.T) {
+	tests := []struct {
+		algorithm string
+		key       crypto.Signer
+		kty       string
+	}{
+		{jwtkeys.AlgorithmRS256, newRSAKey(t), "RSA"},
+		{jwtkeys.AlgorithmEdDSA, newEd25519Key(t), "OKP"},
+	}
+	for _, test := range tests {
+		t.Run(test.algorithm, func(t *testing.T) {
+			privatePath, _ := writeKeyPair(t, "current", test.key)
+			keySet := newKeySet(t, configuration.EnvConfigModel{
+				JwtAlgorithm:      test.algorithm,
+				JwtPrivateKeyFile: privatePath,
+				JwtKeyID:          "current",
+			})
+
+			token, err := keySet.Parse(sign(t, keySet, nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if token.Method.Alg() != test.algorithm || token.Header["kid"] != "current" {
+				t.Fatalf("token is signed with %s by key %v, expected %s by current", token.Method.Alg(),
+					token.Header["kid"], test.algorithm)
+			}
+
+			jwks := keySet.JWKS()
+			if len(jwks.Keys) != 1 || jwks.Keys[0].Kid != "current" || jwks.Keys[0].Kty != test.kty ||
+				jwks.Keys[0].Alg != test.algorithm || jwks.Keys[0].Use != "sig" {
+				t.Fatalf("unexpected JWKS: %+v", jwks)
+			}
+
+			wrongAlgorithm := jwtkeys.AlgorithmEdDSA
+			if test.algorithm == jwtkeys.AlgorithmEdDSA {
+				wrongAlgorithm = jwtkeys.AlgorithmRS256
+			}
+			_, err = jwtkeys.NewKeySet(&configuration.EnvConfigModel{
+				JwtAlgorithm:      wrongAlgorithm,
+				JwtPrivateKeyFile: privatePath,
+			})
+			if err == nil {
+				t.Fatalf("%s key was accepted for %s algorithm", test.algorithm, wrongAlgorithm)
+			}
+		})
+	}
+}
+
+func TestJWKSPublishesRSAKey(t *testing.T) {
+	key := newRSAKey(t)
+	privatePath, _ := writeKeyPair(t, "current", key)
+	keySet := newKeySet(t, configuration.EnvConfigModel{
+		JwtAlgorithm:      jwtkeys.AlgorithmRS256,
+		JwtPrivateKeyFile: privatePath,
+	})
+
+	jwk := keySet.JWKS().Keys[0]
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if new(big.Int).SetBytes(n).Cmp(key.N) != 0 || new(big.Int).SetBytes(e).Int64() != int64(key.E) {
+		t.Fatal("JWKS modulus or exponent does not match public key")
+	}
+	if jwk.Kid == "" {
+		t.Fatal("key id was not derived from public key")
+	}
+	if again := newKeySet(t, configuration.EnvConfigModel{
+		JwtAlgorithm:      jwtkeys.AlgorithmRS256,
+		JwtPrivateKeyFile: privatePath,
+	}); again.JWKS().Keys[0].Kid != jwk.Kid {
+		t.Fatal("derived key id changed after reload of the same key")
+	}
+}
+
+func TestJWKSDoesNotPublishSecret(t *testing.T) {
+	keySet := newKeySet(t, configuration.EnvConfigModel{JwtAlgorithm: jwtkeys.AlgorithmHS256, JwtSecret: testSecret})
+	if jwks := keySet.JWKS(); len(jwks.Keys) != 0 {
+		t.Fatalf("HS256 key set published %+v", jwks)
+	}
+}
+
+func TestRotationAcceptsTokensOfPreviousKey(t *testing.T) {
+	previousPrivatePath, previousPublicPath := writeKeyPair(t, "previous", newEd25519Key(t))
+	previous := newKeySet(t, configuration.EnvConfigModel{
+		JwtAlgorithm:      jwtkeys.AlgorithmEdDSA,
+		JwtPrivateKeyFile: previousPrivatePath,
+		JwtKeyID:          "previous",
+	})
+	previousToken := sign(t, previous, nil)
+
+	currentPrivatePath, _ := writeKeyPair(t, "current", newRSAKey(t))
+	current := newKeySet(t, configuration.EnvConfigModel{
+		JwtAlgorithm:      jwtkeys.AlgorithmRS256,
+		JwtPrivateKeyFile: currentPrivatePath,
+		JwtKeyID:          "current",
+		JwtPublicKeyFiles: "previous=" + previousPublicPath,
+	})
+
+	token, err := current.Parse(previousToken)
+	if err != nil {
+		t.Fatalf("token of previous key was rejected after rotation: %v", err)
+	}
+	if token.Header["kid"] != "previous" {
+		t.Fatalf("token has kid %v, expected previous", token.Header["kid"])
+	}
+	if token, _ := current.Parse(sign(t, current, nil)); token.Header["kid"] != "current" {
+		t.Fatalf("new token is signed with kid %v, expected current", token.Header["kid"])
+	}
+	jwks := current.JWKS()
+	if len(jwks.Keys) != 2 || jwks.Keys[0].Kid != "current" || jwks.Keys[1].Kid != "previous" {
+		t.Fatalf("JWKS after rotation is %+v, expected current and previous key", jwks)
+	}
+
+	//	Token claiming unknown kid or other algorithm than key of its kid is rejected
+	_, err = previous.Parse(sign(t, current, nil))
+	if err == nil {
+		t.Fatal("token of current key was accepted by key set without it")
+	}
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "alice"})
+	forged.Header["kid"] = "previous"
+	forgedString, err := forged.SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = current.Parse(forgedString)
+	if err == nil {
+		t.Fatal("HS256 token was accepted by key set without secret")
+	}
+}
+
+func TestLegacyHS256TokensAreAcceptedUntilDeadline(t *testing.T) {
+	legacyToken := sign(t, newKeySet(t, configuration.EnvConfigModel{JwtSecret: testSecret}), nil)
+	privatePath, _ := writeKeyPair(t, "current", newEd25519Key(t))
+	config := configuration.EnvConfigModel{
+		JwtAlgorithm:        jwtkeys.AlgorithmEdDSA,
+		JwtPrivateKeyFile:   privatePath,
+		JwtSecret:           testSecret,
+		JwtLegacyHS256Until: time.Now().Add(time.Hour).Format(time.RFC3339),
+	}
+
+	_, err := newKeySet(t, config).Parse(legacyToken)
+	if err != nil {
+		t.Fatalf("legacy token was rejected before deadline: %v", err)
+	}
+	config.JwtLegacyHS256Until = time.Now().Add(-time.Hour).Format(time.RFC3339)
+	_, err = newKeySet(t, config).Parse(legacyToken)
+	if err == nil {
+		t.Fatal("legacy token was accepted after deadline")
+	}
+}
+
+func TestIsAccessToken(t *testing.T) {
+	config := configuration.EnvConfigModel{JwtSecret: testSecret}
+	keySet := newKeySet(t, config)
+	config.JwtLegacyAudienceUntil = time.Now().Add(time.Hour).Format(time.RFC3339)
+	legacyKeySet := newKeySet(t, config)
+
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+		access bool
+		legacy bool
+	}{
+		{"access", jwt.MapClaims{"aud": jwtkeys.AccessAudience}, true, true},
+		{"other audience", jwt.MapClaims{"aud": jwtkeys.Audience("2fa_challenge")}, false, false},
+		{"without audience", jwt.MapClaims{"sub": "alice"}, false, true},
+		{"typed without audience", jwt.MapClaims{"typ": "2fa_challenge"}, false, false},
+	}
+	for _, test := range tests {
+		if got := keySet.IsAccessToken(test.claims); got != test.access {
+			t.Errorf("%s token is access token %t, expected %t", test.name, got, test.access)
+		}
+		if got := legacyKeySet.IsAccessToken(test.claims); got != test.legacy {
+			t.Errorf("%s token is access token %t before legacy deadline, expected %t", test.name, got, test.legacy)
+		}
+	}
+}
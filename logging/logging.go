@@ -0,0 +1,116 @@
+// Package logging writes structured logs with slog. Records logged with context of HTTP request
+// carry its request ID and ID of authenticated user, so all logs of one request can be found together
+package logging
+
+import (
+	"context"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/exp/slog"
+	"io"
+	"os"
+	"strings"
+	"tiktok-arena/configuration"
+)
+
+// HeaderRequestID is header with request ID, taken from request or generated, and returned in response
+const HeaderRequestID = "X-Request-ID"
+
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	userIDKey
+	errorKey
+)
+
+// Setup makes logger configured with LOG_LEVEL and LOG_FORMAT default logger of slog and log packages
+func Setup(config *configuration.EnvConfigModel) error {
+	handler, err := NewHandler(os.Stderr, config.LogFormat, config.LogLevel)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
+
+// NewHandler creates handler writing records of level or above to w in json or text format.
+// Handler adds request ID and user ID to records logged with context of request
+func NewHandler(w io.Writer, format string, level string) (slog.Handler, error) {
+	var minLevel slog.Level
+	switch strings.ToLower(level) {
+	case "debug":
+		minLevel = slog.LevelDebug
+	case "info":
+		minLevel = slog.LevelInfo
+	case "warn":
+		minLevel = slog.LevelWarn
+	case "error":
+		minLevel = slog.LevelError
+	default:
+		return nil, fmt.Errorf("unknown log level %s", level)
+	}
+
+	options := slog.HandlerOptions{Level: minLevel}
+	switch format {
+	case "json":
+		return contextHandler{options.NewJSONHandler(w)}, nil
+	case "text":
+		return contextHandler{options.NewTextHandler(w)}, nil
+	default:
+		return nil, fmt.Errorf("unknown log format %s", format)
+	}
+}
+
+// SetRequestID stores request ID in context of request
+func SetRequestID(c *fiber.Ctx, requestId string) {
+	c.Locals(requestIDKey, requestId)
+}
+
+// SetUserID stores ID of authenticated user in context of request
+func SetUserID(c *fiber.Ctx, userId string) {
+	c.Locals(userIDKey, userId)
+}
+
+// SetError stores error request failed with, it is logged by Middleware. Used by error handler,
+// which turns errors into responses before they reach Middleware
+func SetError(c *fiber.Ctx, err error) {
+	c.Locals(errorKey, err)
+}
+
+// RequestID returns request ID stored in context, fiber.Ctx.Context() of request has it
+func RequestID(ctx context.Context) string {
+	requestId, _ := ctx.Value(requestIDKey).(string)
+	return requestId
+}
+
+// UserID returns ID of authenticated user stored in context, empty for anonymous requests
+func UserID(ctx context.Context) string {
+	userId, _ := ctx.Value(userIDKey).(string)
+	return userId
+}
+
+// contextHandler adds request ID and user ID from context to records
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if ctx != nil {
+		if requestId := RequestID(ctx); requestId != "" {
+			record.AddAttrs(slog.String("request_id", requestId))
+		}
+		if userId := UserID(ctx); userId != "" {
+			record.AddAttrs(slog.String("user_id", userId))
+		}
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
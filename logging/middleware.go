@@ -0,0 +1,48 @@
+package logging
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/exp/slog"
+	"time"
+)
+
+// Middleware logs every request after it is handled, requests failed with server error are logged as errors
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		started := time.Now()
+		err := c.Next()
+		logged := err
+		if logged == nil {
+			logged, _ = c.Locals(errorKey).(error)
+		}
+
+		//	Error is turned into response by error handler after middleware returns
+		status := c.Response().StatusCode()
+		if err != nil {
+			status = fiber.StatusInternalServerError
+			var fiberErr *fiber.Error
+			if errors.As(err, &fiberErr) {
+				status = fiberErr.Code
+			}
+		}
+
+		level := slog.LevelInfo
+		if status >= fiber.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		attrs := []slog.Attr{
+			slog.String("method", c.Method()),
+			slog.String("path", c.Path()),
+			slog.String("route", c.Route().Path),
+			slog.Int("status", status),
+			slog.Float64("duration_ms", float64(time.Since(started).Microseconds())/1000),
+			slog.String("ip", c.IP()),
+		}
+		if logged != nil {
+			attrs = append(attrs, slog.String("error", logged.Error()))
+		}
+		slog.LogAttrs(c.Context(), level, "Request handled", attrs...)
+		return err
+	}
+}
@@ -0,0 +1,62 @@
+// Package mailer sends emails to users. Sending is hidden behind Mailer interface,
+// so the server runs locally and in tests without a real mail server
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"tiktok-arena/configuration"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, message Message) error
+}
+
+// ErrDisabled is returned by DisabledMailer
+var ErrDisabled = errors.New("sending emails is disabled")
+
+// Default is mailer selected in configuration
+var Default Mailer
+
+// Setup creates mailer selected in configuration
+func Setup(config *configuration.EnvConfigModel) error {
+	switch config.Mailer {
+	case "disabled":
+		Default = &DisabledMailer{}
+	case "log":
+		Default = &LogMailer{}
+	case "memory":
+		Default = &MemoryMailer{}
+	case "smtp":
+		Default = &SMTPMailer{
+			Host:     config.SMTPHost,
+			Port:     config.SMTPPort,
+			Username: config.SMTPUsername,
+			Password: config.SMTPPassword,
+			From:     config.MailFrom,
+		}
+	default:
+		return fmt.Errorf("unknown mailer %s", config.Mailer)
+	}
+	return nil
+}
+
+// DisabledMailer refuses to send emails, it is used until mail server is configured
+type DisabledMailer struct{}
+
+func (m *DisabledMailer) Send(context.Context, Message) error {
+	return ErrDisabled
+}
+
+// Enabled reports whether mailer sends emails
+func Enabled(m Mailer) bool {
+	_, disabled := m.(*DisabledMailer)
+	return !disabled
+}
@@ -0,0 +1,48 @@
+package mailer
+
+import (
+	"context"
+	"golang.org/x/exp/slog"
+	"sync"
+)
+
+// LogMailer writes emails to log instead of sending them, used for local development.
+// Body is not logged, it carries verification and password reset tokens
+type LogMailer struct{}
+
+func (m *LogMailer) Send(ctx context.Context, message Message) error {
+	slog.InfoCtx(ctx, "Email", "to", message.To, "subject", message.Subject)
+	return nil
+}
+
+// MemoryMailer keeps sent emails in memory, so tests can read links from them
+type MemoryMailer struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func (m *MemoryMailer) Send(_ context.Context, message Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, message)
+	return nil
+}
+
+// Messages returns copy of all sent emails
+func (m *MemoryMailer) Messages() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Message(nil), m.messages...)
+}
+
+// Last returns last email sent to recipient
+func (m *MemoryMailer) Last(to string) (Message, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if m.messages[i].To == to {
+			return m.messages[i], true
+		}
+	}
+	return Message{}, false
+}
@@ -0,0 +1,43 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// SMTPMailer sends emails through SMTP server, STARTTLS is used when server supports it
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func (m *SMTPMailer) Send(_ context.Context, message Message) error {
+	if strings.ContainsAny(message.To, "\r\n") {
+		return fmt.Errorf("invalid recipient %q", message.To)
+	}
+
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	var body strings.Builder
+	body.WriteString("From: " + m.From + "\r\n")
+	body.WriteString("To: " + message.To + "\r\n")
+	body.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", message.Subject) + "\r\n")
+	body.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	body.WriteString("MIME-Version: 1.0\r\n")
+	body.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	body.WriteString("\r\n")
+	body.WriteString(strings.ReplaceAll(message.Body, "\n", "\r\n"))
+
+	return smtp.SendMail(net.JoinHostPort(m.Host, m.Port), auth, m.From, []string{message.To}, []byte(body.String()))
+}
@@ -1,41 +1,20 @@
 package main
 
 import (
-	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/gofiber/fiber/v2/middleware/logger"
-	"log"
-	"tiktok-arena/configuration"
-	"tiktok-arena/database"
-	"tiktok-arena/router"
+	"golang.org/x/exp/slog"
+	"os"
+	"tiktok-arena/commands"
 )
 
-func init() {
-	err := configuration.LoadConfig(".env")
-	if err != nil {
-		log.Fatalln("Failed to load environment variables!", err.Error())
-	}
-	database.ConnectDB(&configuration.EnvConfig)
-}
-
 //	@title			TikTok arena API
 //	@version		1.0
 //	@description	API for TikTok arena application
 //	@host			localhost:8000
 //	@BasePath		/api
 func main() {
-	app := fiber.New()
-
-	//	Logger middleware for logging HTTP request/response details
-	app.Use(logger.New())
-
-	//	Cors middleware
-	app.Use(cors.New(cors.Config{
-		AllowOrigins: "*",
-		AllowHeaders: "*",
-	}))
-
-	router.SetupRoutes(app)
-
-	log.Fatal(app.Listen(":8000"))
+	err := commands.NewApp().Run(os.Args)
+	if err != nil {
+		slog.Error("Command failed", "error", err)
+		os.Exit(1)
+	}
 }
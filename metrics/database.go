@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"gorm.io/gorm"
+	"time"
+)
+
+const queryStartedKey = "metrics:query_started"
+
+var dbQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: Namespace,
+	Subsystem: "db",
+	Name:      "query_duration_seconds",
+	Help:      "Duration of GORM queries by operation and table",
+	Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+}, []string{"operation", "table"})
+
+func init() {
+	MustRegister(dbQueryDuration)
+}
+
+// InstrumentDB records duration of every query run by db and registers collector of its connection pool stats
+func InstrumentDB(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	callbacks := db.Callback()
+	processors := []struct {
+		operation string
+		before    func(name string, fn func(*gorm.DB)) error
+		after     func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", callbacks.Create().Before("*").Register, callbacks.Create().After("*").Register},
+		{"query", callbacks.Query().Before("*").Register, callbacks.Query().After("*").Register},
+		{"update", callbacks.Update().Before("*").Register, callbacks.Update().After("*").Register},
+		{"delete", callbacks.Delete().Before("*").Register, callbacks.Delete().After("*").Register},
+		{"row", callbacks.Row().Before("*").Register, callbacks.Row().After("*").Register},
+		{"raw", callbacks.Raw().Before("*").Register, callbacks.Raw().After("*").Register},
+	}
+	for _, processor := range processors {
+		err = processor.before("metrics:before_"+processor.operation, startQuery)
+		if err != nil {
+			return err
+		}
+		err = processor.after("metrics:after_"+processor.operation, observeQuery(processor.operation))
+		if err != nil {
+			return err
+		}
+	}
+
+	MustRegister(collectors.NewDBStatsCollector(sqlDB, db.Dialector.Name()))
+	return nil
+}
+
+func startQuery(db *gorm.DB) {
+	db.InstanceSet(queryStartedKey, time.Now())
+}
+
+func observeQuery(operation string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(queryStartedKey)
+		if !ok {
+			return
+		}
+		started, ok := value.(time.Time)
+		if !ok {
+			return
+		}
+		dbQueryDuration.WithLabelValues(operation, db.Statement.Table).Observe(time.Since(started).Seconds())
+	}
+}
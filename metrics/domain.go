@@ -0,0 +1,43 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	TournamentsCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "tournaments_created_total",
+		Help:      "Count of created tournaments, including imported ones",
+	})
+
+	// ContestsStarted counts generated contest brackets by contest type
+	ContestsStarted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "contests_started_total",
+		Help:      "Count of started contests by contest type",
+	}, []string{"type"})
+
+	// ContestsFinished counts submitted contest results by contest type
+	ContestsFinished = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "contests_finished_total",
+		Help:      "Count of finished contests by contest type",
+	}, []string{"type"})
+
+	// VotesCast counts tournament upvotes, removed upvotes are not subtracted
+	VotesCast = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "votes_cast_total",
+		Help:      "Count of tournament upvotes",
+	})
+
+	// LoginFailures counts failed password and two-factor checks, also when changing password
+	LoginFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "login_failures_total",
+		Help:      "Count of failed login attempts",
+	})
+)
+
+func init() {
+	MustRegister(TournamentsCreated, ContestsStarted, ContestsFinished, VotesCast, LoginFailures)
+}
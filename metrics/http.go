@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"strconv"
+	"time"
+)
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Count of handled HTTP requests by method, route and response status",
+	}, []string{"method", "route", "status"})
+
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Duration of handling HTTP requests by method, route and response status",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+)
+
+func init() {
+	MustRegister(httpRequests, httpRequestDuration)
+}
+
+// Middleware records count and duration of requests. Requests are labelled with route pattern,
+// e.g. /api/tournament/:tournamentId, instead of path, so ids do not create new series
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		started := time.Now()
+		err := c.Next()
+
+		//	Error is turned into response by error handler after middleware returns
+		status := c.Response().StatusCode()
+		if err != nil {
+			status = fiber.StatusInternalServerError
+			var fiberErr *fiber.Error
+			if errors.As(err, &fiberErr) {
+				status = fiberErr.Code
+			}
+		}
+
+		//	Method is backed by request buffer reused by fasthttp, labels are kept after request ends
+		labels := prometheus.Labels{
+			"method": utils.CopyString(c.Method()),
+			"route":  c.Route().Path,
+			"status": strconv.Itoa(status),
+		}
+		httpRequests.With(labels).Inc()
+		httpRequestDuration.With(labels).Observe(time.Since(started).Seconds())
+		return err
+	}
+}
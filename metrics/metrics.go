@@ -0,0 +1,41 @@
+// Package metrics holds Prometheus metrics of the server and exposes them on /metrics.
+// Subsystems declare their metrics in this package or register own collectors with MustRegister
+package metrics
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/valyala/fasthttp/fasthttpadaptor"
+)
+
+// Namespace prefixes names of all metrics of the server
+const Namespace = "arena"
+
+// Registry holds every metric exposed by Handler. Own registry is used instead of global one,
+// so libraries can not add metrics without being asked to
+var Registry = prometheus.NewRegistry()
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// MustRegister registers collectors in Registry and panics if any of them is already registered
+func MustRegister(cs ...prometheus.Collector) {
+	Registry.MustRegister(cs...)
+}
+
+// Handler serves metrics of Registry in Prometheus text format
+func Handler() fiber.Handler {
+	handler := fasthttpadaptor.NewFastHTTPHandler(
+		promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry}),
+	)
+	return func(c *fiber.Ctx) error {
+		handler(c.Context())
+		return nil
+	}
+}
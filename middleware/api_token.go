@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+	"strings"
+	"tiktok-arena/apierror"
+	"tiktok-arena/logging"
+	"tiktok-arena/models"
+	"time"
+)
+
+// lastUsedPrecision limits how often last used timestamp of API token is written
+const lastUsedPrecision = time.Minute
+
+// scopesClaim holds scopes of API token in claims of authenticated user, JWT sessions do not have it
+const scopesClaim = "scp"
+
+func bearerAPIToken(c *fiber.Ctx) (string, bool) {
+	header := c.Get(fiber.HeaderAuthorization)
+	if len(header) < 7 || !strings.EqualFold(header[:7], "Bearer ") {
+		return "", false
+	}
+	token := strings.TrimSpace(header[7:])
+	return token, strings.HasPrefix(token, models.APITokenPrefix)
+}
+
+// authenticateAPIToken checks personal API token and stores its owner as authenticated user,
+// the same way as JWT middleware does, so handlers do not depend on authentication method
+func (a *Auth) authenticateAPIToken(c *fiber.Ctx, apiToken string) error {
+	token, err := a.APITokens.GetAPITokenByHash(c.Context(), models.HashAPIToken(apiToken))
+	if err != nil {
+		return jwtError(c, err)
+	}
+	now := time.Now().UTC()
+	if token.ExpiresAt != nil && token.ExpiresAt.Before(now) {
+		return jwtError(c, jwt.ErrTokenExpired)
+	}
+
+	user, err := a.Users.GetUserById(c.Context(), token.UserID.String())
+	if err != nil {
+		return jwtError(c, err)
+	}
+	if user.Banned {
+		return bannedError(c)
+	}
+	//	Tokens created before password change or forced reset are revoked together with sessions
+	if user.SessionsRevokedAt != nil && token.CreatedAt.Before(*user.SessionsRevokedAt) {
+		return apierror.Unauthorized("token_revoked", "API token was revoked, create a new one")
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) > lastUsedPrecision {
+		err = a.APITokens.UpdateAPITokenLastUsed(c.Context(), token.ID.String(), now)
+		if err != nil {
+			return jwtError(c, err)
+		}
+	}
+
+	scopes := make([]interface{}, 0)
+	for _, scope := range token.ScopeList() {
+		scopes = append(scopes, scope)
+	}
+	//	iat lets CheckAuthState tell whether token was created before sessions were revoked
+	c.Locals("user", &jwt.Token{
+		Raw:   apiToken,
+		Valid: true,
+		Claims: jwt.MapClaims{
+			"sub":       user.ID.String(),
+			"name":      user.Name,
+			"role":      user.Role,
+			"iat":       float64(token.CreatedAt.Unix()),
+			scopesClaim: scopes,
+		},
+	})
+	logging.SetUserID(c, user.ID.String())
+	return c.Next()
+}
+
+// RequireScope allows API tokens only with given scope, JWT sessions have every scope.
+// Must be used after Protected()
+func RequireScope(scope string) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		scopes, isAPIToken := tokenScopes(c)
+		if !isAPIToken {
+			return c.Next()
+		}
+		for _, granted := range scopes {
+			if granted == scope {
+				return c.Next()
+			}
+		}
+		return insufficientPermissions(c)
+	}
+}
+
+// SessionOnly rejects API tokens, used for account management and privileged routes. Must be used after Protected()
+func SessionOnly() func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		if _, isAPIToken := tokenScopes(c); isAPIToken {
+			return insufficientPermissions(c)
+		}
+		return c.Next()
+	}
+}
+
+func tokenScopes(c *fiber.Ctx) ([]interface{}, bool) {
+	token, ok := c.Locals("user").(*jwt.Token)
+	if !ok {
+		return nil, false
+	}
+	claims := token.Claims.(jwt.MapClaims)
+	scopes, ok := claims[scopesClaim].([]interface{})
+	return scopes, ok
+}
@@ -3,29 +3,150 @@ package middleware
 import (
 	"github.com/gofiber/fiber/v2"
 	jwtware "github.com/gofiber/jwt/v3"
-	"tiktok-arena/configuration"
+	"github.com/golang-jwt/jwt/v4"
+	"tiktok-arena/apierror"
+	"tiktok-arena/jwtkeys"
+	"tiktok-arena/logging"
+	"tiktok-arena/models"
+	"tiktok-arena/store"
+	"time"
 )
 
-func Protected() func(*fiber.Ctx) error {
-	return jwtware.New(jwtware.Config{
-		SigningKey:   []byte(configuration.EnvConfig.JwtSecret),
-		ErrorHandler: jwtError,
+// Auth authenticates users, it checks ban status of users and personal API tokens in stores
+type Auth struct {
+	Users     store.UserStore
+	APITokens store.APITokenStore
+}
+
+func NewAuth(users store.UserStore, apiTokens store.APITokenStore) *Auth {
+	return &Auth{Users: users, APITokens: apiTokens}
+}
+
+// Protected authenticates user with JWT or personal API token from Authorization header
+func (a *Auth) Protected() func(*fiber.Ctx) error {
+	jwtHandler := jwtware.New(jwtware.Config{
+		KeyFunc:        jwtkeys.Keys.Keyfunc,
+		ErrorHandler:   jwtError,
+		SuccessHandler: a.accessTokenOnly,
 	})
+	return func(c *fiber.Ctx) error {
+		if apiToken, ok := bearerAPIToken(c); ok {
+			return a.authenticateAPIToken(c, apiToken)
+		}
+		return jwtHandler(c)
+	}
 }
 
-func jwtError(c *fiber.Ctx, err error) error {
-	if err.Error() == "Missing or malformed JWT" {
-		c.Status(fiber.StatusBadRequest)
-		return c.JSON(fiber.Map{
-			"message": "Missing or malformed JWT",
-			"data":    nil,
-		})
+// OptionalAuth authenticates user if request has Authorization header, anonymous requests are passed through
+func (a *Auth) OptionalAuth() func(*fiber.Ctx) error {
+	protected := a.Protected()
+	return func(c *fiber.Ctx) error {
+		if c.Get(fiber.HeaderAuthorization) == "" {
+			return c.Next()
+		}
+		return protected(c)
+	}
+}
+
+// accessTokenOnly rejects tokens issued for other audiences, e.g. two-factor login challenge,
+// tokens of banned users and sessions revoked after token was issued.
+// Role claim is replaced with current role of user, so demoted users lose permissions immediately
+func (a *Auth) accessTokenOnly(c *fiber.Ctx) error {
+	token := c.Locals("user").(*jwt.Token)
+	claims := token.Claims.(jwt.MapClaims)
+	if !jwtkeys.Keys.IsAccessToken(claims) {
+		return jwtError(c, jwt.ErrTokenInvalidAudience)
+	}
 
+	userId, _ := claims["sub"].(string)
+	state, err := a.Users.GetUserAuthState(c.Context(), userId)
+	if err != nil {
+		return jwtError(c, err)
+	}
+	err = CheckAuthState(claims, state)
+	if err != nil {
+		return err
+	}
+	claims["role"] = state.Role
+	logging.SetUserID(c, userId)
+	return c.Next()
+}
+
+// CheckAuthState rejects banned users and tokens issued before sessions of user were revoked.
+// Protected() checks it on every request, responses which outlive request like notification stream
+// check it again with current state of user
+func CheckAuthState(claims jwt.MapClaims, state models.UserAuthState) error {
+	if state.Banned {
+		return bannedError(nil)
+	}
+	if issuedBefore(claims, state.SessionsRevokedAt) {
+		return apierror.Unauthorized("session_revoked", "Session was revoked, log in again")
+	}
+	return nil
+}
+
+// issuedBefore checks iat claim against revocation time, tokens without iat are treated as revoked
+// once any revocation happened. iat has second precision, so token issued in the same second is kept
+func issuedBefore(claims jwt.MapClaims, revokedAt *time.Time) bool {
+	if revokedAt == nil {
+		return false
+	}
+	issuedAt, ok := claims["iat"].(float64)
+	return !ok || int64(issuedAt) < revokedAt.Unix()
+}
+
+// RequireRole allows request only if user has one of given roles, must be used after Protected()
+func RequireRole(roles ...string) func(*fiber.Ctx) error {
+	allowed := make(map[string]bool, len(roles))
+	for _, role := range roles {
+		allowed[role] = true
+	}
+	return func(c *fiber.Ctx) error {
+		if !allowed[UserRole(c)] {
+			return insufficientPermissions(c)
+		}
+		return c.Next()
+	}
+}
+
+// UserRole returns role of authenticated user, Protected() sets it from store on every request
+func UserRole(c *fiber.Ctx) string {
+	token, ok := c.Locals("user").(*jwt.Token)
+	if !ok {
+		return ""
+	}
+	claims := token.Claims.(jwt.MapClaims)
+	role, ok := claims["role"].(string)
+	if !ok || role == "" {
+		return models.RoleUser
+	}
+	return role
+}
+
+func insufficientPermissions(_ *fiber.Ctx) error {
+	return apierror.Forbidden("insufficient_permissions", "Insufficient permissions")
+}
+
+func bannedError(_ *fiber.Ctx) error {
+	return apierror.Forbidden("user_banned", "User is banned")
+}
+
+func jwtError(_ *fiber.Ctx, err error) error {
+	if err.Error() == "Missing or malformed JWT" {
+		return apierror.Unauthorized("missing_token", "Missing or malformed JWT")
 	} else {
-		c.Status(fiber.StatusUnauthorized)
-		return c.JSON(fiber.Map{
-			"message": "Invalid or expired JWT",
-			"data":    nil,
-		})
+		return apierror.Unauthorized("invalid_token", "Invalid or expired JWT").Wrap(err)
+	}
+}
+
+// TokenFromQuery moves token query parameter to Authorization header, for clients like browser EventSource
+// which can not set headers. Must be used before Protected()
+func TokenFromQuery() func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		token := c.Query("token")
+		if token != "" && c.Get(fiber.HeaderAuthorization) == "" {
+			c.Request().Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+		}
+		return c.Next()
 	}
 }
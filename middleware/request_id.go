@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/google/uuid"
+	"tiktok-arena/logging"
+)
+
+// maxRequestIDLength limits request IDs taken from clients, longer ones are replaced with generated ID
+const maxRequestIDLength = 128
+
+// RequestID takes request ID from X-Request-ID header, so requests can be traced through proxies,
+// or generates new one. Request ID is stored for logs and returned in X-Request-ID response header
+func RequestID() func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		requestId := c.Get(logging.HeaderRequestID)
+		if validRequestID(requestId) {
+			//	Header value is backed by request buffer, request ID is kept until request ends
+			requestId = utils.CopyString(requestId)
+		} else {
+			requestId = uuid.NewString()
+		}
+		logging.SetRequestID(c, requestId)
+		c.Set(logging.HeaderRequestID, requestId)
+		return c.Next()
+	}
+}
+
+// validRequestID accepts printable ASCII without spaces, other characters could break log lines or headers
+func validRequestID(requestId string) bool {
+	if requestId == "" || len(requestId) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(requestId); i++ {
+		if requestId[i] <= ' ' || requestId[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/google/uuid"
+	"strings"
+	"time"
+)
+
+// APITokenPrefix starts every personal access token, so tokens are told apart from JWTs
+// and are easy to find by secret scanners
+const APITokenPrefix = "arena_"
+
+// APIToken is personal access token for bots and scripts, only hash of token is stored
+type APIToken struct {
+	ID         *uuid.UUID `gorm:"type:uuid;primary_key"`
+	UserID     *uuid.UUID `gorm:"not null;index"`
+	User       *User      `gorm:"foreignKey:UserID" json:"-"`
+	Name       string     `gorm:"not null"`
+	Prefix     string     `gorm:"not null"`
+	TokenHash  string     `gorm:"not null;uniqueIndex" json:"-"`
+	Scopes     string     `gorm:"not null"`
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	CreatedAt  time.Time
+}
+
+type CreateAPIToken struct {
+	Name string `validate:"required,max=100"`
+	// Scopes granted to token: read, tournaments:create, results:submit
+	Scopes []string `validate:"required,min=1"`
+	// ExpiresInDays is token lifetime, zero means token never expires
+	ExpiresInDays int `validate:"gte=0,lte=365"`
+}
+
+// CreatedAPIToken is returned once after token creation, plaintext token can not be retrieved later
+type CreatedAPIToken struct {
+	APIToken
+	Token string
+}
+
+const (
+	ScopeRead              = "read"
+	ScopeCreateTournaments = "tournaments:create"
+	ScopeSubmitResults     = "results:submit"
+)
+
+func GetAllowedScopes() map[string]bool {
+	return map[string]bool{
+		ScopeRead:              true,
+		ScopeCreateTournaments: true,
+		ScopeSubmitResults:     true,
+	}
+}
+
+func CheckIfAllowedScope(scope string) bool {
+	return GetAllowedScopes()[scope]
+}
+
+// ScopeList splits space separated scopes of token
+func (t *APIToken) ScopeList() []string {
+	return strings.Fields(t.Scopes)
+}
+
+// HashAPIToken returns hash under which token is stored, tokens are random enough for plain SHA-256
+func HashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
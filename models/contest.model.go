@@ -0,0 +1,30 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// Contest is result of finished contest played on tournament
+type Contest struct {
+	ID             *uuid.UUID  `gorm:"type:uuid;primary_key"`
+	TournamentID   *uuid.UUID  `gorm:"not null;index"`
+	Tournament     *Tournament `gorm:"foreignKey:TournamentID" json:"-"`
+	UserID         *uuid.UUID  `gorm:"not null;index"`
+	User           *User       `gorm:"foreignKey:UserID" json:"-"`
+	ContestType    string      `gorm:"not null"`
+	WinnerTiktokID *uuid.UUID  `gorm:"type:uuid;not null"`
+	CreatedAt      time.Time
+}
+
+type ContestResult struct {
+	ContestType string                `validate:"required"`
+	WinnerURL   string                `validate:"required"`
+	Tiktoks     []ContestTiktokResult `validate:"required,min=2,dive"`
+}
+
+// ContestTiktokResult holds points tiktok scored in contest, e.g. count of won matches
+type ContestTiktokResult struct {
+	URL    string  `validate:"required"`
+	Points float64 `validate:"gte=0"`
+}
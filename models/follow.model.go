@@ -0,0 +1,86 @@
+package models
+
+import (
+	"encoding/base64"
+	"fmt"
+	"github.com/google/uuid"
+	"strings"
+	"time"
+)
+
+// Follow means that follower sees new tournaments and contest results of followee in feed
+type Follow struct {
+	FollowerID *uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Follower   *User      `gorm:"foreignKey:FollowerID" json:"-"`
+	FolloweeID *uuid.UUID `gorm:"type:uuid;primaryKey;index"`
+	Followee   *User      `gorm:"foreignKey:FolloweeID" json:"-"`
+	CreatedAt  time.Time
+}
+
+const (
+	FeedTournamentCreated = "tournament_created"
+	FeedContestFinished   = "contest_finished"
+)
+
+type FeedItem struct {
+	Type       string
+	ID         *uuid.UUID
+	CreatedAt  time.Time
+	User       FeedUser
+	Tournament FeedTournament
+	Contest    *FeedContest `json:",omitempty"`
+}
+
+type FeedUser struct {
+	ID          *uuid.UUID
+	Name        string
+	DisplayName string
+}
+
+type FeedTournament struct {
+	ID   *uuid.UUID
+	Name string
+	Size int
+}
+
+type FeedContest struct {
+	ContestType string
+	WinnerURL   string
+}
+
+// FeedPage holds feed items, NextCursor is empty on last page
+type FeedPage struct {
+	Items      []FeedItem
+	NextCursor string
+}
+
+// FeedCursor points to last item of feed page, next page starts from items older than it
+type FeedCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+func (c FeedCursor) Encode() string {
+	raw := fmt.Sprintf("%s|%s", c.CreatedAt.UTC().Format(time.RFC3339Nano), c.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func ParseFeedCursor(cursor string) (FeedCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return FeedCursor{}, fmt.Errorf("Invalid feed cursor")
+	}
+	createdAt, id, found := strings.Cut(string(raw), "|")
+	if !found {
+		return FeedCursor{}, fmt.Errorf("Invalid feed cursor")
+	}
+	parsedCreatedAt, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return FeedCursor{}, fmt.Errorf("Invalid feed cursor")
+	}
+	parsedId, err := uuid.Parse(id)
+	if err != nil {
+		return FeedCursor{}, fmt.Errorf("Invalid feed cursor")
+	}
+	return FeedCursor{CreatedAt: parsedCreatedAt, ID: parsedId}, nil
+}
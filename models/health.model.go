@@ -0,0 +1,12 @@
+package models
+
+type Readiness struct {
+	Ready  bool
+	Checks []ReadinessCheck
+}
+
+// ReadinessCheck is outcome of one readiness check, Error is empty when check passed
+type ReadinessCheck struct {
+	Name  string
+	Error string `json:",omitempty"`
+}
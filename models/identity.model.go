@@ -0,0 +1,29 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// UserIdentity links user to account at external OpenID Connect provider
+type UserIdentity struct {
+	ID        *uuid.UUID `gorm:"type:uuid;primary_key"`
+	UserID    *uuid.UUID `gorm:"not null;index"`
+	User      *User      `gorm:"foreignKey:UserID" json:"-"`
+	Issuer    string     `gorm:"not null;uniqueIndex:idx_user_identities_issuer_subject"`
+	Subject   string     `gorm:"not null;uniqueIndex:idx_user_identities_issuer_subject"`
+	CreatedAt time.Time
+}
+
+type OIDCAuthorization struct {
+	AuthorizationURL string
+	State            string
+	// FlowToken must be sent back with authorization code, it keeps PKCE verifier and nonce of this flow
+	FlowToken string
+}
+
+type OIDCCallbackInput struct {
+	Code      string `validate:"required"`
+	State     string `validate:"required"`
+	FlowToken string `validate:"required"`
+}
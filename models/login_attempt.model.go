@@ -0,0 +1,9 @@
+package models
+
+import "time"
+
+type LoginAttempt struct {
+	Key         string    `gorm:"primary_key"`
+	Failures    int       `gorm:"not null"`
+	LastFailure time.Time `gorm:"not null"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// Notification is event shown to user, created by notifications subsystem from published events
+type Notification struct {
+	ID         *uuid.UUID `gorm:"type:uuid;primary_key"`
+	UserID     *uuid.UUID `gorm:"not null;index:idx_notifications_user_created,priority:1"`
+	User       *User      `gorm:"foreignKey:UserID" json:"-"`
+	Type       string     `gorm:"not null"`
+	Message    string     `gorm:"not null"`
+	ActorID    *uuid.UUID `gorm:"type:uuid"`
+	TargetType string
+	TargetID   *uuid.UUID `gorm:"type:uuid"`
+	ReadAt     *time.Time
+	CreatedAt  time.Time `gorm:"index:idx_notifications_user_created,priority:2"`
+}
+
+type NotificationsPage struct {
+	Notifications []Notification
+	Total         int64
+}
+
+type UnreadNotifications struct {
+	Unread int64
+}
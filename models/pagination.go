@@ -0,0 +1,29 @@
+package models
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+type Pagination struct {
+	Page  int
+	Count int
+}
+
+// NewPagination clamps page and count to sane values, page numbers start from 1
+func NewPagination(page int, count int) Pagination {
+	if page < 1 {
+		page = 1
+	}
+	if count < 1 {
+		count = DefaultPageSize
+	}
+	if count > MaxPageSize {
+		count = MaxPageSize
+	}
+	return Pagination{Page: page, Count: count}
+}
+
+func (p Pagination) Offset() int {
+	return (p.Page - 1) * p.Count
+}
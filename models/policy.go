@@ -0,0 +1,61 @@
+package models
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"strings"
+	"tiktok-arena/configuration"
+	"unicode/utf8"
+)
+
+//go:embed common_passwords.txt
+var commonPasswordsList string
+
+var commonPasswords = parseCommonPasswords(commonPasswordsList)
+
+func parseCommonPasswords(list string) map[string]bool {
+	passwords := make(map[string]bool)
+	for _, line := range strings.Split(list, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			passwords[strings.ToLower(line)] = true
+		}
+	}
+	return passwords
+}
+
+// ValidateUsername checks username against length and charset rules from configuration
+func ValidateUsername(username string) error {
+	config := configuration.EnvConfig
+
+	length := utf8.RuneCountInString(username)
+	if length < config.UsernameMinLength || length > config.UsernameMaxLength {
+		return fmt.Errorf("Username must be between %d and %d characters long",
+			config.UsernameMinLength, config.UsernameMaxLength)
+	}
+
+	pattern := config.UsernamePattern()
+	if pattern != nil && !pattern.MatchString(username) {
+		return errors.New("Username contains characters that are not allowed")
+	}
+	return nil
+}
+
+// ValidatePassword checks password against length rule and bundled list of common passwords
+func ValidatePassword(username string, password string) error {
+	config := configuration.EnvConfig
+
+	if utf8.RuneCountInString(password) < config.PasswordMinLength {
+		return fmt.Errorf("Password must be at least %d characters long", config.PasswordMinLength)
+	}
+
+	if strings.EqualFold(username, password) {
+		return fmt.Errorf("Password must not be the same as username")
+	}
+
+	if config.PasswordRejectCommon && commonPasswords[strings.ToLower(password)] {
+		return fmt.Errorf("Password is too common")
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// UserProfile is public page of user
+type UserProfile struct {
+	ID          *uuid.UUID
+	Name        string
+	DisplayName string
+	Bio         string
+	AvatarURL   string
+	CreatedAt   time.Time
+	Stats       UserStats
+	Tournaments []Tournament
+}
+
+type UserStats struct {
+	TournamentsCreated int64
+	ContestsPlayed     int64
+	UpvotesReceived    int64
+	Followers          int64
+	Following          int64
+}
+
+type EditProfile struct {
+	DisplayName string `validate:"max=64"`
+	Bio         string `validate:"max=500"`
+	AvatarURL   string `validate:"omitempty,url,max=2048"`
+}
@@ -0,0 +1,137 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type Report struct {
+	ID           *uuid.UUID `gorm:"type:uuid;primary_key"`
+	ReporterID   *uuid.UUID `gorm:"not null"`
+	Reporter     *User      `gorm:"foreignKey:ReporterID" json:"-"`
+	TargetType   string     `gorm:"not null"`
+	TargetID     *uuid.UUID `gorm:"type:uuid;not null"`
+	Reason       string     `gorm:"not null"`
+	Details      string
+	Status       string `gorm:"not null;default:open;index"`
+	CreatedAt    time.Time
+	ResolvedAt   *time.Time
+	ResolvedByID *uuid.UUID `gorm:"type:uuid"`
+}
+
+type ModerationAction struct {
+	ID          *uuid.UUID `gorm:"type:uuid;primary_key"`
+	ReportID    *uuid.UUID `gorm:"type:uuid;index"`
+	Report      *Report    `gorm:"foreignKey:ReportID" json:"-"`
+	ModeratorID *uuid.UUID `gorm:"not null"`
+	Moderator   *User      `gorm:"foreignKey:ModeratorID" json:"-"`
+	Action      string     `gorm:"not null"`
+	TargetType  string     `gorm:"not null"`
+	TargetID    *uuid.UUID `gorm:"type:uuid;not null"`
+	AuthorID    *uuid.UUID `gorm:"type:uuid"`
+	Note        string
+	CreatedAt   time.Time
+}
+
+type CreateReport struct {
+	TargetType string `validate:"required"`
+	TargetID   string `validate:"required,uuid"`
+	Reason     string `validate:"required"`
+	Details    string `validate:"max=1000"`
+}
+
+type ModerationInput struct {
+	Action string
+	Note   string `validate:"max=1000"`
+}
+
+type ReportsPage struct {
+	Reports []Report
+	Total   int64
+}
+
+type ModerationActionsPage struct {
+	Actions []ModerationAction
+	Total   int64
+}
+
+const (
+	ReportTargetTournament = "tournament"
+	ReportTargetTiktok     = "tiktok"
+)
+
+func GetAllowedReportTargetTypes() map[string]bool {
+	return map[string]bool{
+		ReportTargetTournament: true,
+		ReportTargetTiktok:     true,
+	}
+}
+
+func CheckIfAllowedReportTargetType(targetType string) bool {
+	return GetAllowedReportTargetTypes()[targetType]
+}
+
+const (
+	ReportReasonSpam          = "spam"
+	ReportReasonHarassment    = "harassment"
+	ReportReasonHateSpeech    = "hate_speech"
+	ReportReasonSexualContent = "sexual_content"
+	ReportReasonViolence      = "violence"
+	ReportReasonCopyright     = "copyright"
+	ReportReasonOther         = "other"
+)
+
+func GetAllowedReportReasons() map[string]bool {
+	return map[string]bool{
+		ReportReasonSpam:          true,
+		ReportReasonHarassment:    true,
+		ReportReasonHateSpeech:    true,
+		ReportReasonSexualContent: true,
+		ReportReasonViolence:      true,
+		ReportReasonCopyright:     true,
+		ReportReasonOther:         true,
+	}
+}
+
+func CheckIfAllowedReportReason(reason string) bool {
+	return GetAllowedReportReasons()[reason]
+}
+
+const (
+	ReportStatusOpen      = "open"
+	ReportStatusResolved  = "resolved"
+	ReportStatusDismissed = "dismissed"
+)
+
+func GetAllowedReportStatuses() map[string]bool {
+	return map[string]bool{
+		ReportStatusOpen:      true,
+		ReportStatusResolved:  true,
+		ReportStatusDismissed: true,
+	}
+}
+
+func CheckIfAllowedReportStatus(status string) bool {
+	return GetAllowedReportStatuses()[status]
+}
+
+const (
+	ModerationResolve   = "resolve"
+	ModerationDismiss   = "dismiss"
+	ModerationHide      = "hide"
+	ModerationWarn      = "warn"
+	ModerationBanAuthor = "ban"
+)
+
+// GetAllowedContentActions returns actions moderator can take on reported content or its author
+func GetAllowedContentActions() map[string]bool {
+	return map[string]bool{
+		ModerationHide:      true,
+		ModerationWarn:      true,
+		ModerationBanAuthor: true,
+	}
+}
+
+func CheckIfAllowedContentAction(action string) bool {
+	return GetAllowedContentActions()[action]
+}
@@ -3,15 +3,16 @@ package models
 import "github.com/google/uuid"
 
 type Tiktok struct {
-	ID           *uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primary_key"`
+	ID           *uuid.UUID  `gorm:"type:uuid;primary_key"`
 	TournamentID *uuid.UUID  `gorm:"not null"`
 	Tournament   *Tournament `gorm:"foreignKey:TournamentID"`
 	URL          string      `gorm:"not null"`
 	Wins         int
 	AvgPoints    float64
 	TimesPlayed  int
+	Hidden       bool `gorm:"not null;default:false"`
 }
 
 type CreateTiktok struct {
-	URL string `validate:"required"`
+	URL string `validate:"required,http_url,max=2048"`
 }
@@ -1,21 +1,48 @@
 package models
 
 import (
+	"fmt"
 	"github.com/google/uuid"
+	"strconv"
+	"time"
 )
 
 type Tournament struct {
-	ID     *uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primary_key"`
+	ID     *uuid.UUID `gorm:"type:uuid;primary_key"`
 	Name   string     `gorm:"not null"`
 	Size   int        `gorm:"not null"`
 	UserID *uuid.UUID `gorm:"not null"`
 	User   *User      `gorm:"foreignKey:UserID"`
+	Hidden bool       `gorm:"not null;default:false"`
+	// UpvoteMilestone is highest count of upvotes owner was notified about
+	UpvoteMilestone int64 `gorm:"not null;default:0" json:"-"`
+
+	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP"`
 }
 
 type CreateTournament struct {
 	Name    string         `validate:"required"`
 	Size    int            `validate:"gte=4,lte=64"`
-	Tiktoks []CreateTiktok `validate:"required"`
+	Tiktoks []CreateTiktok `validate:"required,dive"`
+}
+
+// ValidateCreateTournament checks tournament payload with validation tags
+// and checks that count of tiktoks matches tournament size
+func ValidateCreateTournament(payload *CreateTournament) error {
+	err := ValidateStruct(payload)
+	if err != nil {
+		return err
+	}
+	if payload.Size != len(payload.Tiktoks) {
+		return ValidationErrors{{
+			Field: "Tiktoks",
+			Rule:  "len",
+			Param: strconv.Itoa(payload.Size),
+			Message: fmt.Sprintf("Tournament size and count of tiktoks mismatch (%d != %d)",
+				payload.Size, len(payload.Tiktoks)),
+		}}
+	}
+	return nil
 }
 
 type Bracket struct {
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+// TournamentExport is tournament with its tiktoks and their statistics as written to export file.
+// JSON export can be imported back as CreateTournament, statistics are ignored on import
+type TournamentExport struct {
+	Name    string
+	Size    int
+	Tiktoks []TiktokExport
+}
+
+type TiktokExport struct {
+	URL         string
+	Wins        int
+	AvgPoints   float64
+	TimesPlayed int
+}
+
+func NewTournamentExport(tournament *Tournament, tiktoks []Tiktok) TournamentExport {
+	export := TournamentExport{
+		Name:    tournament.Name,
+		Size:    tournament.Size,
+		Tiktoks: make([]TiktokExport, 0, len(tiktoks)),
+	}
+	for _, tiktok := range tiktoks {
+		export.Tiktoks = append(export.Tiktoks, TiktokExport{
+			URL:         tiktok.URL,
+			Wins:        tiktok.Wins,
+			AvgPoints:   tiktok.AvgPoints,
+			TimesPlayed: tiktok.TimesPlayed,
+		})
+	}
+	return export
+}
+
+// ImportRowError is error in one row of import file. Row 0 refers to tournament itself,
+// tiktoks are numbered from 1 in JSON and by line in CSV, where line 1 is header
+type ImportRowError struct {
+	Row     int
+	Message string
+}
+
+const (
+	TransferFormatJSON = "json"
+	TransferFormatCSV  = "csv"
+)
+
+func GetAllowedTransferFormats() map[string]bool {
+	return map[string]bool{
+		TransferFormatJSON: true,
+		TransferFormatCSV:  true,
+	}
+}
+
+func CheckIfAllowedTransferFormat(format string) bool {
+	return GetAllowedTransferFormats()[format]
+}
+
+// ValidateTournamentImport checks imported tournament with the same rules as ValidateCreateTournament,
+// but reports every invalid tiktok at its own row. rows holds file row of every tiktok
+func ValidateTournamentImport(payload *CreateTournament, rows []int) []ImportRowError {
+	err := ValidateCreateTournament(payload)
+	if err == nil {
+		return nil
+	}
+	var fieldErrs ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return []ImportRowError{{Row: 0, Message: err.Error()}}
+	}
+
+	var errs []ImportRowError
+	for _, fieldErr := range fieldErrs {
+		row := 0
+		var index int
+		if _, scanErr := fmt.Sscanf(fieldErr.Field, "Tiktoks[%d]", &index); scanErr == nil && index < len(rows) {
+			row = rows[index]
+		}
+		errs = append(errs, ImportRowError{Row: row, Message: fieldErr.Message})
+	}
+	return errs
+}
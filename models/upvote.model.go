@@ -0,0 +1,19 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// TournamentUpvote is upvote given by user to tournament, user can upvote each tournament once
+type TournamentUpvote struct {
+	TournamentID *uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	Tournament   *Tournament `gorm:"foreignKey:TournamentID" json:"-"`
+	UserID       *uuid.UUID  `gorm:"type:uuid;primaryKey;index"`
+	User         *User       `gorm:"foreignKey:UserID" json:"-"`
+	CreatedAt    time.Time
+}
+
+type TournamentUpvotes struct {
+	Upvotes int64
+}
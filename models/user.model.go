@@ -2,12 +2,39 @@ package models
 
 import (
 	"github.com/google/uuid"
+	"time"
 )
 
 type User struct {
-	ID       *uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primary_key"`
+	ID       *uuid.UUID `gorm:"type:uuid;primary_key"`
 	Name     string     `gorm:"not null"`
 	Password string     `gorm:"not null"`
+
+	Role              string `gorm:"not null;default:user"`
+	Banned            bool   `gorm:"not null;default:false"`
+	MustResetPassword bool   `gorm:"not null;default:false"`
+	//	JWT sessions issued before this time are rejected
+	SessionsRevokedAt *time.Time `json:"-"`
+
+	Email         *string `gorm:"uniqueIndex" json:"-"`
+	EmailVerified bool    `gorm:"not null;default:false"`
+
+	DisplayName string    `gorm:"not null;default:''"`
+	Bio         string    `gorm:"not null;default:''"`
+	AvatarURL   string    `gorm:"not null;default:''"`
+	CreatedAt   time.Time `gorm:"not null;default:CURRENT_TIMESTAMP"`
+
+	TOTPSecret   string `gorm:"column:totp_secret" json:"-"`
+	TOTPEnabled  bool   `gorm:"column:totp_enabled;not null;default:false"`
+	TOTPLastStep int64  `gorm:"column:totp_last_step;not null;default:0"`
+}
+
+type RecoveryCode struct {
+	ID       *uuid.UUID `gorm:"type:uuid;primary_key"`
+	UserID   *uuid.UUID `gorm:"not null;index"`
+	User     *User      `gorm:"foreignKey:UserID"`
+	CodeHash string     `gorm:"not null" json:"-"`
+	UsedAt   *time.Time
 }
 
 type AuthInput struct {
@@ -18,5 +45,101 @@ type AuthInput struct {
 type UserAuthDetails struct {
 	ID       string
 	Username string
+	Role     string
 	Token    string
 }
+
+type ChangePasswordInput struct {
+	Name        string `validate:"required"`
+	Password    string `validate:"required"`
+	NewPassword string `validate:"required"`
+	Code        string
+}
+
+type EmailInput struct {
+	Email string `validate:"required,email"`
+}
+
+type EmailVerificationInput struct {
+	Token string `validate:"required"`
+}
+
+type ForgotPasswordInput struct {
+	Email string `validate:"required,email"`
+}
+
+type ResetPasswordInput struct {
+	Token       string `validate:"required"`
+	NewPassword string `validate:"required"`
+}
+
+// UserAuthState is checked on every authenticated request, so changes of role, bans
+// and revoked sessions take effect before tokens expire
+type UserAuthState struct {
+	Role              string
+	Banned            bool
+	SessionsRevokedAt *time.Time
+}
+
+type UserSummary struct {
+	ID                *uuid.UUID
+	Name              string
+	Role              string
+	Banned            bool
+	MustResetPassword bool
+	TOTPEnabled       bool
+}
+
+type UsersPage struct {
+	Users []UserSummary
+	Total int64
+}
+
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+func GetAllowedRoles() map[string]bool {
+	return map[string]bool{
+		RoleUser:      true,
+		RoleModerator: true,
+		RoleAdmin:     true,
+	}
+}
+
+func CheckIfAllowedRole(role string) bool {
+	return GetAllowedRoles()[role]
+}
+
+// CanModerate reports whether role can see hidden content and handle reports
+func CanModerate(role string) bool {
+	return role == RoleModerator || role == RoleAdmin
+}
+
+// CanBan reports whether user with actorRole can ban user with targetRole,
+// moderators and administrators can be banned only by administrators
+func CanBan(actorRole string, targetRole string) bool {
+	return actorRole == RoleAdmin || !CanModerate(targetRole)
+}
+
+type TwoFactorChallenge struct {
+	ChallengeToken string
+	ExpiresIn      int
+}
+
+type TwoFactorLoginInput struct {
+	ChallengeToken string `validate:"required"`
+	Code           string `validate:"required"`
+}
+
+type TwoFactorCodeInput struct {
+	Code string `validate:"required"`
+}
+
+type TwoFactorEnrollment struct {
+	Secret        string
+	URI           string
+	RecoveryCodes []string
+}
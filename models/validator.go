@@ -1,10 +1,117 @@
 package models
 
-import "github.com/go-playground/validator/v10"
+import (
+	"errors"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"net/url"
+	"reflect"
+	"strings"
+)
 
-var validate = validator.New()
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	err := v.RegisterValidation("http_url", isHTTPURL)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// isHTTPURL accepts absolute http and https URLs. Built-in url rule accepts any scheme,
+// e.g. javascript: URLs, which must not end up in links rendered by frontend
+func isHTTPURL(fl validator.FieldLevel) bool {
+	parsed, err := url.Parse(fl.Field().String())
+	if err != nil || parsed.Host == "" {
+		return false
+	}
+	return parsed.Scheme == "http" || parsed.Scheme == "https"
+}
+
+// FieldError describes one invalid field of payload, Field is path from payload root, e.g. Tiktoks[3].URL
+type FieldError struct {
+	Field   string
+	Rule    string
+	Param   string
+	Message string
+}
+
+// ValidationErrors is returned by ValidateStruct when payload has invalid fields
+type ValidationErrors []FieldError
+
+func (errs ValidationErrors) Error() string {
+	messages := make([]string, len(errs))
+	for i, fieldErr := range errs {
+		messages[i] = fieldErr.Message
+	}
+	return strings.Join(messages, "; ")
+}
 
 func ValidateStruct[T any](payload T) error {
 	err := validate.Struct(payload)
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		return translateValidationErrors(validationErrs)
+	}
 	return err
 }
+
+func translateValidationErrors(validationErrs validator.ValidationErrors) ValidationErrors {
+	errs := make(ValidationErrors, len(validationErrs))
+	for i, fieldErr := range validationErrs {
+		errs[i] = FieldError{
+			Field:   fieldPath(fieldErr.Namespace()),
+			Rule:    fieldErr.Tag(),
+			Param:   fieldErr.Param(),
+			Message: fieldMessage(fieldErr),
+		}
+	}
+	return errs
+}
+
+// fieldPath strips name of payload struct from namespace, CreateTournament.Tiktoks[3].URL becomes Tiktoks[3].URL
+func fieldPath(namespace string) string {
+	_, path, found := strings.Cut(namespace, ".")
+	if !found {
+		return namespace
+	}
+	return path
+}
+
+func fieldMessage(fieldErr validator.FieldError) string {
+	field := fieldErr.Field()
+	param := fieldErr.Param()
+	switch fieldErr.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", field)
+	case "email":
+		return fmt.Sprintf("%s must be a valid email", field)
+	case "url":
+		return fmt.Sprintf("%s must be a valid URL", field)
+	case "http_url":
+		return fmt.Sprintf("%s must be a valid http or https URL", field)
+	case "uuid":
+		return fmt.Sprintf("%s must be a valid UUID", field)
+	case "min", "gte":
+		return fmt.Sprintf("%s must be at least %s%s", field, param, sizeUnit(fieldErr.Kind()))
+	case "max", "lte":
+		return fmt.Sprintf("%s must be at most %s%s", field, param, sizeUnit(fieldErr.Kind()))
+	}
+	if param != "" {
+		return fmt.Sprintf("%s failed on %s=%s rule", field, fieldErr.Tag(), param)
+	}
+	return fmt.Sprintf("%s failed on %s rule", field, fieldErr.Tag())
+}
+
+// sizeUnit tells what min and max rules count for strings and lists, numbers are compared as is
+func sizeUnit(kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		return " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return " items"
+	}
+	return ""
+}
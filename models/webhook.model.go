@@ -0,0 +1,99 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"strings"
+	"time"
+)
+
+// Webhook is URL registered by user which receives selected events caused by the user or happening on tournaments
+// of the user, deliveries are signed with Secret
+type Webhook struct {
+	ID        *uuid.UUID `gorm:"type:uuid;primary_key"`
+	UserID    *uuid.UUID `gorm:"not null;index"`
+	User      *User      `gorm:"foreignKey:UserID" json:"-"`
+	URL       string     `gorm:"not null"`
+	Secret    string     `gorm:"not null" json:"-"`
+	Events    string     `gorm:"not null"`
+	CreatedAt time.Time
+}
+
+// WebhookDelivery is one event sent to webhook, kept as delivery log
+type WebhookDelivery struct {
+	ID             *uuid.UUID `gorm:"type:uuid;primary_key"`
+	WebhookID      *uuid.UUID `gorm:"not null;index"`
+	Webhook        *Webhook   `gorm:"foreignKey:WebhookID" json:"-"`
+	EventType      string     `gorm:"not null"`
+	Payload        string     `gorm:"not null"`
+	Status         string     `gorm:"not null;index"`
+	Attempts       int        `gorm:"not null;default:0"`
+	NextAttemptAt  *time.Time `gorm:"index"`
+	LastAttemptAt  *time.Time
+	ResponseStatus int
+	LastError      string
+	ReplayOfID     *uuid.UUID `gorm:"type:uuid"`
+	CreatedAt      time.Time
+}
+
+type CreateWebhook struct {
+	URL    string   `validate:"required,url,max=2048"`
+	Events []string `validate:"required,min=1"`
+}
+
+// CreatedWebhook is returned only once after creation, secret can not be retrieved later
+type CreatedWebhook struct {
+	Webhook
+	Secret string
+}
+
+type WebhookDeliveriesPage struct {
+	Deliveries []WebhookDelivery
+	Total      int64
+}
+
+// WebhookPayload is JSON body of webhook delivery, replayed delivery has the same payload as original one
+type WebhookPayload struct {
+	Event     string
+	CreatedAt time.Time
+	Actor     *WebhookPayloadActor   `json:",omitempty"`
+	Target    *WebhookPayloadTarget  `json:",omitempty"`
+	Details   map[string]interface{} `json:",omitempty"`
+}
+
+type WebhookPayloadActor struct {
+	ID   *uuid.UUID
+	Name string
+}
+
+type WebhookPayloadTarget struct {
+	Type string
+	ID   *uuid.UUID
+	Name string
+}
+
+const (
+	WebhookEventTournamentCreated = "tournament.created"
+	WebhookEventContestFinished   = "contest.finished"
+)
+
+func GetAllowedWebhookEvents() map[string]bool {
+	return map[string]bool{
+		WebhookEventTournamentCreated: true,
+		WebhookEventContestFinished:   true,
+	}
+}
+
+func CheckIfAllowedWebhookEvent(event string) bool {
+	return GetAllowedWebhookEvents()[event]
+}
+
+// EventList returns event types webhook is subscribed to
+func (w *Webhook) EventList() []string {
+	return strings.Fields(w.Events)
+}
+
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
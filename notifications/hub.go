@@ -0,0 +1,74 @@
+package notifications
+
+import (
+	"sync"
+	"tiktok-arena/models"
+)
+
+// streamBuffer is count of notifications kept for slow stream, newer notifications are dropped when it is full
+const streamBuffer = 16
+
+// Hub fans out new notifications to live streams of their recipients
+type Hub struct {
+	mu      sync.Mutex
+	streams map[string]map[chan models.Notification]struct{}
+	closed  bool
+}
+
+func NewHub() *Hub {
+	return &Hub{streams: make(map[string]map[chan models.Notification]struct{})}
+}
+
+// Subscribe opens stream of notifications of user, returned function closes it.
+// Stream channel is closed when hub is closed
+func (h *Hub) Subscribe(userId string) (<-chan models.Notification, func()) {
+	stream := make(chan models.Notification, streamBuffer)
+
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		close(stream)
+		return stream, func() {}
+	}
+	if h.streams[userId] == nil {
+		h.streams[userId] = make(map[chan models.Notification]struct{})
+	}
+	h.streams[userId][stream] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return stream, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			delete(h.streams[userId], stream)
+			if len(h.streams[userId]) == 0 {
+				delete(h.streams, userId)
+			}
+		})
+	}
+}
+
+func (h *Hub) Publish(userId string, notification models.Notification) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for stream := range h.streams[userId] {
+		select {
+		case stream <- notification:
+		default:
+		}
+	}
+}
+
+// Close ends all open streams and streams opened later, so long-lived connections do not hold up shutdown
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for _, streams := range h.streams {
+		for stream := range streams {
+			close(stream)
+		}
+	}
+	h.streams = make(map[string]map[chan models.Notification]struct{})
+}
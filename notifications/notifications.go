@@ -0,0 +1,93 @@
+// Package notifications turns published events into notifications of their recipients
+// and pushes new notifications to live streams of connected users
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"golang.org/x/exp/slog"
+	"tiktok-arena/events"
+	"tiktok-arena/models"
+	"tiktok-arena/store"
+)
+
+// Notifier stores notifications of event recipients and pushes them to live streams
+type Notifier struct {
+	Store store.NotificationStore
+	// Live holds streams of connected users, notifications are pushed only to streams of this server instance
+	Live *Hub
+}
+
+func NewNotifier(notificationStore store.NotificationStore) *Notifier {
+	return &Notifier{Store: notificationStore, Live: NewHub()}
+}
+
+// Setup creates notifier and subscribes it to events published on bus
+func Setup(bus *events.Bus, notificationStore store.NotificationStore) *Notifier {
+	notifier := NewNotifier(notificationStore)
+	bus.Subscribe(notifier.handleEvent)
+	return notifier
+}
+
+func (n *Notifier) handleEvent(ctx context.Context, event events.Event) {
+	if event.RecipientID == nil {
+		return
+	}
+	//	Users are not notified about their own actions
+	if event.ActorID != nil && *event.ActorID == *event.RecipientID {
+		return
+	}
+
+	message, ok := eventMessage(&event)
+	if !ok {
+		return
+	}
+
+	notification := models.Notification{
+		UserID:     event.RecipientID,
+		Type:       event.Type,
+		Message:    message,
+		ActorID:    event.ActorID,
+		TargetType: event.TargetType,
+		TargetID:   event.TargetID,
+		CreatedAt:  event.CreatedAt,
+	}
+	err := n.Store.CreateNotification(ctx, &notification)
+	if err != nil {
+		slog.ErrorCtx(ctx, "Failed to create notification", "event", event.Type, "recipient_id", event.RecipientID,
+			"error", err)
+		return
+	}
+	n.Live.Publish(event.RecipientID.String(), notification)
+}
+
+func eventMessage(event *events.Event) (string, bool) {
+	switch event.Type {
+	case events.TournamentUpvoteMilestone:
+		if event.Count == 1 {
+			return fmt.Sprintf("Your tournament %s got its first upvote", event.TargetName), true
+		}
+		return fmt.Sprintf("Your tournament %s reached %d upvotes", event.TargetName, event.Count), true
+	case events.ModerationActionTaken:
+		message := moderationMessage(event)
+		if event.Note != "" {
+			message += ": " + event.Note
+		}
+		return message, true
+	case events.UserFollowed:
+		return fmt.Sprintf("%s started following you", event.ActorName), true
+	}
+	return "", false
+}
+
+func moderationMessage(event *events.Event) string {
+	switch event.Action {
+	case models.ModerationHide:
+		return fmt.Sprintf("Moderator hid your %s %s", event.TargetType, event.TargetName)
+	case models.ModerationWarn:
+		return fmt.Sprintf("Moderator warned you about your %s %s", event.TargetType, event.TargetName)
+	case models.ModerationBanAuthor:
+		return fmt.Sprintf("You were banned because of your %s %s", event.TargetType, event.TargetName)
+	}
+	return fmt.Sprintf("Moderator took action %s on your %s %s", event.Action, event.TargetType, event.TargetName)
+}
@@ -0,0 +1,284 @@
+// Package oidc implements relying party side of OpenID Connect authorization code flow with PKCE
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v4"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"tiktok-arena/configuration"
+	"tiktok-arena/tracing"
+	"time"
+)
+
+// Metadata is part of provider discovery document used by the flow
+type Metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type Provider struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	HTTPClient   *http.Client
+
+	mu       sync.Mutex
+	metadata *Metadata
+	keys     map[string]interface{}
+}
+
+// IDTokenClaims are identity claims from verified ID token
+type IDTokenClaims struct {
+	Issuer            string
+	Subject           string
+	PreferredUsername string
+	Email             string
+}
+
+// Default is provider from configuration, nil if OIDC login is not configured
+var Default *Provider
+
+// Setup creates provider from configuration, discovery document is fetched on first use,
+// so server can start while provider is unavailable
+func Setup(config *configuration.EnvConfigModel) {
+	if config.OIDCIssuer == "" {
+		Default = nil
+		return
+	}
+	Default = &Provider{
+		Issuer:       strings.TrimSuffix(config.OIDCIssuer, "/"),
+		ClientID:     config.OIDCClientID,
+		ClientSecret: config.OIDCClientSecret,
+		RedirectURL:  config.OIDCRedirectURL,
+		Scopes:       strings.Fields(config.OIDCScopes),
+		HTTPClient: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: tracing.Transport(http.DefaultTransport),
+		},
+	}
+}
+
+// AuthCodeURL returns URL of provider login page for authorization code flow with S256 PKCE challenge
+func (p *Provider) AuthCodeURL(ctx context.Context, state string, nonce string, codeVerifier string) (string, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", p.ClientID)
+	query.Set("redirect_uri", p.RedirectURL)
+	query.Set("scope", strings.Join(p.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", CodeChallenge(codeVerifier))
+	query.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(metadata.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return metadata.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// Exchange exchanges authorization code for tokens and returns verified ID token claims
+func (p *Provider) Exchange(ctx context.Context, code string, codeVerifier string, nonce string) (*IDTokenClaims, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.RedirectURL)
+	form.Set("code_verifier", codeVerifier)
+	form.Set("client_id", p.ClientID)
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, metadata.TokenEndpoint,
+		strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+	if p.ClientSecret != "" {
+		request.SetBasicAuth(url.QueryEscape(p.ClientID), url.QueryEscape(p.ClientSecret))
+	}
+
+	var tokenResponse struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	status, err := p.doJSON(request, &tokenResponse)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK || tokenResponse.Error != "" {
+		return nil, fmt.Errorf("token request failed with status %d: %s %s",
+			status, tokenResponse.Error, tokenResponse.ErrorDescription)
+	}
+	if tokenResponse.IDToken == "" {
+		return nil, errors.New("token response has no id_token")
+	}
+	return p.VerifyIDToken(ctx, tokenResponse.IDToken, nonce)
+}
+
+// VerifyIDToken checks ID token signature, issuer, audience, expiration and nonce
+func (p *Provider) VerifyIDToken(ctx context.Context, rawIDToken string, nonce string) (*IDTokenClaims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		switch token.Method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA:
+		default:
+			return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+		}
+		kid, _ := token.Header["kid"].(string)
+		return p.key(ctx, kid)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !claims.VerifyIssuer(p.Issuer, true) {
+		return nil, errors.New("ID token has unexpected issuer")
+	}
+	if !claims.VerifyAudience(p.ClientID, true) {
+		return nil, errors.New("ID token has unexpected audience")
+	}
+	if _, ok := claims["exp"]; !ok {
+		return nil, errors.New("ID token has no expiration")
+	}
+	if claims["nonce"] != nonce {
+		return nil, errors.New("ID token has unexpected nonce")
+	}
+
+	subject, _ := claims["sub"].(string)
+	if subject == "" {
+		return nil, errors.New("ID token has no subject")
+	}
+	preferredUsername, _ := claims["preferred_username"].(string)
+	email, _ := claims["email"].(string)
+	return &IDTokenClaims{
+		Issuer:            p.Issuer,
+		Subject:           subject,
+		PreferredUsername: preferredUsername,
+		Email:             email,
+	}, nil
+}
+
+func (p *Provider) discover(ctx context.Context) (*Metadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		p.Issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+	var metadata Metadata
+	status, err := p.doJSON(request, &metadata)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("discovery request failed with status %d", status)
+	}
+	if strings.TrimSuffix(metadata.Issuer, "/") != p.Issuer {
+		return nil, fmt.Errorf("discovery document issuer %s does not match %s", metadata.Issuer, p.Issuer)
+	}
+	p.metadata = &metadata
+	return p.metadata, nil
+}
+
+// key returns provider signing key by kid, key set is refetched when kid is unknown to support provider key rotation
+func (p *Provider) key(ctx context.Context, kid string) (interface{}, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, metadata.JWKSURI, nil)
+	if err != nil {
+		return nil, err
+	}
+	var keySet jsonWebKeySet
+	status, err := p.doJSON(request, &keySet)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("key set request failed with status %d", status)
+	}
+	p.keys = keySet.publicKeys()
+
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+	// Providers with single key may omit kid
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown provider key id %s", kid)
+}
+
+func (p *Provider) doJSON(request *http.Request, target interface{}) (int, error) {
+	response, err := p.HTTPClient.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(response.Body, 1<<20))
+	if err != nil {
+		return response.StatusCode, err
+	}
+	if len(body) > 0 {
+		err = json.Unmarshal(body, target)
+		if err != nil && response.StatusCode == http.StatusOK {
+			return response.StatusCode, err
+		}
+	}
+	return response.StatusCode, nil
+}
+
+// RandomString returns URL safe random string, used for state, nonce and PKCE code verifier
+func RandomString() (string, error) {
+	random := make([]byte, 32)
+	_, err := rand.Read(random)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(random), nil
+}
+
+// CodeChallenge returns S256 PKCE challenge for code verifier
+func CodeChallenge(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,108 @@
+package router_test
+
+import (
+	"net/http"
+	"testing"
+	"tiktok-arena/models"
+)
+
+const unknownId = "00000000-0000-0000-0000-000000000000"
+
+func TestAdminEndpointsRequireAdminRole(t *testing.T) {
+	api := newTestAPI(t, nil)
+	alice := api.register("alice")
+
+	api.expectProblem(http.StatusUnauthorized, "missing_token", http.MethodGet, "/api/admin/users", "", nil)
+	api.expectProblem(http.StatusForbidden, "insufficient_permissions", http.MethodGet, "/api/admin/users",
+		alice.Token, nil)
+	api.setRole(alice.ID, models.RoleModerator)
+	api.expectProblem(http.StatusForbidden, "insufficient_permissions", http.MethodGet, "/api/admin/users",
+		alice.Token, nil)
+
+	//	Role is read from store on every request, existing token gains and loses permissions immediately
+	api.setRole(alice.ID, models.RoleAdmin)
+	api.expect(http.StatusOK, http.MethodGet, "/api/admin/users", alice.Token, nil, &models.UsersPage{})
+	api.setRole(alice.ID, models.RoleUser)
+	api.expectProblem(http.StatusForbidden, "insufficient_permissions", http.MethodGet, "/api/admin/users",
+		alice.Token, nil)
+
+	var apiToken models.CreatedAPIToken
+	api.setRole(alice.ID, models.RoleAdmin)
+	api.expect(http.StatusCreated, http.MethodPost, "/api/auth/tokens", alice.Token,
+		models.CreateAPIToken{Name: "reader", Scopes: []string{models.ScopeRead}}, &apiToken)
+	api.expectProblem(http.StatusForbidden, "insufficient_permissions", http.MethodGet, "/api/admin/users",
+		apiToken.Token, nil)
+}
+
+func TestAdminSearchesUsers(t *testing.T) {
+	api := newTestAPI(t, nil)
+	admin := api.register("admin")
+	api.setRole(admin.ID, models.RoleAdmin)
+	api.register("alice")
+	api.register("alicia")
+	api.register("bob")
+
+	var page models.UsersPage
+	api.expect(http.StatusOK, http.MethodGet, "/api/admin/users?search=ALI&count=1", admin.Token, nil, &page)
+	if page.Total != 2 || len(page.Users) != 1 || page.Users[0].Name != "alice" {
+		t.Fatalf("first page of users matching ALI is %+v with total %d, expected alice of 2", page.Users, page.Total)
+	}
+	api.expect(http.StatusOK, http.MethodGet, "/api/admin/users?search=ALI&count=1&page=2", admin.Token, nil, &page)
+	if len(page.Users) != 1 || page.Users[0].Name != "alicia" {
+		t.Fatalf("second page of users matching ALI is %+v, expected alicia", page.Users)
+	}
+}
+
+func TestAdminBansAndUnbansUser(t *testing.T) {
+	api := newTestAPI(t, nil)
+	admin := api.register("admin")
+	api.setRole(admin.ID, models.RoleAdmin)
+	bob := api.register("bob")
+
+	api.expectProblem(http.StatusForbidden, "self_ban", http.MethodPost, "/api/admin/users/"+admin.ID+"/ban",
+		admin.Token, nil)
+	api.expectProblem(http.StatusNotFound, "user_not_found", http.MethodPost, "/api/admin/users/"+unknownId+"/ban",
+		admin.Token, nil)
+
+	api.expect(http.StatusOK, http.MethodPost, "/api/admin/users/"+bob.ID+"/ban", admin.Token, nil, nil)
+	api.expectProblem(http.StatusForbidden, "user_banned", http.MethodGet, "/api/auth/whoami", bob.Token, nil)
+	api.expectProblem(http.StatusForbidden, "user_banned", http.MethodPost, "/api/auth/login", "",
+		models.AuthInput{Name: "bob", Password: testPassword})
+
+	api.expect(http.StatusOK, http.MethodPost, "/api/admin/users/"+bob.ID+"/unban", admin.Token, nil, nil)
+	api.expect(http.StatusOK, http.MethodGet, "/api/auth/whoami", bob.Token, nil, &models.UserAuthDetails{})
+}
+
+func TestAdminForcesPasswordReset(t *testing.T) {
+	api := newTestAPI(t, nil)
+	admin := api.register("admin")
+	api.setRole(admin.ID, models.RoleAdmin)
+	bob := api.register("bob")
+
+	api.expect(http.StatusOK, http.MethodPost, "/api/admin/users/"+bob.ID+"/reset-password", admin.Token, nil, nil)
+	api.expectProblem(http.StatusForbidden, "password_reset_required", http.MethodPost, "/api/auth/login", "",
+		models.AuthInput{Name: "bob", Password: testPassword})
+
+	var page models.UsersPage
+	api.expect(http.StatusOK, http.MethodGet, "/api/admin/users?search=bob", admin.Token, nil, &page)
+	if len(page.Users) != 1 || !page.Users[0].MustResetPassword {
+		t.Fatalf("bob is listed as %+v, expected to be required to reset password", page.Users)
+	}
+}
+
+func TestAdminDeletesAnyTournament(t *testing.T) {
+	api := newTestAPI(t, nil)
+	admin := api.register("admin")
+	api.setRole(admin.ID, models.RoleAdmin)
+	bob := api.register("bob")
+	tournament := api.createTournament(bob.Token, "cats", 4)
+
+	api.expectProblem(http.StatusForbidden, "insufficient_permissions", http.MethodDelete,
+		"/api/admin/tournaments/"+tournament.ID.String(), bob.Token, nil)
+	api.expect(http.StatusOK, http.MethodDelete, "/api/admin/tournaments/"+tournament.ID.String(), admin.Token,
+		nil, nil)
+	api.expectProblem(http.StatusNotFound, "tournament_not_found", http.MethodGet,
+		"/api/tournament/"+tournament.ID.String(), "", nil)
+	api.expectProblem(http.StatusNotFound, "tournament_not_found", http.MethodDelete,
+		"/api/admin/tournaments/"+tournament.ID.String(), admin.Token, nil)
+}
@@ -0,0 +1,340 @@
+package router_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"tiktok-arena/models"
+	"time"
+)
+
+func TestRegisterAndLogin(t *testing.T) {
+	api := newTestAPI(t, nil)
+
+	alice := api.register("alice")
+	if alice.Username != "alice" || alice.Role != models.RoleUser || alice.Token == "" {
+		t.Fatalf("unexpected auth details of registered user: %+v", alice)
+	}
+	api.expectProblem(http.StatusConflict, "user_exists", http.MethodPost, "/api/auth/register", "",
+		models.AuthInput{Name: "alice", Password: testPassword})
+
+	api.expectProblem(http.StatusUnauthorized, "invalid_credentials", http.MethodPost, "/api/auth/login", "",
+		models.AuthInput{Name: "alice", Password: "Wrong-horse-battery-9"})
+
+	var login models.UserAuthDetails
+	api.expect(http.StatusOK, http.MethodPost, "/api/auth/login", "",
+		models.AuthInput{Name: "alice", Password: testPassword}, &login)
+	if login.ID != alice.ID || login.Token == "" {
+		t.Fatalf("login returned %+v, expected alice %s with token", login, alice.ID)
+	}
+
+	var whoAmI models.UserAuthDetails
+	api.expect(http.StatusOK, http.MethodGet, "/api/auth/whoami", login.Token, nil, &whoAmI)
+	if whoAmI.ID != alice.ID {
+		t.Fatalf("token belongs to %s, expected alice %s", whoAmI.ID, alice.ID)
+	}
+	api.expectProblem(http.StatusUnauthorized, "missing_token", http.MethodGet, "/api/auth/whoami", "", nil)
+}
+
+func TestCreateListAndGetTournament(t *testing.T) {
+	api := newTestAPI(t, nil)
+	alice := api.register("alice")
+
+	created := api.createTournament(alice.Token, "cats", 4)
+	if created.UserID.String() != alice.ID || created.Size != 4 {
+		t.Fatalf("unexpected created tournament: %+v", created)
+	}
+
+	var anonymousList []models.Tournament
+	api.expect(http.StatusOK, http.MethodGet, "/api/tournament", "", nil, &anonymousList)
+	if len(anonymousList) != 1 || *anonymousList[0].ID != *created.ID {
+		t.Fatalf("anonymous list has %d tournaments, expected only cats", len(anonymousList))
+	}
+
+	var details models.Tournament
+	api.expect(http.StatusOK, http.MethodGet, "/api/tournament/"+created.ID.String(), "", nil, &details)
+	if details.Name != "cats" {
+		t.Fatalf("got tournament %s, expected cats", details.Name)
+	}
+
+	var tiktoks []models.Tiktok
+	api.expect(http.StatusOK, http.MethodGet, "/api/tournament/"+created.ID.String()+"/tiktoks", "", nil, &tiktoks)
+	if len(tiktoks) != 4 {
+		t.Fatalf("tournament has %d tiktoks, expected 4", len(tiktoks))
+	}
+
+	api.expectProblem(http.StatusUnauthorized, "missing_token", http.MethodPost, "/api/tournament", "",
+		models.CreateTournament{Name: "dogs", Size: 4})
+	api.expectProblem(http.StatusNotFound, "tournament_not_found", http.MethodGet,
+		"/api/tournament/00000000-0000-0000-0000-000000000000", "", nil)
+}
+
+func TestContestBracketAndResult(t *testing.T) {
+	api := newTestAPI(t, nil)
+	alice := api.register("alice")
+	bob := api.register("bob")
+	tournament := api.createTournament(alice.Token, "cats", 8)
+	contestPath := "/api/tournament/" + tournament.ID.String() + "/contest"
+
+	var bracket models.Bracket
+	api.expect(http.StatusOK, http.MethodGet, contestPath+"?type="+models.SingleElimination, "", nil, &bracket)
+	if bracket.CountMatches != 7 || len(bracket.Rounds) != 3 {
+		t.Fatalf("single elimination of 8 tiktoks has %d matches in %d rounds, expected 7 in 3",
+			bracket.CountMatches, len(bracket.Rounds))
+	}
+	api.expectProblem(http.StatusBadRequest, "invalid_contest_type", http.MethodGet, contestPath+"?type=round_robin",
+		"", nil)
+
+	result := models.ContestResult{
+		ContestType: models.KingOfTheHill,
+		WinnerURL:   tiktokURL("cats", 0),
+		Tiktoks: []models.ContestTiktokResult{
+			{URL: tiktokURL("cats", 0), Points: 3},
+			{URL: tiktokURL("cats", 1), Points: 1},
+		},
+	}
+	api.expect(http.StatusCreated, http.MethodPost, contestPath, bob.Token, result, &models.Contest{})
+	api.expect(http.StatusCreated, http.MethodPost, contestPath, alice.Token, result, &models.Contest{})
+
+	var tiktoks []models.Tiktok
+	api.expect(http.StatusOK, http.MethodGet, "/api/tournament/"+tournament.ID.String()+"/tiktoks", "", nil, &tiktoks)
+	for _, tiktok := range tiktoks {
+		if tiktok.URL == result.WinnerURL && tiktok.Wins != 2 {
+			t.Fatalf("winner has %d wins, expected 2", tiktok.Wins)
+		}
+	}
+
+	unknown := result
+	unknown.WinnerURL = "https://www.tiktok.com/@dogs/video/1"
+	carol := api.register("carol")
+	api.expectProblem(http.StatusBadRequest, "unknown_winner", http.MethodPost, contestPath, carol.Token, unknown)
+	api.expectProblem(http.StatusUnauthorized, "missing_token", http.MethodPost, contestPath, "", result)
+}
+
+func TestUpvoteTournament(t *testing.T) {
+	api := newTestAPI(t, nil)
+	alice := api.register("alice")
+	bob := api.register("bob")
+	tournament := api.createTournament(alice.Token, "cats", 4)
+	upvotePath := "/api/tournament/" + tournament.ID.String() + "/upvote"
+
+	var upvotes models.TournamentUpvotes
+	api.expect(http.StatusOK, http.MethodPost, upvotePath, bob.Token, nil, &upvotes)
+	api.expect(http.StatusOK, http.MethodPost, upvotePath, bob.Token, nil, &upvotes)
+	if upvotes.Upvotes != 1 {
+		t.Fatalf("repeated upvote gave %d upvotes, expected 1", upvotes.Upvotes)
+	}
+	api.expectProblem(http.StatusForbidden, "own_tournament", http.MethodPost, upvotePath, alice.Token, nil)
+
+	//	Removing and giving upvote again brings tournament back to first milestone, owner is notified only once
+	api.expect(http.StatusOK, http.MethodDelete, upvotePath, bob.Token, nil, &upvotes)
+	if upvotes.Upvotes != 0 {
+		t.Fatalf("removed upvote left %d upvotes, expected 0", upvotes.Upvotes)
+	}
+	api.expect(http.StatusOK, http.MethodPost, upvotePath, bob.Token, nil, &upvotes)
+
+	var unread models.UnreadNotifications
+	api.expect(http.StatusOK, http.MethodGet, "/api/notifications/unread-count", alice.Token, nil, &unread)
+	if unread.Unread != 1 {
+		t.Fatalf("owner has %d unread notifications, expected 1 for first upvote milestone", unread.Unread)
+	}
+}
+
+func TestNotificationsCanBeMarkedReadOnlyInSession(t *testing.T) {
+	api := newTestAPI(t, nil)
+	alice := api.register("alice")
+
+	var apiToken models.CreatedAPIToken
+	api.expect(http.StatusCreated, http.MethodPost, "/api/auth/tokens", alice.Token,
+		models.CreateAPIToken{Name: "reader", Scopes: []string{models.ScopeRead}}, &apiToken)
+
+	api.expect(http.StatusOK, http.MethodGet, "/api/notifications", apiToken.Token, nil, &models.NotificationsPage{})
+	api.expectProblem(http.StatusForbidden, "insufficient_permissions", http.MethodPost, "/api/notifications/read-all",
+		apiToken.Token, nil)
+	api.expect(http.StatusOK, http.MethodPost, "/api/notifications/read-all", alice.Token, nil, nil)
+}
+
+func TestWebhookReceivesOnlyEventsOfOwner(t *testing.T) {
+	api := newTestAPI(t, nil)
+	alice := api.register("alice")
+	bob := api.register("bob")
+
+	var webhook models.CreatedWebhook
+	api.expect(http.StatusCreated, http.MethodPost, "/api/webhooks", alice.Token, models.CreateWebhook{
+		URL:    "https://hooks.example.com/arena",
+		Events: []string{models.WebhookEventTournamentCreated, models.WebhookEventContestFinished},
+	}, &webhook)
+	deliveries := func() []string {
+		var page models.WebhookDeliveriesPage
+		api.expect(http.StatusOK, http.MethodGet, "/api/webhooks/"+webhook.ID.String()+"/deliveries",
+			alice.Token, nil, &page)
+		events := make([]string, len(page.Deliveries))
+		for i, delivery := range page.Deliveries {
+			events[i] = delivery.EventType
+		}
+		return events
+	}
+
+	api.createTournament(bob.Token, "dogs", 4)
+	if events := deliveries(); len(events) != 0 {
+		t.Fatalf("tournament of bob was delivered to webhook of alice: %v", events)
+	}
+
+	cats := api.createTournament(alice.Token, "cats", 4)
+	api.expect(http.StatusCreated, http.MethodPost, "/api/tournament/"+cats.ID.String()+"/contest", bob.Token,
+		models.ContestResult{
+			ContestType: models.KingOfTheHill,
+			WinnerURL:   tiktokURL("cats", 0),
+			Tiktoks:     []models.ContestTiktokResult{{URL: tiktokURL("cats", 0)}, {URL: tiktokURL("cats", 1)}},
+		}, &models.Contest{})
+	events := deliveries()
+	if len(events) != 2 || events[0] != models.WebhookEventContestFinished {
+		t.Fatalf("expected contest on tournament of alice and her tournament delivered, got %v", events)
+	}
+}
+
+func TestPasswordResetRevokesSessionsAndAPITokens(t *testing.T) {
+	api := newTestAPI(t, nil)
+	alice := api.register("alice")
+	api.expect(http.StatusOK, http.MethodPut, "/api/auth/email", alice.Token,
+		models.EmailInput{Email: "alice@example.com"}, nil)
+	api.expect(http.StatusOK, http.MethodPost, "/api/auth/email/verify", "",
+		models.EmailVerificationInput{Token: api.linkToken("alice@example.com")}, nil)
+
+	var apiToken models.CreatedAPIToken
+	api.expect(http.StatusCreated, http.MethodPost, "/api/auth/tokens", alice.Token,
+		models.CreateAPIToken{Name: "reader", Scopes: []string{models.ScopeRead}}, &apiToken)
+
+	//	iat of session has second precision, sessions issued in the same second as reset are kept
+	time.Sleep(time.Second)
+	api.expect(http.StatusOK, http.MethodPost, "/api/auth/password/forgot", "",
+		models.ForgotPasswordInput{Email: "alice@example.com"}, nil)
+	api.expect(http.StatusOK, http.MethodPost, "/api/auth/password/reset", "", models.ResetPasswordInput{
+		Token:       api.linkToken("alice@example.com"),
+		NewPassword: "Another-horse-battery-7",
+	}, nil)
+
+	api.expectProblem(http.StatusUnauthorized, "session_revoked", http.MethodGet, "/api/auth/whoami", alice.Token, nil)
+	api.expectProblem(http.StatusUnauthorized, "token_revoked", http.MethodGet, "/api/auth/whoami", apiToken.Token, nil)
+	api.expect(http.StatusOK, http.MethodPost, "/api/auth/login", "",
+		models.AuthInput{Name: "alice", Password: "Another-horse-battery-7"}, &models.UserAuthDetails{})
+}
+
+func TestForgotPasswordIsThrottledPerEmail(t *testing.T) {
+	api := newTestAPI(t, map[string]string{"PASSWORD_RESET_EMAIL_FREE_REQUESTS": "2"})
+
+	//	Unknown emails are throttled too, so response does not tell whether account exists
+	for i := 0; i < 2; i++ {
+		api.expect(http.StatusOK, http.MethodPost, "/api/auth/password/forgot", "",
+			models.ForgotPasswordInput{Email: "nobody@example.com"}, nil)
+	}
+	api.expectProblem(http.StatusTooManyRequests, "too_many_requests", http.MethodPost, "/api/auth/password/forgot",
+		"", models.ForgotPasswordInput{Email: "Nobody@Example.com"})
+	api.expect(http.StatusOK, http.MethodPost, "/api/auth/password/forgot", "",
+		models.ForgotPasswordInput{Email: "somebody@example.com"}, nil)
+}
+
+func TestEmailEndpointsAreUnavailableWithDisabledMailer(t *testing.T) {
+	api := newTestAPI(t, map[string]string{"APP_ENV": "production", "MAILER": "disabled"})
+	alice := api.register("alice")
+	admin := api.register("admin")
+	api.setRole(admin.ID, models.RoleAdmin)
+
+	api.expectProblem(http.StatusServiceUnavailable, "email_disabled", http.MethodPut, "/api/auth/email", alice.Token,
+		models.EmailInput{Email: "alice@example.com"})
+	api.expectProblem(http.StatusServiceUnavailable, "email_disabled", http.MethodPost, "/api/auth/email/resend",
+		alice.Token, nil)
+	api.expectProblem(http.StatusServiceUnavailable, "email_disabled", http.MethodPost, "/api/auth/password/forgot",
+		"", models.ForgotPasswordInput{Email: "alice@example.com"})
+
+	//	Administrator can still force password reset, user changes password on next login
+	api.expect(http.StatusOK, http.MethodPost, "/api/admin/users/"+alice.ID+"/reset-password", admin.Token, nil, nil)
+}
+
+func TestNotificationStreamClosesWhenUserIsBanned(t *testing.T) {
+	api := newTestAPI(t, map[string]string{"NOTIFICATION_STREAM_KEEP_ALIVE": "20ms"})
+	alice := api.register("alice")
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		_ = api.store.SetUserBanned(context.Background(), alice.ID, true)
+	}()
+
+	//	Response ends only when stream is closed
+	request := httptest.NewRequest(http.MethodGet, "/api/notifications/stream?token="+alice.Token, nil)
+	response, err := api.app.Test(request, 5000)
+	if err != nil {
+		t.Fatalf("stream was not closed after user was banned: %v", err)
+	}
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(body), "event: unread\n") || !strings.Contains(string(body), ": keep-alive\n") {
+		t.Fatalf("unexpected stream: %q", body)
+	}
+}
+
+func TestMalformedIdsAreRejected(t *testing.T) {
+	api := newTestAPI(t, nil)
+	admin := api.register("admin")
+	api.setRole(admin.ID, models.RoleAdmin)
+
+	var webhook models.CreatedWebhook
+	api.expect(http.StatusCreated, http.MethodPost, "/api/webhooks", admin.Token, models.CreateWebhook{
+		URL:    "https://hooks.example.com/arena",
+		Events: []string{models.WebhookEventTournamentCreated},
+	}, &webhook)
+
+	for _, request := range []struct {
+		method string
+		path   string
+	}{
+		{http.MethodPost, "/api/admin/users/not-an-id/ban"},
+		{http.MethodPost, "/api/admin/users/not-an-id/unban"},
+		{http.MethodPost, "/api/admin/users/not-an-id/reset-password"},
+		{http.MethodDelete, "/api/auth/tokens/not-an-id"},
+		{http.MethodDelete, "/api/webhooks/not-an-id"},
+		{http.MethodGet, "/api/webhooks/not-an-id/deliveries"},
+		{http.MethodPost, "/api/webhooks/" + webhook.ID.String() + "/deliveries/not-an-id/replay"},
+		{http.MethodPost, "/api/notifications/not-an-id/read"},
+		{http.MethodPost, "/api/moderation/reports/not-an-id/resolve"},
+		{http.MethodPost, "/api/moderation/reports/not-an-id/dismiss"},
+		{http.MethodPost, "/api/moderation/reports/not-an-id/action"},
+	} {
+		var body interface{}
+		if strings.HasSuffix(request.path, "/action") {
+			body = models.ModerationInput{Action: models.ModerationHide}
+		}
+		api.expectProblem(http.StatusBadRequest, "invalid_id", request.method, request.path, admin.Token, body)
+	}
+}
+
+func TestValidationErrorsHaveFieldPaths(t *testing.T) {
+	api := newTestAPI(t, nil)
+	alice := api.register("alice")
+
+	var problem struct {
+		Code   string
+		Errors []models.FieldError
+	}
+	status := api.do(http.MethodPost, "/api/tournament", alice.Token, models.CreateTournament{
+		Size: 4,
+		Tiktoks: []models.CreateTiktok{{URL: tiktokURL("paths", 0)}, {}, {URL: tiktokURL("paths", 2)},
+			{URL: "ftp://example.com/video"}},
+	}, &problem)
+	expected := []models.FieldError{
+		{Field: "Name", Rule: "required", Message: "Name is required"},
+		{Field: "Tiktoks[1].URL", Rule: "required", Message: "URL is required"},
+		{Field: "Tiktoks[3].URL", Rule: "http_url", Message: "URL must be a valid http or https URL"},
+	}
+	if status != http.StatusBadRequest || problem.Code != "validation_failed" ||
+		!reflect.DeepEqual(problem.Errors, expected) {
+		t.Fatalf("responded %d %+v, expected validation_failed with %+v", status, problem, expected)
+	}
+}
@@ -0,0 +1,78 @@
+package router_test
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"tiktok-arena/models"
+)
+
+const wrongPassword = "Wrong-horse-battery-9"
+
+// login sends login request with X-Forwarded-For header unless forwardedFor is empty,
+// returns status and Retry-After header of response
+func (a *testAPI) login(name string, password string, forwardedFor string) (int, string) {
+	a.t.Helper()
+	headers := map[string]string{}
+	if forwardedFor != "" {
+		headers["X-Forwarded-For"] = forwardedFor
+	}
+	response := a.send(http.MethodPost, "/api/auth/login", "", models.AuthInput{Name: name, Password: password},
+		headers)
+	defer response.Body.Close()
+	return response.StatusCode, response.Header.Get("Retry-After")
+}
+
+func TestLoginIsThrottledPerUser(t *testing.T) {
+	api := newTestAPI(t, map[string]string{"LOGIN_USER_FREE_ATTEMPTS": "2", "LOGIN_BACKOFF_BASE": "1m"})
+	api.register("alice")
+
+	for i := 0; i < 2; i++ {
+		if status, _ := api.login("alice", wrongPassword, ""); status != http.StatusUnauthorized {
+			t.Fatalf("failed login %d got status %d, expected 401", i+1, status)
+		}
+	}
+
+	//	Locked out user can not log in even with correct password, username is matched regardless of case
+	status, retryAfter := api.login("ALICE", testPassword, "")
+	seconds, err := strconv.Atoi(retryAfter)
+	if status != http.StatusTooManyRequests || err != nil || seconds < 1 || seconds > 60 {
+		t.Fatalf("login after lockout got status %d with Retry-After %q, expected 429 within 60 seconds",
+			status, retryAfter)
+	}
+	if status, _ := api.login("bob", wrongPassword, ""); status != http.StatusUnauthorized {
+		t.Fatalf("login of other user got status %d, expected 401", status)
+	}
+}
+
+func TestLoginIsThrottledPerClientBehindTrustedProxy(t *testing.T) {
+	api := newTestAPI(t, map[string]string{
+		"LOGIN_IP_FREE_ATTEMPTS": "2",
+		"LOGIN_BACKOFF_BASE":     "1m",
+		//	Test requests come from unspecified address
+		"TRUSTED_PROXIES": "0.0.0.0",
+	})
+
+	for _, name := range []string{"alice", "bob"} {
+		if status, _ := api.login(name, wrongPassword, "203.0.113.1"); status != http.StatusUnauthorized {
+			t.Fatalf("failed login of %s got status %d, expected 401", name, status)
+		}
+	}
+	if status, retryAfter := api.login("carol", wrongPassword, "203.0.113.1"); status != http.StatusTooManyRequests ||
+		retryAfter == "" {
+		t.Fatalf("login from locked out client got status %d with Retry-After %q, expected 429", status, retryAfter)
+	}
+	if status, _ := api.login("carol", wrongPassword, "203.0.113.2"); status != http.StatusUnauthorized {
+		t.Fatalf("login from other client behind the same proxy got status %d, expected 401", status)
+	}
+}
+
+func TestLoginIgnoresProxyHeaderOfUntrustedClient(t *testing.T) {
+	api := newTestAPI(t, map[string]string{"LOGIN_IP_FREE_ATTEMPTS": "2", "LOGIN_BACKOFF_BASE": "1m"})
+
+	api.login("alice", wrongPassword, "203.0.113.1")
+	api.login("bob", wrongPassword, "203.0.113.2")
+	if status, _ := api.login("carol", wrongPassword, "203.0.113.3"); status != http.StatusTooManyRequests {
+		t.Fatalf("client changing X-Forwarded-For escaped lockout with status %d, expected 429", status)
+	}
+}
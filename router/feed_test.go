@@ -0,0 +1,70 @@
+package router_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"tiktok-arena/models"
+)
+
+// feedPage gets page of feed of user with token, empty cursor gets first page
+func (a *testAPI) feedPage(token string, cursor string, count int) models.FeedPage {
+	a.t.Helper()
+	var page models.FeedPage
+	a.expect(http.StatusOK, http.MethodGet, fmt.Sprintf("/api/feed?count=%d&cursor=%s", count, cursor), token, nil,
+		&page)
+	return page
+}
+
+func TestFeedCursorIsStableWhenNewItemsArrive(t *testing.T) {
+	api := newTestAPI(t, nil)
+	alice := api.register("alice")
+	bob := api.register("bob")
+	carol := api.register("carol")
+	api.expect(http.StatusOK, http.MethodPost, "/api/users/bob/follow", alice.Token, nil, nil)
+
+	var created []string
+	for i := 0; i < 5; i++ {
+		tournament := api.createTournament(bob.Token, fmt.Sprintf("cats%d", i), 4)
+		created = append(created, tournament.ID.String())
+	}
+	api.createTournament(carol.Token, "dogs", 4)
+
+	//	Walk feed page by page while bob keeps creating tournaments,
+	//	pages must continue where previous page ended without repeating or skipping items
+	var seen []string
+	first := api.feedPage(alice.Token, "", 2)
+	page := first
+	for i := 0; ; i++ {
+		for _, item := range page.Items {
+			if item.User.Name != "bob" || item.Type != models.FeedTournamentCreated {
+				t.Fatalf("feed of alice has %+v, expected only tournaments of bob", item)
+			}
+			seen = append(seen, item.ID.String())
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		api.createTournament(bob.Token, fmt.Sprintf("late%d", i), 4)
+		page = api.feedPage(alice.Token, page.NextCursor, 2)
+	}
+
+	if len(seen) != len(created) {
+		t.Fatalf("feed pages have %d items, expected %d tournaments created before first page", len(seen), len(created))
+	}
+	for i, id := range seen {
+		if id != created[len(created)-1-i] {
+			t.Fatalf("item %d of feed is %s, expected tournaments newest first %v", i, id, created)
+		}
+	}
+
+	//	Items created while paging are on new first page
+	fresh := api.feedPage(alice.Token, "", 2)
+	if fresh.Items[0].Tournament.Name == first.Items[0].Tournament.Name {
+		t.Fatalf("new first page starts with %s, expected tournament created while paging",
+			fresh.Items[0].Tournament.Name)
+	}
+
+	api.expectProblem(http.StatusBadRequest, "invalid_cursor", http.MethodGet, "/api/feed?cursor=not-a-cursor",
+		alice.Token, nil)
+}
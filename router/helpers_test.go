@@ -0,0 +1,219 @@
+package router_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"tiktok-arena/configuration"
+	"tiktok-arena/controllers"
+	"tiktok-arena/events"
+	"tiktok-arena/health"
+	"tiktok-arena/jwtkeys"
+	"tiktok-arena/mailer"
+	"tiktok-arena/middleware"
+	"tiktok-arena/models"
+	"tiktok-arena/notifications"
+	"tiktok-arena/oidc"
+	"tiktok-arena/router"
+	"tiktok-arena/store/memory"
+	"tiktok-arena/throttle"
+	"tiktok-arena/webhooks"
+)
+
+const testPassword = "Correct-horse-battery-9"
+
+// testAPI serves the whole HTTP API from memory store, the same way serve command does with database
+type testAPI struct {
+	t      *testing.T
+	app    *fiber.App
+	store  *memory.Store
+	mailer *mailer.MemoryMailer
+}
+
+// newTestAPI loads configuration from defaults and given overrides and sets up packages with global state
+func newTestAPI(t *testing.T, overrides map[string]string) *testAPI {
+	t.Helper()
+	settings := map[string]string{
+		"APP_ENV":        "development",
+		"DB_DRIVER":      "sqlite",
+		"JWT_SECRET_KEY": "test-secret-which-is-long-enough-for-hs256",
+		"MAILER":         "memory",
+	}
+	for key, value := range overrides {
+		settings[key] = value
+	}
+	err := configuration.LoadConfig("", settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = jwtkeys.Setup(&configuration.EnvConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oidc.Setup(&configuration.EnvConfig)
+	err = throttle.Setup(&configuration.EnvConfig, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = mailer.Setup(&configuration.EnvConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	memoryStore := memory.New()
+	stores := memoryStore.Stores()
+	bus := events.NewBus()
+	notifier := notifications.Setup(bus, stores.Notifications)
+	dispatcher := webhooks.Setup(&configuration.EnvConfig, bus, stores.Webhooks)
+	//	Memory mailer is nil in tests which disable mailer
+	mail, _ := mailer.Default.(*mailer.MemoryMailer)
+
+	app := fiber.New(fiber.Config{
+		ErrorHandler:            controllers.ErrorHandler,
+		ProxyHeader:             configuration.EnvConfig.ProxyHeader,
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          configuration.EnvConfig.TrustedProxyList(),
+		EnableIPValidation:      true,
+	})
+	app.Use(controllers.ErrorMiddleware())
+	handlers := controllers.NewHandlers(stores, bus, mailer.Default, dispatcher, notifier.Live, health.NewChecker())
+	router.SetupRoutes(app, handlers, middleware.NewAuth(stores.Users, stores.APITokens))
+
+	return &testAPI{t: t, app: app, store: memoryStore, mailer: mail}
+}
+
+// send sends request with JSON body and extra headers, empty token sends anonymous request.
+// Caller closes body of returned response
+func (a *testAPI) send(method string, path string, token string, body interface{}, headers map[string]string) *http.Response {
+	a.t.Helper()
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			a.t.Fatal(err)
+		}
+		reader = bytes.NewReader(encoded)
+	}
+	request := httptest.NewRequest(method, path, reader)
+	request.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	if token != "" {
+		request.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+	}
+	for key, value := range headers {
+		request.Header.Set(key, value)
+	}
+
+	response, err := a.app.Test(request, -1)
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	return response
+}
+
+// do sends request with JSON body, empty token sends anonymous request. Response body is decoded into result
+// unless result is nil
+func (a *testAPI) do(method string, path string, token string, body interface{}, result interface{}) int {
+	a.t.Helper()
+	response := a.send(method, path, token, body, nil)
+	defer response.Body.Close()
+	if result != nil {
+		err := json.NewDecoder(response.Body).Decode(result)
+		if err != nil {
+			a.t.Fatalf("%s %s: can not decode response with status %d: %v", method, path, response.StatusCode, err)
+		}
+	}
+	return response.StatusCode
+}
+
+// expect sends request like do and fails test if response has other status
+func (a *testAPI) expect(status int, method string, path string, token string, body interface{}, result interface{}) {
+	a.t.Helper()
+	var problem controllers.ProblemResponseType
+	if result == nil {
+		result = &problem
+	}
+	got := a.do(method, path, token, body, result)
+	if got != status {
+		a.t.Fatalf("%s %s: expected status %d, got %d %+v", method, path, status, got, result)
+	}
+}
+
+// expectProblem sends request and checks status and code of problem details in response
+func (a *testAPI) expectProblem(status int, code string, method string, path string, token string, body interface{}) {
+	a.t.Helper()
+	var problem controllers.ProblemResponseType
+	got := a.do(method, path, token, body, &problem)
+	if got != status || problem.Code != code {
+		a.t.Fatalf("%s %s: expected %d %s, got %d %s: %s", method, path, status, code, got, problem.Code, problem.Detail)
+	}
+}
+
+// register creates user with testPassword and returns its auth details
+func (a *testAPI) register(name string) models.UserAuthDetails {
+	a.t.Helper()
+	var details models.UserAuthDetails
+	a.expect(http.StatusCreated, http.MethodPost, "/api/auth/register", "",
+		models.AuthInput{Name: name, Password: testPassword}, &details)
+	return details
+}
+
+// setRole changes role of user directly in store, tokens of user get new role on next request
+func (a *testAPI) setRole(userId string, role string) {
+	a.t.Helper()
+	err := a.store.SetUserRole(context.Background(), userId, role)
+	if err != nil {
+		a.t.Fatal(err)
+	}
+}
+
+// createTournament creates tournament of given size owned by user with token and returns it
+func (a *testAPI) createTournament(token string, name string, size int) models.Tournament {
+	a.t.Helper()
+	tiktoks := make([]models.CreateTiktok, size)
+	for i := range tiktoks {
+		tiktoks[i].URL = tiktokURL(name, i)
+	}
+	a.expect(http.StatusOK, http.MethodPost, "/api/tournament", token,
+		models.CreateTournament{Name: name, Size: size, Tiktoks: tiktoks}, nil)
+
+	var tournaments []models.Tournament
+	a.expect(http.StatusOK, http.MethodGet, "/api/tournament", token, nil, &tournaments)
+	for _, tournament := range tournaments {
+		if tournament.Name == name {
+			return tournament
+		}
+	}
+	a.t.Fatalf("created tournament %s is not listed", name)
+	return models.Tournament{}
+}
+
+// tiktokURL returns URL of i-th tiktok of tournament created by createTournament
+func tiktokURL(tournamentName string, i int) string {
+	return fmt.Sprintf("https://www.tiktok.com/@%s/video/%d", tournamentName, 7000000000000000000+i)
+}
+
+// linkToken returns token from link in last email sent to recipient
+func (a *testAPI) linkToken(to string) string {
+	a.t.Helper()
+	message, ok := a.mailer.Last(to)
+	if !ok {
+		a.t.Fatalf("no email was sent to %s", to)
+	}
+	_, link, found := strings.Cut(message.Body, "?token=")
+	if !found {
+		a.t.Fatalf("email to %s has no link with token: %s", to, message.Body)
+	}
+	token, err := url.QueryUnescape(strings.Fields(link)[0])
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	return token
+}
@@ -0,0 +1,154 @@
+package router_test
+
+import (
+	"net/http"
+	"testing"
+	"tiktok-arena/events"
+	"tiktok-arena/models"
+)
+
+// report reports target and returns created report
+func (a *testAPI) report(token string, targetType string, targetId string) models.Report {
+	a.t.Helper()
+	var report models.Report
+	a.expect(http.StatusCreated, http.MethodPost, "/api/reports", token, models.CreateReport{
+		TargetType: targetType,
+		TargetID:   targetId,
+		Reason:     models.ReportReasonSpam,
+	}, &report)
+	return report
+}
+
+// listsTournament reports whether tournament with given name is in list of tournaments seen with token
+func (a *testAPI) listsTournament(token string, name string) bool {
+	a.t.Helper()
+	var tournaments []models.Tournament
+	a.expect(http.StatusOK, http.MethodGet, "/api/tournament", token, nil, &tournaments)
+	for _, tournament := range tournaments {
+		if tournament.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCreateReport(t *testing.T) {
+	api := newTestAPI(t, nil)
+	alice := api.register("alice")
+	bob := api.register("bob")
+	tournament := api.createTournament(alice.Token, "cats", 4)
+
+	api.expectProblem(http.StatusBadRequest, "invalid_target_type", http.MethodPost, "/api/reports", bob.Token,
+		models.CreateReport{TargetType: "user", TargetID: alice.ID, Reason: models.ReportReasonSpam})
+	api.expectProblem(http.StatusBadRequest, "invalid_reason", http.MethodPost, "/api/reports", bob.Token,
+		models.CreateReport{TargetType: models.ReportTargetTournament, TargetID: tournament.ID.String(), Reason: "boring"})
+	api.expectProblem(http.StatusNotFound, "target_not_found", http.MethodPost, "/api/reports", bob.Token,
+		models.CreateReport{TargetType: models.ReportTargetTournament, TargetID: unknownId, Reason: models.ReportReasonSpam})
+
+	report := api.report(bob.Token, models.ReportTargetTournament, tournament.ID.String())
+	if report.Status != models.ReportStatusOpen || report.ReporterID.String() != bob.ID {
+		t.Fatalf("unexpected created report: %+v", report)
+	}
+	api.expectProblem(http.StatusConflict, "already_reported", http.MethodPost, "/api/reports", bob.Token,
+		models.CreateReport{TargetType: models.ReportTargetTournament, TargetID: tournament.ID.String(),
+			Reason: models.ReportReasonOther})
+}
+
+func TestModerationRequiresModerator(t *testing.T) {
+	api := newTestAPI(t, nil)
+	alice := api.register("alice")
+
+	api.expectProblem(http.StatusForbidden, "insufficient_permissions", http.MethodGet, "/api/moderation/reports",
+		alice.Token, nil)
+	api.setRole(alice.ID, models.RoleModerator)
+	api.expect(http.StatusOK, http.MethodGet, "/api/moderation/reports", alice.Token, nil, &models.ReportsPage{})
+	api.expectProblem(http.StatusBadRequest, "invalid_status", http.MethodGet, "/api/moderation/reports?status=lost",
+		alice.Token, nil)
+}
+
+func TestHiddenTournamentIsVisibleOnlyToOwnerAndModerators(t *testing.T) {
+	api := newTestAPI(t, nil)
+	alice := api.register("alice")
+	bob := api.register("bob")
+	moderator := api.register("moderator")
+	api.setRole(moderator.ID, models.RoleModerator)
+	tournament := api.createTournament(alice.Token, "cats", 4)
+	tournamentPath := "/api/tournament/" + tournament.ID.String()
+	report := api.report(bob.Token, models.ReportTargetTournament, tournament.ID.String())
+
+	var queue models.ReportsPage
+	api.expect(http.StatusOK, http.MethodGet, "/api/moderation/reports", moderator.Token, nil, &queue)
+	if queue.Total != 1 || *queue.Reports[0].ID != *report.ID {
+		t.Fatalf("moderation queue has %+v, expected report of bob", queue.Reports)
+	}
+
+	actionPath := "/api/moderation/reports/" + report.ID.String() + "/action"
+	api.expectProblem(http.StatusBadRequest, "invalid_action", http.MethodPost, actionPath, moderator.Token,
+		models.ModerationInput{Action: models.ModerationDismiss})
+	api.expect(http.StatusOK, http.MethodPost, actionPath, moderator.Token,
+		models.ModerationInput{Action: models.ModerationHide, Note: "spam"}, nil)
+	api.expectProblem(http.StatusConflict, "report_closed", http.MethodPost, actionPath, moderator.Token,
+		models.ModerationInput{Action: models.ModerationHide})
+
+	if api.listsTournament("", "cats") || api.listsTournament(bob.Token, "cats") {
+		t.Fatal("hidden tournament is listed to anonymous user and bob")
+	}
+	api.expectProblem(http.StatusNotFound, "tournament_not_found", http.MethodGet, tournamentPath, bob.Token, nil)
+	if !api.listsTournament(alice.Token, "cats") || !api.listsTournament(moderator.Token, "cats") {
+		t.Fatal("hidden tournament is not listed to its owner and moderator")
+	}
+	api.expect(http.StatusOK, http.MethodGet, tournamentPath, alice.Token, nil, &models.Tournament{})
+	api.expect(http.StatusOK, http.MethodGet, tournamentPath, moderator.Token, nil, &models.Tournament{})
+
+	var actions models.ModerationActionsPage
+	api.expect(http.StatusOK, http.MethodGet, "/api/moderation/actions", moderator.Token, nil, &actions)
+	if actions.Total != 1 || actions.Actions[0].Action != models.ModerationHide ||
+		actions.Actions[0].AuthorID.String() != alice.ID {
+		t.Fatalf("moderation log has %+v, expected hide of tournament of alice", actions.Actions)
+	}
+
+	var notifications models.NotificationsPage
+	api.expect(http.StatusOK, http.MethodGet, "/api/notifications", alice.Token, nil, &notifications)
+	if notifications.Total != 1 || notifications.Notifications[0].Type != events.ModerationActionTaken {
+		t.Fatalf("alice has notifications %+v, expected one about moderation action", notifications.Notifications)
+	}
+}
+
+func TestModeratorWarnsAndBansAuthors(t *testing.T) {
+	api := newTestAPI(t, nil)
+	alice := api.register("alice")
+	bob := api.register("bob")
+	admin := api.register("admin")
+	api.setRole(admin.ID, models.RoleAdmin)
+	moderator := api.register("moderator")
+	api.setRole(moderator.ID, models.RoleModerator)
+
+	cats := api.createTournament(alice.Token, "cats", 4)
+	var tiktoks []models.Tiktok
+	api.expect(http.StatusOK, http.MethodGet, "/api/tournament/"+cats.ID.String()+"/tiktoks", "", nil, &tiktoks)
+	warned := api.report(bob.Token, models.ReportTargetTiktok, tiktoks[0].ID.String())
+	api.expect(http.StatusOK, http.MethodPost, "/api/moderation/reports/"+warned.ID.String()+"/action",
+		moderator.Token, models.ModerationInput{Action: models.ModerationWarn}, nil)
+	api.expect(http.StatusOK, http.MethodGet, "/api/auth/whoami", alice.Token, nil, &models.UserAuthDetails{})
+
+	banned := api.report(bob.Token, models.ReportTargetTournament, cats.ID.String())
+	api.expect(http.StatusOK, http.MethodPost, "/api/moderation/reports/"+banned.ID.String()+"/action",
+		moderator.Token, models.ModerationInput{Action: models.ModerationBanAuthor}, nil)
+	api.expectProblem(http.StatusForbidden, "user_banned", http.MethodGet, "/api/auth/whoami", alice.Token, nil)
+
+	//	Moderators can not ban administrators
+	dogs := api.createTournament(admin.Token, "dogs", 4)
+	protected := api.report(bob.Token, models.ReportTargetTournament, dogs.ID.String())
+	api.expectProblem(http.StatusForbidden, "author_protected", http.MethodPost,
+		"/api/moderation/reports/"+protected.ID.String()+"/action", moderator.Token,
+		models.ModerationInput{Action: models.ModerationBanAuthor})
+	api.expect(http.StatusOK, http.MethodPost, "/api/moderation/reports/"+protected.ID.String()+"/dismiss",
+		moderator.Token, nil, nil)
+
+	var dismissed models.ReportsPage
+	api.expect(http.StatusOK, http.MethodGet, "/api/moderation/reports?status="+models.ReportStatusDismissed,
+		moderator.Token, nil, &dismissed)
+	if dismissed.Total != 1 || *dismissed.Reports[0].ID != *protected.ID {
+		t.Fatalf("dismissed reports are %+v, expected report of admin tournament", dismissed.Reports)
+	}
+}
@@ -0,0 +1,208 @@
+package router_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"tiktok-arena/controllers"
+	"tiktok-arena/models"
+	"tiktok-arena/oidc/oidctest"
+)
+
+const oidcRedirectURL = "http://localhost:3000/oidc/callback"
+
+func newOIDCTestAPI(t *testing.T) (*testAPI, *oidctest.Server) {
+	t.Helper()
+	provider, err := oidctest.NewServer("arena", "arena-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(provider.Close)
+	api := newTestAPI(t, map[string]string{
+		"OIDC_ISSUER":        provider.Issuer(),
+		"OIDC_CLIENT_ID":     provider.ClientID,
+		"OIDC_CLIENT_SECRET": provider.ClientSecret,
+		"OIDC_REDIRECT_URL":  oidcRedirectURL,
+	})
+	return api, provider
+}
+
+// startOIDCLogin starts login flow, token is set for link flow
+func startOIDCLogin(api *testAPI, token string) models.OIDCAuthorization {
+	api.t.Helper()
+	var authorization models.OIDCAuthorization
+	if token == "" {
+		api.expect(http.StatusOK, http.MethodGet, "/api/auth/oidc/authorize", "", nil, &authorization)
+	} else {
+		api.expect(http.StatusOK, http.MethodPost, "/api/auth/oidc/link", token, nil, &authorization)
+	}
+	return authorization
+}
+
+// authorize approves authorization URL at provider and returns callback payload as browser would send it
+func authorize(t *testing.T, provider *oidctest.Server, authorization models.OIDCAuthorization) models.OIDCCallbackInput {
+	t.Helper()
+	code, state, err := provider.Authorize(authorization.AuthorizationURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return models.OIDCCallbackInput{Code: code, State: state, FlowToken: authorization.FlowToken}
+}
+
+// withQuery returns authorization URL with changed query parameter, e.g. to make provider see other nonce
+func withQuery(t *testing.T, authorizationURL string, key string, value string) string {
+	t.Helper()
+	parsed, err := url.Parse(authorizationURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := parsed.Query()
+	query.Set(key, value)
+	parsed.RawQuery = query.Encode()
+	return parsed.String()
+}
+
+func TestOIDCLoginCreatesUserOnFirstLogin(t *testing.T) {
+	api, provider := newOIDCTestAPI(t)
+	provider.SetUser(oidctest.User{Subject: "subject-1", PreferredUsername: "carol", Email: "carol@example.com"})
+
+	var first models.UserAuthDetails
+	callback := authorize(t, provider, startOIDCLogin(api, ""))
+	api.expect(http.StatusOK, http.MethodPost, "/api/auth/oidc/callback", "", callback, &first)
+	if first.Username != "carol" || first.Token == "" {
+		t.Fatalf("expected new user carol with token, got %+v", first)
+	}
+	if !api.store.CheckIfUserHasIdentity(context.Background(), first.ID, provider.Issuer()) {
+		t.Fatal("identity is not linked to created user")
+	}
+
+	var whoAmI models.UserAuthDetails
+	api.expect(http.StatusOK, http.MethodGet, "/api/auth/whoami", first.Token, nil, &whoAmI)
+	if whoAmI.ID != first.ID {
+		t.Fatalf("token belongs to %s, expected %s", whoAmI.ID, first.ID)
+	}
+
+	var second models.UserAuthDetails
+	callback = authorize(t, provider, startOIDCLogin(api, ""))
+	api.expect(http.StatusOK, http.MethodPost, "/api/auth/oidc/callback", "", callback, &second)
+	if second.ID != first.ID {
+		t.Fatalf("second login created user %s, expected login as %s", second.ID, first.ID)
+	}
+}
+
+func TestOIDCLoginAvoidsTakenUsername(t *testing.T) {
+	api, provider := newOIDCTestAPI(t)
+	existing := api.register("carol")
+	provider.SetUser(oidctest.User{Subject: "subject-1", PreferredUsername: "carol"})
+
+	var created models.UserAuthDetails
+	callback := authorize(t, provider, startOIDCLogin(api, ""))
+	api.expect(http.StatusOK, http.MethodPost, "/api/auth/oidc/callback", "", callback, &created)
+	if created.ID == existing.ID || created.Username == "carol" {
+		t.Fatalf("identity of provider was attached to existing account carol: %+v", created)
+	}
+}
+
+func TestOIDCLinkAttachesIdentityToExistingAccount(t *testing.T) {
+	api, provider := newOIDCTestAPI(t)
+	alice := api.register("alice")
+	provider.SetUser(oidctest.User{Subject: "alice-at-provider", PreferredUsername: "alice_idp"})
+
+	callback := authorize(t, provider, startOIDCLogin(api, alice.Token))
+	api.expect(http.StatusOK, http.MethodPost, "/api/auth/oidc/callback", "", callback, nil)
+
+	var login models.UserAuthDetails
+	callback = authorize(t, provider, startOIDCLogin(api, ""))
+	api.expect(http.StatusOK, http.MethodPost, "/api/auth/oidc/callback", "", callback, &login)
+	if login.ID != alice.ID {
+		t.Fatalf("login with linked identity returned user %s, expected alice %s", login.ID, alice.ID)
+	}
+
+	bob := api.register("bob")
+	callback = authorize(t, provider, startOIDCLogin(api, bob.Token))
+	api.expectProblem(http.StatusConflict, "identity_linked", http.MethodPost, "/api/auth/oidc/callback", "", callback)
+}
+
+func TestOIDCLinkRequiresSession(t *testing.T) {
+	api, _ := newOIDCTestAPI(t)
+	api.expectProblem(http.StatusUnauthorized, "missing_token", http.MethodPost, "/api/auth/oidc/link", "", nil)
+}
+
+func TestOIDCCallbackRejectsBadState(t *testing.T) {
+	api, provider := newOIDCTestAPI(t)
+
+	callback := authorize(t, provider, startOIDCLogin(api, ""))
+	callback.State = "forged-state"
+	api.expectProblem(http.StatusUnauthorized, "invalid_flow", http.MethodPost, "/api/auth/oidc/callback", "", callback)
+
+	//	Flow token of another flow does not match state either
+	other := startOIDCLogin(api, "")
+	callback = authorize(t, provider, startOIDCLogin(api, ""))
+	callback.FlowToken = other.FlowToken
+	api.expectProblem(http.StatusUnauthorized, "invalid_flow", http.MethodPost, "/api/auth/oidc/callback", "", callback)
+	assertNoUsers(t, api)
+}
+
+func TestOIDCCallbackRejectsNonceMismatch(t *testing.T) {
+	api, provider := newOIDCTestAPI(t)
+
+	authorization := startOIDCLogin(api, "")
+	authorization.AuthorizationURL = withQuery(t, authorization.AuthorizationURL, "nonce", "replayed-nonce")
+	callback := authorize(t, provider, authorization)
+	api.expectProblem(http.StatusUnauthorized, "oidc_login_failed", http.MethodPost, "/api/auth/oidc/callback", "", callback)
+	assertNoUsers(t, api)
+}
+
+func TestOIDCCallbackRejectsWrongCodeVerifier(t *testing.T) {
+	api, provider := newOIDCTestAPI(t)
+
+	//	Provider gets challenge of another verifier, so verifier kept in flow token does not match it
+	authorization := startOIDCLogin(api, "")
+	authorization.AuthorizationURL = withQuery(t, authorization.AuthorizationURL,
+		"code_challenge", "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM")
+	callback := authorize(t, provider, authorization)
+	api.expectProblem(http.StatusUnauthorized, "oidc_login_failed", http.MethodPost, "/api/auth/oidc/callback", "", callback)
+	assertNoUsers(t, api)
+}
+
+func TestOIDCCallbackRejectsReusedCode(t *testing.T) {
+	api, provider := newOIDCTestAPI(t)
+
+	callback := authorize(t, provider, startOIDCLogin(api, ""))
+	api.expect(http.StatusOK, http.MethodPost, "/api/auth/oidc/callback", "", callback, nil)
+
+	//	Error of provider is only logged, response does not reveal it
+	var problem controllers.ProblemResponseType
+	api.expect(http.StatusUnauthorized, http.MethodPost, "/api/auth/oidc/callback", "", callback, &problem)
+	if problem.Code != "oidc_login_failed" || problem.Detail != "OpenID Connect login failed" {
+		t.Fatalf("unexpected problem of reused code: %+v", problem)
+	}
+}
+
+func TestOIDCLoginRefusesUserWhoMustResetPassword(t *testing.T) {
+	api, provider := newOIDCTestAPI(t)
+	provider.SetUser(oidctest.User{Subject: "subject-1", PreferredUsername: "carol"})
+
+	var carol models.UserAuthDetails
+	api.expect(http.StatusOK, http.MethodPost, "/api/auth/oidc/callback", "",
+		authorize(t, provider, startOIDCLogin(api, "")), &carol)
+	err := api.store.SetUserMustResetPassword(context.Background(), carol.ID, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	api.expectProblem(http.StatusForbidden, "password_reset_required", http.MethodPost, "/api/auth/oidc/callback", "",
+		authorize(t, provider, startOIDCLogin(api, "")))
+}
+
+func assertNoUsers(t *testing.T, api *testAPI) {
+	t.Helper()
+	_, total, err := api.store.SearchUsers(context.Background(), "", models.NewPagination(1, 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 0 {
+		t.Fatalf("rejected login created %d users", total)
+	}
+}
@@ -5,26 +5,107 @@ import (
 	"github.com/gofiber/swagger"
 	"tiktok-arena/controllers"
 	_ "tiktok-arena/docs"
+	"tiktok-arena/metrics"
 	"tiktok-arena/middleware"
+	"tiktok-arena/models"
 )
 
-func SetupRoutes(app *fiber.App) {
+func SetupRoutes(app *fiber.App, h *controllers.Handlers, auth *middleware.Auth) {
+	app.Get("/.well-known/jwks.json", h.GetJWKS)
+	app.Get("/healthz", h.Healthz)
+	app.Get("/readyz", h.Readyz)
+	app.Get("/metrics", metrics.Handler())
+
 	api := app.Group("/api")
 
 	//	Use 'swag init' to generate new /docs files, details: https://github.com/gofiber/swagger#usage
 	api.Get("/docs/*", swagger.HandlerDefault)
 
 	api.Route("/auth", func(router fiber.Router) {
-		router.Post("/register", controllers.RegisterUser)
-		router.Post("/login", controllers.LoginUser)
-		router.Get("/whoami", middleware.Protected(), controllers.WhoAmI)
+		router.Post("/register", h.RegisterUser)
+		router.Post("/login", h.LoginUser)
+		router.Post("/login/2fa", h.LoginUserTwoFactor)
+		router.Post("/password", h.ChangePassword)
+		router.Post("/password/forgot", h.ForgotPassword)
+		router.Post("/password/reset", h.ResetPassword)
+		router.Put("/email", auth.Protected(), middleware.SessionOnly(), h.SetEmail)
+		router.Post("/email/resend", auth.Protected(), middleware.SessionOnly(), h.ResendEmailVerification)
+		router.Post("/email/verify", h.VerifyEmail)
+		router.Get("/whoami", auth.Protected(), middleware.RequireScope(models.ScopeRead), h.WhoAmI)
+		router.Post("/2fa/enroll", auth.Protected(), middleware.SessionOnly(), h.EnrollTwoFactor)
+		router.Post("/2fa/confirm", auth.Protected(), middleware.SessionOnly(), h.ConfirmTwoFactor)
+		router.Post("/2fa/disable", auth.Protected(), middleware.SessionOnly(), h.DisableTwoFactor)
+		router.Get("/oidc/authorize", h.OIDCAuthorize)
+		router.Post("/oidc/link", auth.Protected(), middleware.SessionOnly(), h.OIDCLink)
+		router.Post("/oidc/callback", h.OIDCCallback)
+		router.Get("/tokens", auth.Protected(), middleware.SessionOnly(), h.GetAPITokens)
+		router.Post("/tokens", auth.Protected(), middleware.SessionOnly(), h.CreateAPIToken)
+		router.Delete("/tokens/:tokenId", auth.Protected(), middleware.SessionOnly(), h.DeleteAPIToken)
 	})
 
 	api.Route("/tournament", func(router fiber.Router) {
-		router.Get("", controllers.GetAllTournaments)
-		router.Post("", middleware.Protected(), controllers.CreateTournament)
-		router.Get("/:tournamentId", controllers.GetTournamentDetails)
-		router.Get("/:tournamentId/tiktoks", controllers.GetTournamentTiktoks)
-		router.Get("/:tournamentId/contest", controllers.GetTournamentContest)
+		router.Get("", auth.OptionalAuth(), h.GetAllTournaments)
+		router.Post("", auth.Protected(), middleware.RequireScope(models.ScopeCreateTournaments),
+			h.CreateTournament)
+		router.Post("/import", auth.Protected(), middleware.RequireScope(models.ScopeCreateTournaments),
+			h.ImportTournament)
+		router.Get("/:tournamentId", auth.OptionalAuth(), h.GetTournamentDetails)
+		router.Get("/:tournamentId/export", auth.OptionalAuth(), h.ExportTournament)
+		router.Get("/:tournamentId/tiktoks", auth.OptionalAuth(), h.GetTournamentTiktoks)
+		router.Get("/:tournamentId/contest", auth.OptionalAuth(), h.GetTournamentContest)
+		router.Post("/:tournamentId/contest", auth.Protected(), middleware.RequireScope(models.ScopeSubmitResults),
+			h.SubmitContestResult)
+		router.Post("/:tournamentId/upvote", auth.Protected(), middleware.SessionOnly(), h.UpvoteTournament)
+		router.Delete("/:tournamentId/upvote", auth.Protected(), middleware.SessionOnly(),
+			h.RemoveTournamentUpvote)
+	})
+
+	api.Route("/users", func(router fiber.Router) {
+		router.Put("/me", auth.Protected(), middleware.SessionOnly(), h.EditProfile)
+		router.Get("/:name", h.GetUserProfile)
+		router.Post("/:name/follow", auth.Protected(), middleware.SessionOnly(), h.FollowUser)
+		router.Delete("/:name/follow", auth.Protected(), middleware.SessionOnly(), h.UnfollowUser)
+	})
+
+	api.Route("/notifications", func(router fiber.Router) {
+		router.Get("/stream", middleware.TokenFromQuery(), auth.Protected(), middleware.RequireScope(models.ScopeRead),
+			h.StreamNotifications)
+		router.Use(auth.Protected(), middleware.RequireScope(models.ScopeRead))
+		router.Get("", h.GetNotifications)
+		router.Get("/unread-count", h.GetUnreadNotificationsCount)
+		router.Post("/read-all", middleware.SessionOnly(), h.MarkAllNotificationsRead)
+		router.Post("/:notificationId/read", middleware.SessionOnly(), h.MarkNotificationRead)
+	})
+
+	api.Route("/webhooks", func(router fiber.Router) {
+		router.Use(auth.Protected(), middleware.SessionOnly())
+		router.Get("", h.GetWebhooks)
+		router.Post("", h.CreateWebhook)
+		router.Delete("/:webhookId", h.DeleteWebhook)
+		router.Get("/:webhookId/deliveries", h.GetWebhookDeliveries)
+		router.Post("/:webhookId/deliveries/:deliveryId/replay", h.ReplayWebhookDelivery)
+	})
+
+	api.Get("/feed", auth.Protected(), middleware.RequireScope(models.ScopeRead), h.GetFeed)
+
+	api.Post("/reports", auth.Protected(), middleware.SessionOnly(), h.CreateReport)
+
+	api.Route("/moderation", func(router fiber.Router) {
+		router.Use(auth.Protected(), middleware.SessionOnly(),
+			middleware.RequireRole(models.RoleModerator, models.RoleAdmin))
+		router.Get("/reports", h.GetReports)
+		router.Post("/reports/:reportId/resolve", h.ResolveReport)
+		router.Post("/reports/:reportId/dismiss", h.DismissReport)
+		router.Post("/reports/:reportId/action", h.ActOnReport)
+		router.Get("/actions", h.GetModerationActions)
+	})
+
+	api.Route("/admin", func(router fiber.Router) {
+		router.Use(auth.Protected(), middleware.SessionOnly(), middleware.RequireRole(models.RoleAdmin))
+		router.Get("/users", h.GetUsers)
+		router.Post("/users/:userId/ban", h.BanUser)
+		router.Post("/users/:userId/unban", h.UnbanUser)
+		router.Post("/users/:userId/reset-password", h.ForcePasswordReset)
+		router.Delete("/tournaments/:tournamentId", h.DeleteAnyTournament)
 	})
 }
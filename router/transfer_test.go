@@ -0,0 +1,84 @@
+package router_test
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"github.com/gofiber/fiber/v2"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"tiktok-arena/controllers"
+	"tiktok-arena/models"
+)
+
+// importCSV posts CSV file to tournament import and returns problem details of response
+func (a *testAPI) importCSV(token string, name string, file string) (int, controllers.ProblemResponseType) {
+	a.t.Helper()
+	request := httptest.NewRequest(http.MethodPost, "/api/tournament/import?format=csv&name="+name,
+		strings.NewReader(file))
+	request.Header.Set(fiber.HeaderContentType, "text/csv")
+	request.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+	response, err := a.app.Test(request, -1)
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	defer response.Body.Close()
+	var problem controllers.ProblemResponseType
+	err = json.NewDecoder(response.Body).Decode(&problem)
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	return response.StatusCode, problem
+}
+
+func TestTiktokURLsMustBeHTTP(t *testing.T) {
+	api := newTestAPI(t, nil)
+	alice := api.register("alice")
+
+	tiktoks := []models.CreateTiktok{{URL: tiktokURL("links", 0)}, {URL: "javascript:alert(1)"},
+		{URL: tiktokURL("links", 2)}, {URL: "ftp://example.com/video"}}
+	api.expectProblem(http.StatusBadRequest, "validation_failed", http.MethodPost, "/api/tournament", alice.Token,
+		models.CreateTournament{Name: "links", Size: 4, Tiktoks: tiktoks})
+	api.expectProblem(http.StatusBadRequest, "import_failed", http.MethodPost, "/api/tournament/import",
+		alice.Token, models.CreateTournament{Name: "links", Size: 4, Tiktoks: tiktoks})
+
+	status, problem := api.importCSV(alice.Token, "links", "url\n"+tiktokURL("links", 0)+"\n=1+2\n"+
+		tiktokURL("links", 2)+"\n"+tiktokURL("links", 3)+"\n")
+	rowErrors, _ := problem.Errors.([]interface{})
+	if status != http.StatusBadRequest || problem.Code != "import_failed" || len(rowErrors) != 1 ||
+		!strings.Contains(rowErrors[0].(map[string]interface{})["Message"].(string), "http or https URL") {
+		t.Fatalf("CSV import with formula responded %d %+v, expected error of row 3", status, problem)
+	}
+}
+
+func TestCSVExportEscapesFormulas(t *testing.T) {
+	api := newTestAPI(t, nil)
+	alice := api.register("alice")
+	tournament := api.createTournament(alice.Token, "formulas", 4)
+
+	//	Tiktoks created before URLs had to be http or https may start with anything
+	err := api.store.CreateNewTiktok(context.Background(), &models.Tiktok{
+		TournamentID: tournament.ID,
+		URL:          "=HYPERLINK(\"https://example.com\")",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response := api.send(http.MethodGet, "/api/tournament/"+tournament.ID.String()+"/export?format=csv", "",
+		nil, nil)
+	defer response.Body.Close()
+	records, err := csv.NewReader(response.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	urls := make(map[string]bool)
+	for _, record := range records[1:] {
+		urls[record[0]] = true
+	}
+	if len(records) != 6 || !urls["'=HYPERLINK(\"https://example.com\")"] || !urls[tiktokURL("formulas", 0)] {
+		t.Fatalf("unexpected CSV export: %v", records)
+	}
+}
@@ -0,0 +1,114 @@
+package router_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"tiktok-arena/models"
+	"tiktok-arena/totp"
+	"time"
+)
+
+// enableTwoFactor enrolls and confirms two-factor authentication of user,
+// returns enrollment and time step of code used for confirmation
+func (a *testAPI) enableTwoFactor(token string) (models.TwoFactorEnrollment, int64) {
+	a.t.Helper()
+	var enrollment models.TwoFactorEnrollment
+	a.expect(http.StatusOK, http.MethodPost, "/api/auth/2fa/enroll", token, nil, &enrollment)
+
+	step := totp.Step(time.Now())
+	a.expect(http.StatusOK, http.MethodPost, "/api/auth/2fa/confirm", token,
+		models.TwoFactorCodeInput{Code: a.totpCode(enrollment.Secret, step)}, nil)
+	return enrollment, step
+}
+
+func (a *testAPI) totpCode(secret string, step int64) string {
+	a.t.Helper()
+	code, err := totp.Code(secret, step)
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	return code
+}
+
+// challenge logs in with testPassword and returns two-factor challenge token
+func (a *testAPI) challenge(name string) string {
+	a.t.Helper()
+	var challenge models.TwoFactorChallenge
+	a.expect(http.StatusAccepted, http.MethodPost, "/api/auth/login", "",
+		models.AuthInput{Name: name, Password: testPassword}, &challenge)
+	return challenge.ChallengeToken
+}
+
+func TestTwoFactorEnrollment(t *testing.T) {
+	api := newTestAPI(t, nil)
+	alice := api.register("alice")
+
+	api.expectProblem(http.StatusConflict, "two_factor_not_enrolled", http.MethodPost, "/api/auth/2fa/confirm",
+		alice.Token, models.TwoFactorCodeInput{Code: "123456"})
+
+	var enrollment models.TwoFactorEnrollment
+	api.expect(http.StatusOK, http.MethodPost, "/api/auth/2fa/enroll", alice.Token, nil, &enrollment)
+	if !strings.HasPrefix(enrollment.URI, "otpauth://totp/") || !strings.Contains(enrollment.URI, enrollment.Secret) ||
+		len(enrollment.RecoveryCodes) != 10 {
+		t.Fatalf("unexpected enrollment: %+v", enrollment)
+	}
+
+	//	Two-factor is not enabled until it is confirmed with a valid code
+	api.expectProblem(http.StatusBadRequest, "invalid_two_factor_code", http.MethodPost, "/api/auth/2fa/confirm",
+		alice.Token, models.TwoFactorCodeInput{Code: "00000"})
+	api.expect(http.StatusOK, http.MethodPost, "/api/auth/login", "",
+		models.AuthInput{Name: "alice", Password: testPassword}, &models.UserAuthDetails{})
+
+	api.expect(http.StatusOK, http.MethodPost, "/api/auth/2fa/confirm", alice.Token,
+		models.TwoFactorCodeInput{Code: api.totpCode(enrollment.Secret, totp.Step(time.Now()))}, nil)
+	api.expectProblem(http.StatusConflict, "two_factor_enabled", http.MethodPost, "/api/auth/2fa/enroll",
+		alice.Token, nil)
+}
+
+func TestTwoFactorLogin(t *testing.T) {
+	api := newTestAPI(t, nil)
+	alice := api.register("alice")
+	enrollment, step := api.enableTwoFactor(alice.Token)
+
+	//	Challenge token does not work as access token
+	challenge := api.challenge("alice")
+	api.expectProblem(http.StatusUnauthorized, "invalid_token", http.MethodGet, "/api/auth/whoami", challenge, nil)
+
+	//	Code used for confirmation can not be used again, neither can later code once it was used
+	api.expectProblem(http.StatusUnauthorized, "invalid_two_factor_code", http.MethodPost, "/api/auth/login/2fa", "",
+		models.TwoFactorLoginInput{ChallengeToken: challenge, Code: api.totpCode(enrollment.Secret, step)})
+	nextCode := api.totpCode(enrollment.Secret, step+1)
+	var login models.UserAuthDetails
+	api.expect(http.StatusOK, http.MethodPost, "/api/auth/login/2fa", "",
+		models.TwoFactorLoginInput{ChallengeToken: challenge, Code: nextCode}, &login)
+	if login.ID != alice.ID || login.Token == "" {
+		t.Fatalf("two-factor login returned %+v, expected token of alice %s", login, alice.ID)
+	}
+	api.expectProblem(http.StatusUnauthorized, "invalid_two_factor_code", http.MethodPost, "/api/auth/login/2fa", "",
+		models.TwoFactorLoginInput{ChallengeToken: api.challenge("alice"), Code: nextCode})
+
+	//	Recovery code works once, with any case and without dash
+	recoveryCode := strings.ToUpper(strings.ReplaceAll(enrollment.RecoveryCodes[0], "-", ""))
+	api.expect(http.StatusOK, http.MethodPost, "/api/auth/login/2fa", "",
+		models.TwoFactorLoginInput{ChallengeToken: api.challenge("alice"), Code: recoveryCode}, &login)
+	api.expectProblem(http.StatusUnauthorized, "invalid_two_factor_code", http.MethodPost, "/api/auth/login/2fa", "",
+		models.TwoFactorLoginInput{ChallengeToken: api.challenge("alice"), Code: enrollment.RecoveryCodes[0]})
+
+	api.expectProblem(http.StatusUnauthorized, "invalid_challenge", http.MethodPost, "/api/auth/login/2fa", "",
+		models.TwoFactorLoginInput{ChallengeToken: alice.Token, Code: enrollment.RecoveryCodes[1]})
+}
+
+func TestPasswordDoesNotClearTwoFactorLockout(t *testing.T) {
+	api := newTestAPI(t, map[string]string{"LOGIN_USER_FREE_ATTEMPTS": "2", "LOGIN_BACKOFF_BASE": "1m"})
+	alice := api.register("alice")
+	api.enableTwoFactor(alice.Token)
+
+	//	Password alone is not a successful login, failed code guesses keep counting
+	api.expectProblem(http.StatusUnauthorized, "invalid_two_factor_code", http.MethodPost, "/api/auth/login/2fa", "",
+		models.TwoFactorLoginInput{ChallengeToken: api.challenge("alice"), Code: "00000"})
+	api.expectProblem(http.StatusUnauthorized, "invalid_two_factor_code", http.MethodPost, "/api/auth/login/2fa", "",
+		models.TwoFactorLoginInput{ChallengeToken: api.challenge("alice"), Code: "00000"})
+	api.expectProblem(http.StatusTooManyRequests, "too_many_requests", http.MethodPost, "/api/auth/login", "",
+		models.AuthInput{Name: "alice", Password: testPassword})
+}
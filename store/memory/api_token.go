@@ -0,0 +1,69 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"tiktok-arena/models"
+	"tiktok-arena/store"
+	"time"
+)
+
+func (s *Store) CreateAPIToken(_ context.Context, newToken *models.APIToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := newId(&newToken.ID)
+	if newToken.CreatedAt.IsZero() {
+		newToken.CreatedAt = now()
+	}
+	s.apiTokens[id] = *newToken
+	return nil
+}
+
+func (s *Store) GetUserAPITokens(_ context.Context, userId string) ([]models.APIToken, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var tokens []models.APIToken
+	for _, token := range s.apiTokens {
+		if sameId(token.UserID, userId) {
+			tokens = append(tokens, token)
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].CreatedAt.After(tokens[j].CreatedAt)
+	})
+	return tokens, nil
+}
+
+func (s *Store) GetAPITokenByHash(_ context.Context, tokenHash string) (models.APIToken, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, token := range s.apiTokens {
+		if token.TokenHash == tokenHash {
+			return token, nil
+		}
+	}
+	return models.APIToken{}, store.ErrNotFound
+}
+
+func (s *Store) DeleteAPIToken(_ context.Context, userId string, tokenId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, _ := parseId(tokenId)
+	token, ok := s.apiTokens[id]
+	if !ok || !sameId(token.UserID, userId) {
+		return store.ErrNotFound
+	}
+	delete(s.apiTokens, id)
+	return nil
+}
+
+func (s *Store) UpdateAPITokenLastUsed(_ context.Context, tokenId string, lastUsedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, _ := parseId(tokenId)
+	if token, ok := s.apiTokens[id]; ok {
+		token.LastUsedAt = &lastUsedAt
+		s.apiTokens[id] = token
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+// Package memory implements storage interfaces of package store in memory.
+// It is meant for tests and local experiments, data is lost when process exits
+package memory
+
+import (
+	"github.com/google/uuid"
+	"sync"
+	"tiktok-arena/models"
+	"tiktok-arena/store"
+	"time"
+)
+
+type pair [2]uuid.UUID
+
+// Store keeps all records in maps guarded by one mutex, records are copied in and out,
+// so callers can not change stored data without calling the store
+type Store struct {
+	mu sync.RWMutex
+
+	users         map[uuid.UUID]models.User
+	recoveryCodes []models.RecoveryCode
+	identities    []models.UserIdentity
+	follows       map[pair]models.Follow
+
+	tournaments map[uuid.UUID]models.Tournament
+	tiktoks     map[uuid.UUID]models.Tiktok
+	upvotes     map[pair]models.TournamentUpvote
+	contests    []models.Contest
+
+	apiTokens map[uuid.UUID]models.APIToken
+
+	reports           map[uuid.UUID]models.Report
+	moderationActions []models.ModerationAction
+
+	notifications map[uuid.UUID]models.Notification
+
+	webhooks   map[uuid.UUID]models.Webhook
+	deliveries map[uuid.UUID]models.WebhookDelivery
+}
+
+func New() *Store {
+	return &Store{
+		users:         make(map[uuid.UUID]models.User),
+		follows:       make(map[pair]models.Follow),
+		tournaments:   make(map[uuid.UUID]models.Tournament),
+		tiktoks:       make(map[uuid.UUID]models.Tiktok),
+		upvotes:       make(map[pair]models.TournamentUpvote),
+		apiTokens:     make(map[uuid.UUID]models.APIToken),
+		reports:       make(map[uuid.UUID]models.Report),
+		notifications: make(map[uuid.UUID]models.Notification),
+		webhooks:      make(map[uuid.UUID]models.Webhook),
+		deliveries:    make(map[uuid.UUID]models.WebhookDelivery),
+	}
+}
+
+// Stores returns all stores backed by this memory store
+func (s *Store) Stores() store.Stores {
+	return store.Stores{
+		Users:         s,
+		Tournaments:   s,
+		Tiktoks:       s,
+		APITokens:     s,
+		Moderation:    s,
+		Notifications: s,
+		Webhooks:      s,
+	}
+}
+
+// parseId parses id from request, invalid ids can not match any record
+func parseId(id string) (uuid.UUID, bool) {
+	parsed, err := uuid.Parse(id)
+	return parsed, err == nil
+}
+
+// newId fills empty id like database default does
+func newId(id **uuid.UUID) uuid.UUID {
+	if *id == nil {
+		generated := uuid.New()
+		*id = &generated
+	}
+	return **id
+}
+
+func now() time.Time {
+	return time.Now().UTC()
+}
+
+// page returns items of page and total count of items
+func page[T any](items []T, pagination models.Pagination) ([]T, int64) {
+	total := int64(len(items))
+	start := pagination.Offset()
+	if start > len(items) {
+		start = len(items)
+	}
+	end := start + pagination.Count
+	if end > len(items) {
+		end = len(items)
+	}
+	return append([]T(nil), items[start:end]...), total
+}
+
+func sameId(id *uuid.UUID, other string) bool {
+	return id != nil && id.String() == other
+}
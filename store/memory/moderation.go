@@ -0,0 +1,174 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"sort"
+	"tiktok-arena/models"
+	"tiktok-arena/store"
+)
+
+func (s *Store) CreateReport(_ context.Context, newReport *models.Report) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := newId(&newReport.ID)
+	if newReport.Status == "" {
+		newReport.Status = models.ReportStatusOpen
+	}
+	if newReport.CreatedAt.IsZero() {
+		newReport.CreatedAt = now()
+	}
+	s.reports[id] = *newReport
+	return nil
+}
+
+func (s *Store) CheckIfOpenReportExists(_ context.Context, reporterId string, targetType string, targetId string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, report := range s.reports {
+		if sameId(report.ReporterID, reporterId) && report.TargetType == targetType &&
+			sameId(report.TargetID, targetId) && report.Status == models.ReportStatusOpen {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Store) GetReportById(_ context.Context, reportId string) (models.Report, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	id, _ := parseId(reportId)
+	report, ok := s.reports[id]
+	if !ok {
+		return models.Report{}, store.ErrNotFound
+	}
+	return report, nil
+}
+
+func (s *Store) GetReports(_ context.Context, status string, pagination models.Pagination) ([]models.Report, int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var reports []models.Report
+	for _, report := range s.reports {
+		if report.Status == status {
+			reports = append(reports, report)
+		}
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].CreatedAt.Before(reports[j].CreatedAt)
+	})
+	reports, total := page(reports, pagination)
+	return reports, total, nil
+}
+
+func (s *Store) GetModerationActions(_ context.Context, pagination models.Pagination) ([]models.ModerationAction, int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	actions := make([]models.ModerationAction, len(s.moderationActions))
+	//	Actions are appended in order they were taken, newest go first
+	for i, action := range s.moderationActions {
+		actions[len(actions)-1-i] = action
+	}
+	actions, total := page(actions, pagination)
+	return actions, total, nil
+}
+
+func (s *Store) GetContentAuthorId(_ context.Context, targetType string, targetId string) (*uuid.UUID, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	tournamentId, err := s.contentTournamentId(targetType, targetId)
+	if err != nil {
+		return nil, err
+	}
+	tournament, ok := s.tournaments[tournamentId]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return tournament.UserID, nil
+}
+
+func (s *Store) GetContentTitle(_ context.Context, targetType string, targetId string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	id, _ := parseId(targetId)
+	switch targetType {
+	case models.ReportTargetTournament:
+		return s.tournaments[id].Name, nil
+	case models.ReportTargetTiktok:
+		return s.tiktoks[id].URL, nil
+	}
+	return "", fmt.Errorf("unknown report target type %s", targetType)
+}
+
+// contentTournamentId returns id of tournament reported content belongs to
+func (s *Store) contentTournamentId(targetType string, targetId string) (uuid.UUID, error) {
+	id, _ := parseId(targetId)
+	switch targetType {
+	case models.ReportTargetTournament:
+		return id, nil
+	case models.ReportTargetTiktok:
+		tiktok, ok := s.tiktoks[id]
+		if !ok {
+			return uuid.UUID{}, store.ErrNotFound
+		}
+		return *tiktok.TournamentID, nil
+	}
+	return uuid.UUID{}, fmt.Errorf("unknown report target type %s", targetType)
+}
+
+func (s *Store) CloseReport(
+	_ context.Context,
+	report *models.Report,
+	action *models.ModerationAction,
+	status string,
+) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.reports[*report.ID]
+	if !ok || stored.Status != models.ReportStatusOpen {
+		return fmt.Errorf("report %s is already closed: %w", report.ID, store.ErrConflict)
+	}
+
+	switch action.Action {
+	case models.ModerationHide:
+		switch action.TargetType {
+		case models.ReportTargetTournament:
+			if tournament, ok := s.tournaments[*action.TargetID]; ok {
+				tournament.Hidden = true
+				s.tournaments[*action.TargetID] = tournament
+			}
+		case models.ReportTargetTiktok:
+			if tiktok, ok := s.tiktoks[*action.TargetID]; ok {
+				tiktok.Hidden = true
+				s.tiktoks[*action.TargetID] = tiktok
+			}
+		default:
+			return fmt.Errorf("unknown report target type %s", action.TargetType)
+		}
+	case models.ModerationBanAuthor:
+		if action.AuthorID == nil || action.ModeratorID == nil || *action.AuthorID == *action.ModeratorID {
+			return fmt.Errorf("author of content can not be banned by this moderator: %w", store.ErrForbidden)
+		}
+		if user, ok := s.users[*action.AuthorID]; ok {
+			if !models.CanBan(s.users[*action.ModeratorID].Role, user.Role) {
+				return fmt.Errorf("%s can not ban %s %s: %w",
+					s.users[*action.ModeratorID].Role, user.Role, action.AuthorID, store.ErrForbidden)
+			}
+			user.Banned = true
+			s.users[*action.AuthorID] = user
+		}
+	}
+
+	resolvedAt := now()
+	stored.Status = status
+	stored.ResolvedAt = &resolvedAt
+	stored.ResolvedByID = action.ModeratorID
+	s.reports[*report.ID] = stored
+
+	newId(&action.ID)
+	action.CreatedAt = resolvedAt
+	s.moderationActions = append(s.moderationActions, *action)
+	return nil
+}
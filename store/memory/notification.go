@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"tiktok-arena/models"
+	"tiktok-arena/store"
+)
+
+func (s *Store) CreateNotification(_ context.Context, notification *models.Notification) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := newId(&notification.ID)
+	if notification.CreatedAt.IsZero() {
+		notification.CreatedAt = now()
+	}
+	s.notifications[id] = *notification
+	return nil
+}
+
+func (s *Store) GetNotifications(
+	_ context.Context,
+	userId string,
+	unreadOnly bool,
+	pagination models.Pagination,
+) ([]models.Notification, int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var notifications []models.Notification
+	for _, notification := range s.notifications {
+		if sameId(notification.UserID, userId) && (!unreadOnly || notification.ReadAt == nil) {
+			notifications = append(notifications, notification)
+		}
+	}
+	sort.Slice(notifications, func(i, j int) bool {
+		return notifications[i].CreatedAt.After(notifications[j].CreatedAt)
+	})
+	notifications, total := page(notifications, pagination)
+	return notifications, total, nil
+}
+
+func (s *Store) CountUnreadNotifications(_ context.Context, userId string) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var count int64
+	for _, notification := range s.notifications {
+		if sameId(notification.UserID, userId) && notification.ReadAt == nil {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s *Store) MarkNotificationRead(_ context.Context, userId string, notificationId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, _ := parseId(notificationId)
+	notification, ok := s.notifications[id]
+	if !ok || !sameId(notification.UserID, userId) {
+		return store.ErrNotFound
+	}
+	if notification.ReadAt == nil {
+		readAt := now()
+		notification.ReadAt = &readAt
+		s.notifications[id] = notification
+	}
+	return nil
+}
+
+func (s *Store) MarkAllNotificationsRead(_ context.Context, userId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	readAt := now()
+	for id, notification := range s.notifications {
+		if sameId(notification.UserID, userId) && notification.ReadAt == nil {
+			notification.ReadAt = &readAt
+			s.notifications[id] = notification
+		}
+	}
+	return nil
+}
@@ -0,0 +1,313 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"github.com/google/uuid"
+	"sort"
+	"strings"
+	"tiktok-arena/models"
+	"tiktok-arena/store"
+	"time"
+)
+
+// allTournamentsLimit is the same limit database store applies to list of all tournaments
+const allTournamentsLimit = 100
+
+func (s *Store) GetTournamentById(_ context.Context, tournamentId string) (models.Tournament, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	id, _ := parseId(tournamentId)
+	tournament, ok := s.tournaments[id]
+	if !ok {
+		return models.Tournament{}, store.ErrNotFound
+	}
+	return tournament, nil
+}
+
+func (s *Store) CheckIfTournamentExists(_ context.Context, tournamentName string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, tournament := range s.tournaments {
+		if tournament.Name == tournamentName {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Store) CreateNewTournament(_ context.Context, newTournament *models.Tournament) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := newId(&newTournament.ID)
+	if newTournament.CreatedAt.IsZero() {
+		newTournament.CreatedAt = now()
+	}
+	s.tournaments[id] = *newTournament
+	return nil
+}
+
+func (s *Store) DeleteTournament(_ context.Context, tournamentId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, _ := parseId(tournamentId)
+	if _, ok := s.tournaments[id]; !ok {
+		return store.ErrNotFound
+	}
+
+	contests := s.contests[:0]
+	for _, contest := range s.contests {
+		if *contest.TournamentID != id {
+			contests = append(contests, contest)
+		}
+	}
+	s.contests = contests
+	for key := range s.upvotes {
+		if key[0] == id {
+			delete(s.upvotes, key)
+		}
+	}
+	for tiktokId, tiktok := range s.tiktoks {
+		if *tiktok.TournamentID == id {
+			delete(s.tiktoks, tiktokId)
+		}
+	}
+	delete(s.tournaments, id)
+	return nil
+}
+
+func (s *Store) GetAllTournaments(_ context.Context, viewerId string, moderator bool) ([]models.Tournament, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var tournaments []models.Tournament
+	for _, tournament := range s.tournaments {
+		if moderator || !tournament.Hidden || (viewerId != "" && sameId(tournament.UserID, viewerId)) {
+			tournaments = append(tournaments, tournament)
+		}
+	}
+	sortTournaments(tournaments)
+	if len(tournaments) > allTournamentsLimit {
+		tournaments = tournaments[:allTournamentsLimit]
+	}
+	return tournaments, nil
+}
+
+func (s *Store) GetUserPublicTournaments(
+	_ context.Context,
+	userId string,
+	pagination models.Pagination,
+) ([]models.Tournament, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var tournaments []models.Tournament
+	for _, tournament := range s.tournaments {
+		if sameId(tournament.UserID, userId) && !tournament.Hidden {
+			tournaments = append(tournaments, tournament)
+		}
+	}
+	sortTournaments(tournaments)
+	tournaments, _ = page(tournaments, pagination)
+	return tournaments, nil
+}
+
+// sortTournaments orders tournaments newest first
+func sortTournaments(tournaments []models.Tournament) {
+	sort.Slice(tournaments, func(i, j int) bool {
+		return tournaments[i].CreatedAt.After(tournaments[j].CreatedAt)
+	})
+}
+
+func (s *Store) AddTournamentUpvote(_ context.Context, upvote *models.TournamentUpvote) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := pair{*upvote.TournamentID, *upvote.UserID}
+	if _, ok := s.upvotes[key]; ok {
+		return false, nil
+	}
+	if upvote.CreatedAt.IsZero() {
+		upvote.CreatedAt = now()
+	}
+	s.upvotes[key] = *upvote
+	return true, nil
+}
+
+func (s *Store) RemoveTournamentUpvote(_ context.Context, tournamentId string, userId string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tournament, _ := parseId(tournamentId)
+	user, _ := parseId(userId)
+	key := pair{tournament, user}
+	if _, ok := s.upvotes[key]; !ok {
+		return false, nil
+	}
+	delete(s.upvotes, key)
+	return true, nil
+}
+
+func (s *Store) CountTournamentUpvotes(_ context.Context, tournamentId string) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var count int64
+	for key := range s.upvotes {
+		if key[0].String() == tournamentId {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s *Store) RaiseUpvoteMilestone(_ context.Context, tournamentId string, milestone int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, _ := parseId(tournamentId)
+	tournament, ok := s.tournaments[id]
+	if !ok || tournament.UpvoteMilestone >= milestone {
+		return false, nil
+	}
+	tournament.UpvoteMilestone = milestone
+	s.tournaments[id] = tournament
+	return true, nil
+}
+
+func (s *Store) SaveContestResult(_ context.Context, newContest *models.Contest, points map[uuid.UUID]float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for tiktokId, tiktokPoints := range points {
+		tiktok, ok := s.tiktoks[tiktokId]
+		if !ok {
+			continue
+		}
+		tiktok.AvgPoints = (tiktok.AvgPoints*float64(tiktok.TimesPlayed) + tiktokPoints) / float64(tiktok.TimesPlayed+1)
+		tiktok.TimesPlayed++
+		if tiktokId == *newContest.WinnerTiktokID {
+			tiktok.Wins++
+		}
+		s.tiktoks[tiktokId] = tiktok
+	}
+	newId(&newContest.ID)
+	if newContest.CreatedAt.IsZero() {
+		newContest.CreatedAt = now()
+	}
+	s.contests = append(s.contests, *newContest)
+	return nil
+}
+
+func (s *Store) RecomputeTiktokWins(_ context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	wins := make(map[uuid.UUID]int)
+	for _, contest := range s.contests {
+		wins[*contest.WinnerTiktokID]++
+	}
+	var changed int64
+	for id, tiktok := range s.tiktoks {
+		if tiktok.Wins != wins[id] {
+			tiktok.Wins = wins[id]
+			s.tiktoks[id] = tiktok
+			changed++
+		}
+	}
+	return changed, nil
+}
+
+func (s *Store) GetFeed(
+	_ context.Context,
+	followerId string,
+	cursor *models.FeedCursor,
+	limit int,
+) ([]models.FeedItem, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	followed := make(map[uuid.UUID]bool)
+	for key := range s.follows {
+		if key[0].String() == followerId {
+			followed[key[1]] = true
+		}
+	}
+
+	var items []models.FeedItem
+	addItem := func(item models.FeedItem, userId *uuid.UUID, tournamentId *uuid.UUID) {
+		user, ok := s.users[*userId]
+		if !ok || user.Banned || !followed[*userId] {
+			return
+		}
+		tournament, ok := s.tournaments[*tournamentId]
+		if !ok || tournament.Hidden {
+			return
+		}
+		item.User = models.FeedUser{ID: user.ID, Name: user.Name, DisplayName: user.DisplayName}
+		item.Tournament = models.FeedTournament{ID: tournament.ID, Name: tournament.Name, Size: tournament.Size}
+		items = append(items, item)
+	}
+	for _, tournament := range s.tournaments {
+		addItem(models.FeedItem{
+			Type:      models.FeedTournamentCreated,
+			ID:        tournament.ID,
+			CreatedAt: tournament.CreatedAt,
+		}, tournament.UserID, tournament.ID)
+	}
+	for _, contest := range s.contests {
+		feedContest := &models.FeedContest{ContestType: contest.ContestType}
+		if winner, ok := s.tiktoks[*contest.WinnerTiktokID]; ok {
+			feedContest.WinnerURL = winner.URL
+		}
+		addItem(models.FeedItem{
+			Type:      models.FeedContestFinished,
+			ID:        contest.ID,
+			CreatedAt: contest.CreatedAt,
+			Contest:   feedContest,
+		}, contest.UserID, contest.TournamentID)
+	}
+
+	//	Same order as in database: newest first, ties are broken by id
+	newer := func(a *models.FeedItem, createdAt time.Time, id uuid.UUID) bool {
+		if !a.CreatedAt.Equal(createdAt) {
+			return a.CreatedAt.After(createdAt)
+		}
+		return bytes.Compare(a.ID[:], id[:]) > 0
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return newer(&items[i], items[j].CreatedAt, *items[j].ID)
+	})
+
+	feed := make([]models.FeedItem, 0, limit)
+	for i := range items {
+		if cursor != nil && !newer(&models.FeedItem{CreatedAt: cursor.CreatedAt, ID: &cursor.ID},
+			items[i].CreatedAt, *items[i].ID) {
+			continue
+		}
+		feed = append(feed, items[i])
+		if len(feed) == limit {
+			break
+		}
+	}
+	return feed, nil
+}
+
+func (s *Store) CreateNewTiktok(_ context.Context, newTiktok *models.Tiktok) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := newId(&newTiktok.ID)
+	s.tiktoks[id] = *newTiktok
+	return nil
+}
+
+func (s *Store) GetTournamentTiktoksById(
+	_ context.Context,
+	tournamentId string,
+	includeHidden bool,
+) ([]models.Tiktok, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var tiktoks []models.Tiktok
+	for _, tiktok := range s.tiktoks {
+		if sameId(tiktok.TournamentID, tournamentId) && (includeHidden || !tiktok.Hidden) {
+			tiktoks = append(tiktoks, tiktok)
+		}
+	}
+	sort.Slice(tiktoks, func(i, j int) bool {
+		return strings.Compare(tiktoks[i].URL, tiktoks[j].URL) < 0
+	})
+	return tiktoks, nil
+}
@@ -0,0 +1,409 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"sort"
+	"strings"
+	"tiktok-arena/models"
+	"tiktok-arena/store"
+)
+
+func (s *Store) GetUserByName(_ context.Context, username string) (models.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, user := range s.users {
+		if strings.EqualFold(user.Name, username) {
+			return user, nil
+		}
+	}
+	return models.User{}, store.ErrNotFound
+}
+
+func (s *Store) GetUserById(_ context.Context, userId string) (models.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	id, ok := parseId(userId)
+	if !ok {
+		return models.User{}, store.ErrNotFound
+	}
+	user, ok := s.users[id]
+	if !ok {
+		return models.User{}, store.ErrNotFound
+	}
+	return user, nil
+}
+
+func (s *Store) GetUserByEmail(_ context.Context, email string) (models.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, user := range s.users {
+		if user.Email != nil && user.EmailVerified && strings.EqualFold(*user.Email, email) {
+			return user, nil
+		}
+	}
+	return models.User{}, store.ErrNotFound
+}
+
+func (s *Store) GetUserAuthState(ctx context.Context, userId string) (models.UserAuthState, error) {
+	user, err := s.GetUserById(ctx, userId)
+	return models.UserAuthState{
+		Role:              user.Role,
+		Banned:            user.Banned,
+		SessionsRevokedAt: user.SessionsRevokedAt,
+	}, err
+}
+
+func (s *Store) CheckIfUserExists(ctx context.Context, username string) bool {
+	_, err := s.GetUserByName(ctx, username)
+	return err == nil
+}
+
+func (s *Store) CheckIfEmailTaken(_ context.Context, email string, exceptUserId string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for id, user := range s.users {
+		if user.Email != nil && strings.EqualFold(*user.Email, email) && id.String() != exceptUserId {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Store) SearchUsers(
+	_ context.Context,
+	search string,
+	pagination models.Pagination,
+) ([]models.UserSummary, int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var users []models.UserSummary
+	for _, user := range s.users {
+		if !strings.Contains(strings.ToLower(user.Name), strings.ToLower(search)) {
+			continue
+		}
+		users = append(users, models.UserSummary{
+			ID:                user.ID,
+			Name:              user.Name,
+			Role:              user.Role,
+			Banned:            user.Banned,
+			MustResetPassword: user.MustResetPassword,
+			TOTPEnabled:       user.TOTPEnabled,
+		})
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Name < users[j].Name })
+	users, total := page(users, pagination)
+	return users, total, nil
+}
+
+func (s *Store) CreateNewUser(_ context.Context, newUser *models.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.createUser(newUser)
+}
+
+// createUser checks unique constraints and fills defaults like database does, must be called with lock held
+func (s *Store) createUser(newUser *models.User) error {
+	for _, user := range s.users {
+		if strings.EqualFold(user.Name, newUser.Name) {
+			return fmt.Errorf("user %s already exists", newUser.Name)
+		}
+		if newUser.Email != nil && user.Email != nil && strings.EqualFold(*user.Email, *newUser.Email) {
+			return fmt.Errorf("email %s is already used", *newUser.Email)
+		}
+	}
+	id := newId(&newUser.ID)
+	if newUser.Role == "" {
+		newUser.Role = models.RoleUser
+	}
+	if newUser.CreatedAt.IsZero() {
+		newUser.CreatedAt = now()
+	}
+	s.users[id] = *newUser
+	return nil
+}
+
+// updateUser applies update to user, returns store.ErrNotFound if there is no such user
+func (s *Store) updateUser(userId string, update func(user *models.User)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, ok := parseId(userId)
+	if !ok {
+		return store.ErrNotFound
+	}
+	user, ok := s.users[id]
+	if !ok {
+		return store.ErrNotFound
+	}
+	update(&user)
+	s.users[id] = user
+	return nil
+}
+
+func (s *Store) SetUserBanned(_ context.Context, userId string, banned bool) error {
+	return s.updateUser(userId, func(user *models.User) {
+		user.Banned = banned
+	})
+}
+
+func (s *Store) SetUserRole(_ context.Context, userId string, role string) error {
+	return s.updateUser(userId, func(user *models.User) {
+		user.Role = role
+	})
+}
+
+func (s *Store) SetUserMustResetPassword(_ context.Context, userId string, mustReset bool) error {
+	return s.updateUser(userId, func(user *models.User) {
+		user.MustResetPassword = mustReset
+		if mustReset {
+			revokedAt := now()
+			user.SessionsRevokedAt = &revokedAt
+		}
+	})
+}
+
+func (s *Store) UpdateUserPassword(_ context.Context, userId string, hashedPassword string) error {
+	return s.updateUser(userId, func(user *models.User) {
+		revokedAt := now()
+		user.Password = hashedPassword
+		user.MustResetPassword = false
+		user.SessionsRevokedAt = &revokedAt
+	})
+}
+
+func (s *Store) SetUserEmail(_ context.Context, userId string, email string) error {
+	email = strings.ToLower(email)
+	return s.updateUser(userId, func(user *models.User) {
+		user.Email = &email
+		user.EmailVerified = false
+	})
+}
+
+func (s *Store) VerifyUserEmail(_ context.Context, userId string, email string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, _ := parseId(userId)
+	user, ok := s.users[id]
+	if !ok || user.Email == nil || !strings.EqualFold(*user.Email, email) {
+		return store.ErrNotFound
+	}
+	user.EmailVerified = true
+	s.users[id] = user
+	return nil
+}
+
+func (s *Store) UpdateUserProfile(_ context.Context, userId string, profile *models.EditProfile) error {
+	return s.updateUser(userId, func(user *models.User) {
+		user.DisplayName = profile.DisplayName
+		user.Bio = profile.Bio
+		user.AvatarURL = profile.AvatarURL
+	})
+}
+
+func (s *Store) GetUserStats(_ context.Context, userId string) (models.UserStats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var stats models.UserStats
+	for _, tournament := range s.tournaments {
+		if sameId(tournament.UserID, userId) && !tournament.Hidden {
+			stats.TournamentsCreated++
+		}
+	}
+	for _, contest := range s.contests {
+		if sameId(contest.UserID, userId) {
+			stats.ContestsPlayed++
+		}
+	}
+	for key := range s.upvotes {
+		tournament, ok := s.tournaments[key[0]]
+		if ok && sameId(tournament.UserID, userId) && !tournament.Hidden {
+			stats.UpvotesReceived++
+		}
+	}
+	for key := range s.follows {
+		if key[0].String() == userId {
+			stats.Following++
+		}
+		if key[1].String() == userId {
+			stats.Followers++
+		}
+	}
+	return stats, nil
+}
+
+func (s *Store) SetUserTOTPSecret(_ context.Context, userId string, secret string) error {
+	err := s.updateUser(userId, func(user *models.User) {
+		user.TOTPSecret = secret
+		user.TOTPEnabled = false
+	})
+	if err == store.ErrNotFound {
+		return nil
+	}
+	return err
+}
+
+func (s *Store) EnableUserTOTP(_ context.Context, userId string, step int64) error {
+	err := s.updateUser(userId, func(user *models.User) {
+		user.TOTPEnabled = true
+		user.TOTPLastStep = step
+	})
+	if err == store.ErrNotFound {
+		return nil
+	}
+	return err
+}
+
+func (s *Store) DisableUserTOTP(_ context.Context, userId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, _ := parseId(userId)
+	if user, ok := s.users[id]; ok {
+		user.TOTPSecret = ""
+		user.TOTPEnabled = false
+		user.TOTPLastStep = 0
+		s.users[id] = user
+	}
+	s.recoveryCodes = removeRecoveryCodes(s.recoveryCodes, id)
+	return nil
+}
+
+func (s *Store) UseUserTOTPStep(_ context.Context, userId string, step int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, _ := parseId(userId)
+	user, ok := s.users[id]
+	if !ok || user.TOTPLastStep >= step {
+		return false, nil
+	}
+	user.TOTPLastStep = step
+	s.users[id] = user
+	return true, nil
+}
+
+func (s *Store) ReplaceRecoveryCodes(_ context.Context, userId *uuid.UUID, codeHashes []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.recoveryCodes = removeRecoveryCodes(s.recoveryCodes, *userId)
+	for _, hash := range codeHashes {
+		code := models.RecoveryCode{UserID: userId, CodeHash: hash}
+		newId(&code.ID)
+		s.recoveryCodes = append(s.recoveryCodes, code)
+	}
+	return nil
+}
+
+func removeRecoveryCodes(codes []models.RecoveryCode, userId uuid.UUID) []models.RecoveryCode {
+	kept := codes[:0]
+	for _, code := range codes {
+		if *code.UserID != userId {
+			kept = append(kept, code)
+		}
+	}
+	return kept
+}
+
+func (s *Store) UseRecoveryCode(_ context.Context, userId string, codeHash string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.recoveryCodes {
+		code := &s.recoveryCodes[i]
+		if sameId(code.UserID, userId) && code.CodeHash == codeHash && code.UsedAt == nil {
+			usedAt := now()
+			code.UsedAt = &usedAt
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *Store) GetUserIdentity(_ context.Context, issuer string, subject string) (models.UserIdentity, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, identity := range s.identities {
+		if identity.Issuer == issuer && identity.Subject == subject {
+			return identity, nil
+		}
+	}
+	return models.UserIdentity{}, store.ErrNotFound
+}
+
+func (s *Store) CheckIfUserHasIdentity(_ context.Context, userId string, issuer string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, identity := range s.identities {
+		if sameId(identity.UserID, userId) && identity.Issuer == issuer {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Store) CreateUserIdentity(_ context.Context, newIdentity *models.UserIdentity) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.createIdentity(newIdentity)
+}
+
+func (s *Store) createIdentity(newIdentity *models.UserIdentity) error {
+	for _, identity := range s.identities {
+		if identity.Issuer == newIdentity.Issuer && identity.Subject == newIdentity.Subject {
+			return fmt.Errorf("identity %s from %s already exists", newIdentity.Subject, newIdentity.Issuer)
+		}
+	}
+	newId(&newIdentity.ID)
+	if newIdentity.CreatedAt.IsZero() {
+		newIdentity.CreatedAt = now()
+	}
+	s.identities = append(s.identities, *newIdentity)
+	return nil
+}
+
+func (s *Store) CreateNewUserWithIdentity(
+	_ context.Context,
+	newUser *models.User,
+	newIdentity *models.UserIdentity,
+) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, identity := range s.identities {
+		if identity.Issuer == newIdentity.Issuer && identity.Subject == newIdentity.Subject {
+			return fmt.Errorf("identity %s from %s already exists", newIdentity.Subject, newIdentity.Issuer)
+		}
+	}
+	err := s.createUser(newUser)
+	if err != nil {
+		return err
+	}
+	newIdentity.UserID = newUser.ID
+	return s.createIdentity(newIdentity)
+}
+
+func (s *Store) FollowUser(_ context.Context, follow *models.Follow) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := pair{*follow.FollowerID, *follow.FolloweeID}
+	if _, ok := s.follows[key]; ok {
+		return false, nil
+	}
+	if follow.CreatedAt.IsZero() {
+		follow.CreatedAt = now()
+	}
+	s.follows[key] = *follow
+	return true, nil
+}
+
+func (s *Store) UnfollowUser(_ context.Context, followerId string, followeeId string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	follower, _ := parseId(followerId)
+	followee, _ := parseId(followeeId)
+	key := pair{follower, followee}
+	if _, ok := s.follows[key]; !ok {
+		return false, nil
+	}
+	delete(s.follows, key)
+	return true, nil
+}
@@ -0,0 +1,191 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"tiktok-arena/models"
+	"tiktok-arena/store"
+	"time"
+)
+
+func (s *Store) CreateWebhook(_ context.Context, newWebhook *models.Webhook) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := newId(&newWebhook.ID)
+	if newWebhook.CreatedAt.IsZero() {
+		newWebhook.CreatedAt = now()
+	}
+	s.webhooks[id] = *newWebhook
+	return nil
+}
+
+func (s *Store) GetUserWebhooks(_ context.Context, userId string) ([]models.Webhook, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var webhooks []models.Webhook
+	for _, webhook := range s.webhooks {
+		if sameId(webhook.UserID, userId) {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].CreatedAt.Before(webhooks[j].CreatedAt)
+	})
+	return webhooks, nil
+}
+
+func (s *Store) GetUserWebhook(_ context.Context, userId string, webhookId string) (models.Webhook, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	id, _ := parseId(webhookId)
+	webhook, ok := s.webhooks[id]
+	if !ok || !sameId(webhook.UserID, userId) {
+		return models.Webhook{}, store.ErrNotFound
+	}
+	return webhook, nil
+}
+
+func (s *Store) GetWebhookById(_ context.Context, webhookId string) (models.Webhook, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	id, _ := parseId(webhookId)
+	webhook, ok := s.webhooks[id]
+	if !ok {
+		return models.Webhook{}, store.ErrNotFound
+	}
+	return webhook, nil
+}
+
+func (s *Store) GetWebhooksForEvent(_ context.Context, eventType string, userIds []string) ([]models.Webhook, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	owners := make(map[string]bool, len(userIds))
+	for _, userId := range userIds {
+		owners[userId] = true
+	}
+	var webhooks []models.Webhook
+	for _, webhook := range s.webhooks {
+		if !owners[webhook.UserID.String()] || s.users[*webhook.UserID].Banned {
+			continue
+		}
+		for _, event := range webhook.EventList() {
+			if event == eventType {
+				webhooks = append(webhooks, webhook)
+				break
+			}
+		}
+	}
+	return webhooks, nil
+}
+
+func (s *Store) DeleteWebhook(_ context.Context, userId string, webhookId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, _ := parseId(webhookId)
+	webhook, ok := s.webhooks[id]
+	if !ok || !sameId(webhook.UserID, userId) {
+		return store.ErrNotFound
+	}
+	for deliveryId, delivery := range s.deliveries {
+		if *delivery.WebhookID == id {
+			delete(s.deliveries, deliveryId)
+		}
+	}
+	delete(s.webhooks, id)
+	return nil
+}
+
+func (s *Store) CreateWebhookDelivery(_ context.Context, delivery *models.WebhookDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := newId(&delivery.ID)
+	if delivery.CreatedAt.IsZero() {
+		delivery.CreatedAt = now()
+	}
+	s.deliveries[id] = *delivery
+	return nil
+}
+
+func (s *Store) GetWebhookDelivery(_ context.Context, webhookId string, deliveryId string) (models.WebhookDelivery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	id, _ := parseId(deliveryId)
+	delivery, ok := s.deliveries[id]
+	if !ok || !sameId(delivery.WebhookID, webhookId) {
+		return models.WebhookDelivery{}, store.ErrNotFound
+	}
+	return delivery, nil
+}
+
+func (s *Store) GetWebhookDeliveries(
+	_ context.Context,
+	webhookId string,
+	pagination models.Pagination,
+) ([]models.WebhookDelivery, int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var deliveries []models.WebhookDelivery
+	for _, delivery := range s.deliveries {
+		if sameId(delivery.WebhookID, webhookId) {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt)
+	})
+	deliveries, total := page(deliveries, pagination)
+	return deliveries, total, nil
+}
+
+func (s *Store) GetDueWebhookDeliveries(_ context.Context, dueAt time.Time, limit int) ([]models.WebhookDelivery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var deliveries []models.WebhookDelivery
+	for _, delivery := range s.deliveries {
+		if delivery.Status == models.DeliveryPending && delivery.NextAttemptAt != nil &&
+			!delivery.NextAttemptAt.After(dueAt) {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].NextAttemptAt.Before(*deliveries[j].NextAttemptAt)
+	})
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
+
+func (s *Store) ClaimWebhookDelivery(
+	_ context.Context,
+	delivery *models.WebhookDelivery,
+	leaseUntil time.Time,
+) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, ok := s.deliveries[*delivery.ID]
+	if !ok || stored.Status != models.DeliveryPending || stored.NextAttemptAt == nil ||
+		delivery.NextAttemptAt == nil || !stored.NextAttemptAt.Equal(*delivery.NextAttemptAt) {
+		return false, nil
+	}
+	stored.NextAttemptAt = &leaseUntil
+	s.deliveries[*delivery.ID] = stored
+	return true, nil
+}
+
+func (s *Store) SaveWebhookDeliveryAttempt(_ context.Context, delivery *models.WebhookDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, ok := s.deliveries[*delivery.ID]
+	if !ok {
+		return nil
+	}
+	stored.Status = delivery.Status
+	stored.Attempts = delivery.Attempts
+	stored.NextAttemptAt = delivery.NextAttemptAt
+	stored.LastAttemptAt = delivery.LastAttemptAt
+	stored.ResponseStatus = delivery.ResponseStatus
+	stored.LastError = delivery.LastError
+	s.deliveries[*delivery.ID] = stored
+	return nil
+}
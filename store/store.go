@@ -0,0 +1,178 @@
+// Package store declares storage interfaces used by controllers and background workers.
+// Package database implements them with GORM and Postgres, package memory keeps data in memory,
+// so HTTP API can be run in tests without database
+package store
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"tiktok-arena/models"
+	"time"
+)
+
+// ErrNotFound is returned when requested record does not exist or does not belong to given user
+var ErrNotFound = errors.New("record not found")
+
+// ErrConflict is returned when record was changed by another request, e.g. report was closed concurrently
+var ErrConflict = errors.New("record was changed concurrently")
+
+// ErrForbidden is returned when role of acting user does not allow change, e.g. moderator bans administrator
+var ErrForbidden = errors.New("action is not permitted")
+
+type UserStore interface {
+	GetUserByName(ctx context.Context, username string) (models.User, error)
+	GetUserById(ctx context.Context, userId string) (models.User, error)
+	// GetUserByEmail finds user by verified email, letter case is ignored
+	GetUserByEmail(ctx context.Context, email string) (models.User, error)
+	GetUserAuthState(ctx context.Context, userId string) (models.UserAuthState, error)
+	CheckIfUserExists(ctx context.Context, username string) bool
+	CheckIfEmailTaken(ctx context.Context, email string, exceptUserId string) bool
+	// SearchUsers returns page of users whose name contains search string, ignoring letter case
+	SearchUsers(ctx context.Context, search string, pagination models.Pagination) ([]models.UserSummary, int64, error)
+	CreateNewUser(ctx context.Context, newUser *models.User) error
+
+	SetUserBanned(ctx context.Context, userId string, banned bool) error
+	SetUserRole(ctx context.Context, userId string, role string) error
+	// SetUserMustResetPassword forces or cancels password reset, forcing it also revokes sessions of user
+	SetUserMustResetPassword(ctx context.Context, userId string, mustReset bool) error
+	// UpdateUserPassword sets new password hash and clears forced password reset. Sessions and API tokens
+	// issued before are revoked, so whoever knew old password loses access
+	UpdateUserPassword(ctx context.Context, userId string, hashedPassword string) error
+	// SetUserEmail sets new unverified email of user
+	SetUserEmail(ctx context.Context, userId string, email string) error
+	// VerifyUserEmail marks email of user verified if it is still the current email of user
+	VerifyUserEmail(ctx context.Context, userId string, email string) error
+	UpdateUserProfile(ctx context.Context, userId string, profile *models.EditProfile) error
+	// GetUserStats counts public tournaments of user, contests user played, upvotes given to user tournaments
+	// and follows of user
+	GetUserStats(ctx context.Context, userId string) (models.UserStats, error)
+
+	SetUserTOTPSecret(ctx context.Context, userId string, secret string) error
+	EnableUserTOTP(ctx context.Context, userId string, step int64) error
+	// DisableUserTOTP removes TOTP secret and recovery codes of user
+	DisableUserTOTP(ctx context.Context, userId string) error
+	// UseUserTOTPStep marks TOTP time step as used, returns false if this or later step was used before
+	UseUserTOTPStep(ctx context.Context, userId string, step int64) (bool, error)
+	ReplaceRecoveryCodes(ctx context.Context, userId *uuid.UUID, codeHashes []string) error
+	// UseRecoveryCode marks unused recovery code as used, returns false if there is no such code
+	UseRecoveryCode(ctx context.Context, userId string, codeHash string) (bool, error)
+
+	GetUserIdentity(ctx context.Context, issuer string, subject string) (models.UserIdentity, error)
+	CheckIfUserHasIdentity(ctx context.Context, userId string, issuer string) bool
+	CreateUserIdentity(ctx context.Context, newIdentity *models.UserIdentity) error
+	// CreateNewUserWithIdentity creates user and links external identity to it atomically
+	CreateNewUserWithIdentity(ctx context.Context, newUser *models.User, newIdentity *models.UserIdentity) error
+
+	// FollowUser creates follow, returns false if user already follows followee
+	FollowUser(ctx context.Context, follow *models.Follow) (bool, error)
+	// UnfollowUser removes follow, returns false if user did not follow followee
+	UnfollowUser(ctx context.Context, followerId string, followeeId string) (bool, error)
+}
+
+type TournamentStore interface {
+	GetTournamentById(ctx context.Context, tournamentId string) (models.Tournament, error)
+	CheckIfTournamentExists(ctx context.Context, tournamentName string) bool
+	CreateNewTournament(ctx context.Context, newTournament *models.Tournament) error
+	// DeleteTournament deletes tournament with its tiktoks, contests and upvotes
+	DeleteTournament(ctx context.Context, tournamentId string) error
+	// GetAllTournaments returns tournaments visible to viewer: hidden tournaments are returned only to their
+	// owner or to moderators. Empty viewerId means anonymous viewer
+	GetAllTournaments(ctx context.Context, viewerId string, moderator bool) ([]models.Tournament, error)
+	// GetUserPublicTournaments returns page of tournaments of user not hidden by moderators, newest first
+	GetUserPublicTournaments(ctx context.Context, userId string, pagination models.Pagination) ([]models.Tournament, error)
+
+	// AddTournamentUpvote upvotes tournament, returns false if user already upvoted it
+	AddTournamentUpvote(ctx context.Context, upvote *models.TournamentUpvote) (bool, error)
+	// RemoveTournamentUpvote removes upvote, returns false if user did not upvote tournament
+	RemoveTournamentUpvote(ctx context.Context, tournamentId string, userId string) (bool, error)
+	CountTournamentUpvotes(ctx context.Context, tournamentId string) (int64, error)
+	// RaiseUpvoteMilestone records milestone reached by tournament, returns false if tournament already
+	// reached the same or higher milestone
+	RaiseUpvoteMilestone(ctx context.Context, tournamentId string, milestone int64) (bool, error)
+
+	// SaveContestResult records contest and updates statistics of played tiktoks atomically.
+	// Every played tiktok gets its average points recalculated, winner also gets a win
+	SaveContestResult(ctx context.Context, newContest *models.Contest, points map[uuid.UUID]float64) error
+	// GetFeed returns up to limit items of feed of follower older than cursor, newest first.
+	// Nil cursor starts from newest item
+	GetFeed(ctx context.Context, followerId string, cursor *models.FeedCursor, limit int) ([]models.FeedItem, error)
+	// RecomputeTiktokWins sets wins of every tiktok to count of recorded contests it won,
+	// returns count of tiktoks whose wins were wrong
+	RecomputeTiktokWins(ctx context.Context) (int64, error)
+}
+
+type TiktokStore interface {
+	CreateNewTiktok(ctx context.Context, newTiktok *models.Tiktok) error
+	// GetTournamentTiktoksById returns tournament tiktoks, hidden tiktoks are included only if includeHidden is set
+	GetTournamentTiktoksById(ctx context.Context, tournamentId string, includeHidden bool) ([]models.Tiktok, error)
+}
+
+type APITokenStore interface {
+	CreateAPIToken(ctx context.Context, newToken *models.APIToken) error
+	GetUserAPITokens(ctx context.Context, userId string) ([]models.APIToken, error)
+	GetAPITokenByHash(ctx context.Context, tokenHash string) (models.APIToken, error)
+	// DeleteAPIToken deletes token of user, returns ErrNotFound if user has no such token
+	DeleteAPIToken(ctx context.Context, userId string, tokenId string) error
+	UpdateAPITokenLastUsed(ctx context.Context, tokenId string, lastUsedAt time.Time) error
+}
+
+type ModerationStore interface {
+	CreateReport(ctx context.Context, newReport *models.Report) error
+	CheckIfOpenReportExists(ctx context.Context, reporterId string, targetType string, targetId string) bool
+	GetReportById(ctx context.Context, reportId string) (models.Report, error)
+	// GetReports returns page of reports with given status, oldest first, so queue is handled in order
+	GetReports(ctx context.Context, status string, pagination models.Pagination) ([]models.Report, int64, error)
+	GetModerationActions(ctx context.Context, pagination models.Pagination) ([]models.ModerationAction, int64, error)
+	// GetContentAuthorId returns id of user who created reported content
+	GetContentAuthorId(ctx context.Context, targetType string, targetId string) (*uuid.UUID, error)
+	// GetContentTitle returns tournament name or tiktok URL, used to refer to content in notifications
+	GetContentTitle(ctx context.Context, targetType string, targetId string) (string, error)
+	// CloseReport applies moderation action, records it and closes report with given status atomically.
+	// Ban of author is rejected with ErrForbidden unless models.CanBan allows it for current roles
+	CloseReport(ctx context.Context, report *models.Report, action *models.ModerationAction, status string) error
+}
+
+type NotificationStore interface {
+	CreateNotification(ctx context.Context, notification *models.Notification) error
+	// GetNotifications returns page of notifications of user, newest first
+	GetNotifications(ctx context.Context, userId string, unreadOnly bool, pagination models.Pagination) ([]models.Notification, int64, error)
+	CountUnreadNotifications(ctx context.Context, userId string) (int64, error)
+	// MarkNotificationRead marks notification of user read, returns ErrNotFound if user has no such notification
+	MarkNotificationRead(ctx context.Context, userId string, notificationId string) error
+	MarkAllNotificationsRead(ctx context.Context, userId string) error
+}
+
+type WebhookStore interface {
+	CreateWebhook(ctx context.Context, newWebhook *models.Webhook) error
+	GetUserWebhooks(ctx context.Context, userId string) ([]models.Webhook, error)
+	GetUserWebhook(ctx context.Context, userId string, webhookId string) (models.Webhook, error)
+	GetWebhookById(ctx context.Context, webhookId string) (models.Webhook, error)
+	// GetWebhooksForEvent returns webhooks subscribed to event type of given users who are not banned
+	GetWebhooksForEvent(ctx context.Context, eventType string, userIds []string) ([]models.Webhook, error)
+	// DeleteWebhook deletes webhook of user with its delivery log
+	DeleteWebhook(ctx context.Context, userId string, webhookId string) error
+
+	CreateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+	GetWebhookDelivery(ctx context.Context, webhookId string, deliveryId string) (models.WebhookDelivery, error)
+	// GetWebhookDeliveries returns page of delivery log of webhook, newest first
+	GetWebhookDeliveries(ctx context.Context, webhookId string, pagination models.Pagination) ([]models.WebhookDelivery, int64, error)
+	// GetDueWebhookDeliveries returns pending deliveries whose next attempt is due, oldest first
+	GetDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]models.WebhookDelivery, error)
+	// ClaimWebhookDelivery moves next attempt of due delivery to leaseUntil, so other workers skip it while
+	// it is being sent. Returns false if delivery was claimed by another worker
+	ClaimWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery, leaseUntil time.Time) (bool, error)
+	// SaveWebhookDeliveryAttempt records result of delivery attempt
+	SaveWebhookDeliveryAttempt(ctx context.Context, delivery *models.WebhookDelivery) error
+}
+
+// Stores groups all stores, implementations usually provide all of them with one value
+type Stores struct {
+	Users         UserStore
+	Tournaments   TournamentStore
+	Tiktoks       TiktokStore
+	APITokens     APITokenStore
+	Moderation    ModerationStore
+	Notifications NotificationStore
+	Webhooks      WebhookStore
+}
@@ -0,0 +1,61 @@
+package throttle
+
+import (
+	"sync"
+	"time"
+)
+
+// sweepEvery defines how many failures are registered between removals of stale keys
+const sweepEvery = 1000
+
+// MemoryStore keeps attempts in process memory, suitable for a single instance deployment
+type MemoryStore struct {
+	mu         sync.Mutex
+	attempts   map[string]Attempt
+	sinceSweep int
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{attempts: make(map[string]Attempt)}
+}
+
+func (s *MemoryStore) Get(key string) (Attempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.attempts[key], nil
+}
+
+func (s *MemoryStore) RegisterFailure(key string, now time.Time, resetBefore time.Time) (Attempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sinceSweep++
+	if s.sinceSweep >= sweepEvery {
+		s.sweep(resetBefore)
+	}
+
+	attempt := s.attempts[key]
+	if attempt.LastFailure.Before(resetBefore) {
+		attempt.Failures = 0
+	}
+	attempt.Failures++
+	attempt.LastFailure = now
+	s.attempts[key] = attempt
+	return attempt, nil
+}
+
+func (s *MemoryStore) Reset(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.attempts, key)
+	return nil
+}
+
+func (s *MemoryStore) sweep(resetBefore time.Time) {
+	for key, attempt := range s.attempts {
+		if attempt.LastFailure.Before(resetBefore) {
+			delete(s.attempts, key)
+		}
+	}
+	s.sinceSweep = 0
+}
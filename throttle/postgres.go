@@ -0,0 +1,58 @@
+package throttle
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"sync/atomic"
+	"tiktok-arena/models"
+	"time"
+)
+
+// PostgresStore keeps attempts in login_attempts table, so all instances share the same counters
+type PostgresStore struct {
+	db *gorm.DB
+	// sinceSweep counts failures registered by this instance, every sweepEvery failures stale rows are deleted
+	sinceSweep int64
+}
+
+func NewPostgresStore(db *gorm.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+func (s *PostgresStore) Get(key string) (Attempt, error) {
+	var attempt models.LoginAttempt
+	record := s.db.Table("login_attempts").First(&attempt, "key = ?", key)
+	if errors.Is(record.Error, gorm.ErrRecordNotFound) {
+		return Attempt{}, nil
+	}
+	return Attempt{Failures: attempt.Failures, LastFailure: attempt.LastFailure}, record.Error
+}
+
+func (s *PostgresStore) RegisterFailure(key string, now time.Time, resetBefore time.Time) (Attempt, error) {
+	if atomic.AddInt64(&s.sinceSweep, 1)%sweepEvery == 0 {
+		err := s.sweep(resetBefore)
+		if err != nil {
+			return Attempt{}, err
+		}
+	}
+
+	var attempt models.LoginAttempt
+	record := s.db.Raw(`
+		INSERT INTO login_attempts (key, failures, last_failure) VALUES (?, 1, ?)
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE WHEN login_attempts.last_failure < ? THEN 1 ELSE login_attempts.failures + 1 END,
+			last_failure = EXCLUDED.last_failure
+		RETURNING key, failures, last_failure`,
+		key, now, resetBefore,
+	).Scan(&attempt)
+	return Attempt{Failures: attempt.Failures, LastFailure: attempt.LastFailure}, record.Error
+}
+
+func (s *PostgresStore) Reset(key string) error {
+	return s.db.Table("login_attempts").Where("key = ?", key).Delete(&models.LoginAttempt{}).Error
+}
+
+// sweep deletes attempts whose counters would start from scratch anyway
+func (s *PostgresStore) sweep(resetBefore time.Time) error {
+	return s.db.Table("login_attempts").Where("last_failure < ?", resetBefore).Delete(&models.LoginAttempt{}).Error
+}
@@ -0,0 +1,137 @@
+package throttle
+
+import (
+	"fmt"
+	"gorm.io/gorm"
+	"math"
+	"tiktok-arena/configuration"
+	"time"
+)
+
+// Store keeps count of failed attempts per key
+type Store interface {
+	// Get returns current failures for key, zero Attempt if key is unknown
+	Get(key string) (Attempt, error)
+	// RegisterFailure increments failures for key. If last failure happened before resetBefore
+	// counter starts from scratch
+	RegisterFailure(key string, now time.Time, resetBefore time.Time) (Attempt, error)
+	// Reset forgets all failures for key
+	Reset(key string) error
+}
+
+type Attempt struct {
+	Failures    int
+	LastFailure time.Time
+}
+
+// Limiter allows FreeAttempts failures for a key, after that every next failure locks key out
+// for exponentially growing delay, starting from BaseDelay and limited by MaxDelay
+type Limiter struct {
+	Prefix       string
+	Store        Store
+	FreeAttempts int
+	BaseDelay    time.Duration
+	MaxDelay     time.Duration
+	ResetAfter   time.Duration
+}
+
+var (
+	LoginByUser *Limiter
+	LoginByIP   *Limiter
+
+	PasswordResetByEmail *Limiter
+	PasswordResetByIP    *Limiter
+)
+
+// Setup creates login and password reset limiters with store selected in configuration
+func Setup(config *configuration.EnvConfigModel, db *gorm.DB) error {
+	var store Store
+	switch config.LoginThrottleStore {
+	case "", "memory":
+		store = NewMemoryStore()
+	case "database":
+		store = NewPostgresStore(db)
+	default:
+		return fmt.Errorf("unknown login throttle store %s", config.LoginThrottleStore)
+	}
+
+	LoginByUser = &Limiter{
+		Prefix:       "login_user:",
+		Store:        store,
+		FreeAttempts: config.LoginUserFreeAttempts,
+		BaseDelay:    config.LoginBackoffBase,
+		MaxDelay:     config.LoginLockoutMax,
+		ResetAfter:   config.LoginAttemptsResetAfter,
+	}
+	LoginByIP = &Limiter{
+		Prefix:       "login_ip:",
+		Store:        store,
+		FreeAttempts: config.LoginIPFreeAttempts,
+		BaseDelay:    config.LoginBackoffBase,
+		MaxDelay:     config.LoginLockoutMax,
+		ResetAfter:   config.LoginAttemptsResetAfter,
+	}
+	PasswordResetByEmail = &Limiter{
+		Prefix:       "password_reset_email:",
+		Store:        store,
+		FreeAttempts: config.PasswordResetEmailFreeRequests,
+		BaseDelay:    config.LoginBackoffBase,
+		MaxDelay:     config.LoginLockoutMax,
+		ResetAfter:   config.LoginAttemptsResetAfter,
+	}
+	PasswordResetByIP = &Limiter{
+		Prefix:       "password_reset_ip:",
+		Store:        store,
+		FreeAttempts: config.PasswordResetIPFreeRequests,
+		BaseDelay:    config.LoginBackoffBase,
+		MaxDelay:     config.LoginLockoutMax,
+		ResetAfter:   config.LoginAttemptsResetAfter,
+	}
+	return nil
+}
+
+// RetryAfter returns how long key is locked out, zero if key can make an attempt now
+func (l *Limiter) RetryAfter(key string) (time.Duration, error) {
+	attempt, err := l.Store.Get(l.Prefix + key)
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now().UTC()
+	if now.Sub(attempt.LastFailure) > l.ResetAfter {
+		return 0, nil
+	}
+	lockedUntil := attempt.LastFailure.Add(l.delay(attempt.Failures))
+	if lockedUntil.After(now) {
+		return lockedUntil.Sub(now), nil
+	}
+	return 0, nil
+}
+
+// Fail registers failed attempt for key
+func (l *Limiter) Fail(key string) error {
+	now := time.Now().UTC()
+	_, err := l.Store.RegisterFailure(l.Prefix+key, now, now.Add(-l.ResetAfter))
+	return err
+}
+
+// Count registers attempt which is limited whatever its outcome, e.g. requested password reset email
+func (l *Limiter) Count(key string) error {
+	return l.Fail(key)
+}
+
+// Reset clears failed attempts for key after successful attempt
+func (l *Limiter) Reset(key string) error {
+	return l.Store.Reset(l.Prefix + key)
+}
+
+func (l *Limiter) delay(failures int) time.Duration {
+	if failures < l.FreeAttempts {
+		return 0
+	}
+	exponent := failures - l.FreeAttempts
+	delay := float64(l.BaseDelay) * math.Pow(2, float64(exponent))
+	if delay > float64(l.MaxDelay) {
+		return l.MaxDelay
+	}
+	return time.Duration(delay)
+}
@@ -0,0 +1,75 @@
+package totp_test
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"tiktok-arena/totp"
+	"time"
+)
+
+// rfcSecret is SHA1 seed of RFC 6238 Appendix B test vectors
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestCodeMatchesRFC6238TestVectors(t *testing.T) {
+	//	Test vectors have 8 digits, 6 digit codes are their last 6 digits
+	vectors := []struct {
+		unix int64
+		code string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+	for _, vector := range vectors {
+		expected := vector.code[len(vector.code)-totp.Digits:]
+		code, err := totp.Code(rfcSecret, totp.Step(time.Unix(vector.unix, 0)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code != expected {
+			t.Errorf("code at %d is %s, expected %s", vector.unix, code, expected)
+		}
+
+		step, valid := totp.Validate(strings.ToLower(rfcSecret), expected, time.Unix(vector.unix, 0))
+		if !valid || step != totp.Step(time.Unix(vector.unix, 0)) {
+			t.Errorf("code %s at %d was not accepted by Validate", expected, vector.unix)
+		}
+	}
+}
+
+func TestValidateAcceptsCodesWithinSkew(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	current := totp.Step(now)
+
+	for offset := int64(-totp.Skew - 1); offset <= totp.Skew+1; offset++ {
+		code, err := totp.Code(rfcSecret, current+offset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		step, valid := totp.Validate(rfcSecret, code[:3]+" "+code[3:], now)
+		expected := offset >= -totp.Skew && offset <= totp.Skew
+		if valid != expected {
+			t.Errorf("code of step %+d accepted is %t, expected %t", offset, valid, expected)
+		}
+		if valid && step != current+offset {
+			t.Errorf("code of step %+d matched step %+d", offset, step-current)
+		}
+	}
+}
+
+func TestValidateRejectsMalformedInput(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	if _, valid := totp.Validate("not base32!", "005924", now); valid {
+		t.Error("code was accepted for malformed secret")
+	}
+	if _, valid := totp.Validate(rfcSecret, "", now); valid {
+		t.Error("empty code was accepted")
+	}
+	if _, valid := totp.Validate(rfcSecret, "89005924", now); valid {
+		t.Error("8 digit code was accepted")
+	}
+}
@@ -0,0 +1,88 @@
+package tracing
+
+import (
+	"errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const querySpanKey = "tracing:query_span"
+
+// InstrumentDB records span of every query run by db within traced request or background job.
+// Queries without parent span, e.g. polling for webhook deliveries, are not traced
+func InstrumentDB(db *gorm.DB) error {
+	system := semconv.DBSystemKey.String(db.Dialector.Name())
+	switch db.Dialector.Name() {
+	case "postgres":
+		system = semconv.DBSystemPostgreSQL
+	case "sqlite":
+		system = semconv.DBSystemSqlite
+	}
+
+	callbacks := db.Callback()
+	processors := []struct {
+		operation string
+		before    func(name string, fn func(*gorm.DB)) error
+		after     func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", callbacks.Create().Before("*").Register, callbacks.Create().After("*").Register},
+		{"query", callbacks.Query().Before("*").Register, callbacks.Query().After("*").Register},
+		{"update", callbacks.Update().Before("*").Register, callbacks.Update().After("*").Register},
+		{"delete", callbacks.Delete().Before("*").Register, callbacks.Delete().After("*").Register},
+		{"row", callbacks.Row().Before("*").Register, callbacks.Row().After("*").Register},
+		{"raw", callbacks.Raw().Before("*").Register, callbacks.Raw().After("*").Register},
+	}
+	for _, processor := range processors {
+		err := processor.before("tracing:before_"+processor.operation, startQuerySpan(processor.operation, system))
+		if err != nil {
+			return err
+		}
+		err = processor.after("tracing:after_"+processor.operation, endQuerySpan)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func startQuerySpan(operation string, system attribute.KeyValue) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx := Context(db.Statement.Context)
+		if !trace.SpanContextFromContext(ctx).IsValid() {
+			return
+		}
+		name := operation
+		if db.Statement.Table != "" {
+			name += " " + db.Statement.Table
+		}
+		_, span := Tracer().Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(system))
+		db.InstanceSet(querySpanKey, span)
+	}
+}
+
+func endQuerySpan(db *gorm.DB) {
+	value, ok := db.InstanceGet(querySpanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+
+	//	Statement is recorded without parameters, they hold password hashes, tokens and emails
+	span.SetAttributes(
+		semconv.DBStatement(db.Statement.SQL.String()),
+		semconv.DBSQLTable(db.Statement.Table),
+		attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+	)
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+}
@@ -0,0 +1,90 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/valyala/fasthttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type contextKey int
+
+const spanKey contextKey = iota
+
+// Middleware records server span of every request. Span continues trace from traceparent header of request
+// and is named after matched route, e.g. GET /api/tournament/:tournamentId
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		//	Request values are backed by buffers reused by fasthttp, span outlives request
+		method := utils.CopyString(c.Method())
+		ctx := otel.GetTextMapPropagator().Extract(context.Background(), headerCarrier{&c.Request().Header})
+		ctx, span := Tracer().Start(ctx, method, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(
+			semconv.HTTPMethod(method),
+			//	Query is left out, notification stream gets access token in it
+			semconv.HTTPTarget(utils.CopyString(c.Path())),
+			semconv.HTTPScheme(utils.CopyString(c.Protocol())),
+			semconv.NetSockPeerAddr(utils.CopyString(c.IP())),
+		))
+		defer span.End()
+		c.Locals(spanKey, span)
+		c.SetUserContext(ctx)
+
+		err := c.Next()
+
+		//	Error is turned into response by error handler after middleware returns
+		status := c.Response().StatusCode()
+		if err != nil {
+			status = fiber.StatusInternalServerError
+			var fiberErr *fiber.Error
+			if errors.As(err, &fiberErr) {
+				status = fiberErr.Code
+			}
+			span.RecordError(err)
+		}
+		route := c.Route().Path
+		span.SetName(method + " " + route)
+		span.SetAttributes(semconv.HTTPRoute(route), semconv.HTTPStatusCode(status))
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, utils.StatusMessage(status))
+		}
+		return err
+	}
+}
+
+// Context returns ctx with span of request it belongs to. Handlers pass fiber.Ctx.Context() down,
+// which carries span as request local, while OpenTelemetry looks for it under own context key
+func Context(ctx context.Context) context.Context {
+	if trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+	if span, ok := ctx.Value(spanKey).(trace.Span); ok {
+		return trace.ContextWithSpan(ctx, span)
+	}
+	return ctx
+}
+
+// headerCarrier reads trace context from request headers
+type headerCarrier struct {
+	header *fasthttp.RequestHeader
+}
+
+func (h headerCarrier) Get(key string) string {
+	return string(h.header.Peek(key))
+}
+
+func (h headerCarrier) Set(key string, value string) {
+	h.header.Set(key, value)
+}
+
+func (h headerCarrier) Keys() []string {
+	var keys []string
+	h.header.VisitAll(func(key, _ []byte) {
+		keys = append(keys, string(key))
+	})
+	return keys
+}
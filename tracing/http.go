@@ -0,0 +1,20 @@
+package tracing
+
+import (
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"net/http"
+)
+
+// Transport records client span of every request sent with base and adds trace context headers to it
+func Transport(base http.RoundTripper) http.RoundTripper {
+	return contextTransport{next: otelhttp.NewTransport(base)}
+}
+
+// contextTransport moves span of request to context of outbound request, see Context
+type contextTransport struct {
+	next http.RoundTripper
+}
+
+func (t contextTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	return t.next.RoundTrip(request.WithContext(Context(request.Context())))
+}
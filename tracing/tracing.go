@@ -0,0 +1,99 @@
+// Package tracing records OpenTelemetry spans of requests, database queries and outbound HTTP calls.
+// Trace context is taken from W3C traceparent header of request and sent with outbound calls
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+	"net/url"
+	"os"
+	"tiktok-arena/configuration"
+)
+
+const instrumentationName = "tiktok-arena"
+
+// Memory keeps finished spans when memory exporter is selected, so tests can read them
+var Memory *tracetest.InMemoryExporter
+
+var provider *sdktrace.TracerProvider
+
+// Setup installs tracer provider with exporter selected in configuration and W3C trace context propagator.
+// Without exporter spans are not recorded, but trace context of requests is still passed to outbound calls
+func Setup(config *configuration.EnvConfigModel) error {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var processor sdktrace.SpanProcessor
+	switch config.TracingExporter {
+	case "", "none":
+		return nil
+	case "stdout":
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		if err != nil {
+			return err
+		}
+		processor = sdktrace.NewBatchSpanProcessor(exporter)
+	case "memory":
+		//	Spans are exported as soon as they end, tests read them right after request
+		Memory = tracetest.NewInMemoryExporter()
+		processor = sdktrace.NewSimpleSpanProcessor(Memory)
+	case "otlp":
+		exporter, err := newOTLPExporter(config.TracingOTLPEndpoint)
+		if err != nil {
+			return err
+		}
+		processor = sdktrace.NewBatchSpanProcessor(exporter)
+	default:
+		return fmt.Errorf("unknown tracing exporter %s", config.TracingExporter)
+	}
+
+	provider = sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(processor),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.TracingSampleRatio))),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceName(config.TracingServiceName),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+	return nil
+}
+
+// Shutdown exports spans which are not exported yet, it does nothing when tracing is disabled
+func Shutdown(ctx context.Context) error {
+	if provider == nil {
+		return nil
+	}
+	return provider.Shutdown(ctx)
+}
+
+// Tracer returns tracer of the server, spans of all packages are recorded with it
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+func newOTLPExporter(endpoint string) (sdktrace.SpanExporter, error) {
+	parsed, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	options := []otlptracehttp.Option{otlptracehttp.WithEndpoint(parsed.Host)}
+	if parsed.Scheme == "http" {
+		options = append(options, otlptracehttp.WithInsecure())
+	}
+	if parsed.Path != "" && parsed.Path != "/" {
+		options = append(options, otlptracehttp.WithURLPath(parsed.Path))
+	}
+	return otlptracehttp.New(context.Background(), options...)
+}
@@ -0,0 +1,164 @@
+package tracing_test
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"tiktok-arena/configuration"
+	"tiktok-arena/database"
+	"tiktok-arena/tracing"
+)
+
+const (
+	traceId     = "4bf92f3577b34da6a3ce929d0e0e4736"
+	traceparent = "00-" + traceId + "-00f067aa0ba902b7-01"
+	secret      = "secret-value"
+)
+
+// newTracedApp serves routes which query SQLite database and call server, recording spans with returned recorder
+func newTracedApp(t *testing.T, server *httptest.Server) (*fiber.App, *tracetest.SpanRecorder) {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	db, err := database.Open(&configuration.EnvConfigModel{
+		DBDriver:   "sqlite",
+		SQLitePath: filepath.Join(t.TempDir(), "arena.db"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.Exec("CREATE TABLE tournaments (id text PRIMARY KEY, name text NOT NULL)").Error
+	if err == nil {
+		err = tracing.InstrumentDB(db)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: tracing.Transport(http.DefaultTransport)}
+
+	app := fiber.New()
+	app.Use(tracing.Middleware())
+	app.Get("/api/tournament/:tournamentId", func(c *fiber.Ctx) error {
+		var names []string
+		err := db.WithContext(c.Context()).Table("tournaments").Where("name = ?", secret).Pluck("name", &names).Error
+		if err != nil {
+			return err
+		}
+		request, err := http.NewRequestWithContext(c.Context(), http.MethodGet, server.URL, nil)
+		if err != nil {
+			return err
+		}
+		response, err := client.Do(request)
+		if err != nil {
+			return err
+		}
+		return response.Body.Close()
+	})
+	app.Get("/api/broken", func(c *fiber.Ctx) error {
+		var names []string
+		return db.WithContext(c.Context()).Table("missing").Pluck("name", &names).Error
+	})
+	return app, recorder
+}
+
+func spanNamed(spans []sdktrace.ReadOnlySpan, name string) sdktrace.ReadOnlySpan {
+	for _, span := range spans {
+		if span.Name() == name {
+			return span
+		}
+	}
+	return nil
+}
+
+func TestTracingRecordsRequestQueryAndOutboundSpans(t *testing.T) {
+	outboundTraceparent := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		outboundTraceparent <- r.Header.Get("traceparent")
+	}))
+	defer server.Close()
+	app, recorder := newTracedApp(t, server)
+
+	request := httptest.NewRequest(http.MethodGet, "/api/tournament/42?token="+secret, nil)
+	request.Header.Set("traceparent", traceparent)
+	response, err := app.Test(request, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", response.StatusCode)
+	}
+
+	spans := recorder.Ended()
+	serverSpan := spanNamed(spans, "GET /api/tournament/:tournamentId")
+	querySpan := spanNamed(spans, "query tournaments")
+	clientSpan := spanNamed(spans, "HTTP GET")
+	if serverSpan == nil || querySpan == nil || clientSpan == nil {
+		names := make([]string, len(spans))
+		for i, span := range spans {
+			names[i] = span.Name()
+		}
+		t.Fatalf("expected server, query and client spans, got %v", names)
+	}
+
+	//	Request continues trace of caller, query and outbound call are children of request span
+	if serverSpan.SpanContext().TraceID().String() != traceId || serverSpan.SpanKind() != trace.SpanKindServer {
+		t.Fatalf("server span does not continue trace %s: %+v", traceId, serverSpan.SpanContext())
+	}
+	for _, span := range []sdktrace.ReadOnlySpan{querySpan, clientSpan} {
+		if span.Parent().SpanID() != serverSpan.SpanContext().SpanID() {
+			t.Errorf("parent of span %s is not request span", span.Name())
+		}
+	}
+	if header := <-outboundTraceparent; !strings.Contains(header, traceId) {
+		t.Errorf("outbound call got traceparent %q, expected trace %s", header, traceId)
+	}
+	if serverSpan.Status().Code == codes.Error || querySpan.Status().Code == codes.Error {
+		t.Error("successful request recorded error status")
+	}
+
+	//	Neither query string of request nor query parameters are recorded
+	for _, span := range spans {
+		for _, attribute := range span.Attributes() {
+			if strings.Contains(attribute.Value.Emit(), secret) || strings.Contains(attribute.Value.Emit(), "?token") {
+				t.Errorf("span %s has attribute %s=%s with secret", span.Name(), attribute.Key,
+					attribute.Value.Emit())
+			}
+		}
+	}
+}
+
+func TestTracingRecordsErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	app, recorder := newTracedApp(t, server)
+
+	response, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/broken", nil), -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("expected status 500, got %d", response.StatusCode)
+	}
+
+	spans := recorder.Ended()
+	for _, name := range []string{"GET /api/broken", "query missing"} {
+		span := spanNamed(spans, name)
+		if span == nil {
+			t.Fatalf("span %s is not recorded", name)
+		}
+		if span.Status().Code != codes.Error || len(span.Events()) == 0 {
+			t.Errorf("span %s has status %+v and %d events, expected error", name, span.Status(), len(span.Events()))
+		}
+	}
+}
@@ -0,0 +1,183 @@
+// Package transfer reads and writes tournaments in JSON and CSV files, so tournaments can be moved
+// between instances or prepared in spreadsheets
+package transfer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"tiktok-arena/models"
+)
+
+// csvHeader is first line of CSV export, only url column is read on import
+var csvHeader = []string{"url", "wins", "avg_points", "times_played"}
+
+// ImportError is returned when import file can not be parsed or does not pass validation
+type ImportError struct {
+	Errors []models.ImportRowError
+}
+
+func (e *ImportError) Error() string {
+	lines := make([]string, 0, len(e.Errors))
+	for _, rowError := range e.Errors {
+		if rowError.Row == 0 {
+			lines = append(lines, rowError.Message)
+		} else {
+			lines = append(lines, fmt.Sprintf("row %d: %s", rowError.Row, rowError.Message))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func ContentType(format string) string {
+	if format == models.TransferFormatCSV {
+		return "text/csv"
+	}
+	return "application/json"
+}
+
+func Export(w io.Writer, format string, export *models.TournamentExport) error {
+	switch format {
+	case models.TransferFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(export)
+	case models.TransferFormatCSV:
+		return exportCSV(w, export)
+	}
+	return fmt.Errorf("%s is not allowed export format", format)
+}
+
+func exportCSV(w io.Writer, export *models.TournamentExport) error {
+	writer := csv.NewWriter(w)
+	err := writer.Write(csvHeader)
+	if err != nil {
+		return err
+	}
+	for _, tiktok := range export.Tiktoks {
+		err = writer.Write([]string{
+			escapeFormula(tiktok.URL),
+			strconv.Itoa(tiktok.Wins),
+			strconv.FormatFloat(tiktok.AvgPoints, 'f', -1, 64),
+			strconv.Itoa(tiktok.TimesPlayed),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// escapeFormula prefixes text cell with apostrophe when spreadsheet would evaluate it as formula,
+// e.g. =HYPERLINK(...). Imported URLs must be http or https, but tiktoks created before that rule
+// may have any URL
+func escapeFormula(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
+
+// ImportJSON reads tournament from JSON in format of CreateTournament, JSON export is accepted as is
+func ImportJSON(r io.Reader) (*models.CreateTournament, error) {
+	var payload *models.CreateTournament
+	err := json.NewDecoder(r).Decode(&payload)
+	if err != nil {
+		return nil, &ImportError{Errors: []models.ImportRowError{{Row: 0, Message: err.Error()}}}
+	}
+	if payload == nil {
+		payload = &models.CreateTournament{}
+	}
+
+	rows := make([]int, len(payload.Tiktoks))
+	for i := range rows {
+		rows[i] = i + 1
+	}
+	err = validate(payload, rows)
+	if err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// ImportCSV reads tiktoks of tournament from CSV with header line, column url is required
+// and other columns are ignored. CSV has no place for tournament name, so it is passed separately.
+// Zero size means tournament of all tiktoks in file
+func ImportCSV(r io.Reader, name string, size int) (*models.CreateTournament, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, &ImportError{Errors: []models.ImportRowError{{Row: 1, Message: "file is empty"}}}
+	}
+	if err != nil {
+		return nil, &ImportError{Errors: []models.ImportRowError{{Row: 1, Message: err.Error()}}}
+	}
+	urlColumn := -1
+	for i, column := range header {
+		if strings.EqualFold(strings.TrimSpace(column), "url") {
+			urlColumn = i
+		}
+	}
+	if urlColumn == -1 {
+		return nil, &ImportError{Errors: []models.ImportRowError{{Row: 1, Message: "header has no url column"}}}
+	}
+
+	payload := &models.CreateTournament{Name: name, Size: size}
+	var rows []int
+	var errs []models.ImportRowError
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var parseError *csv.ParseError
+		if errors.As(err, &parseError) {
+			errs = append(errs, models.ImportRowError{Row: parseError.Line, Message: parseError.Err.Error()})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		row, _ := reader.FieldPos(0)
+		if urlColumn >= len(record) {
+			errs = append(errs, models.ImportRowError{Row: row, Message: "row has no url column"})
+			continue
+		}
+		payload.Tiktoks = append(payload.Tiktoks, models.CreateTiktok{URL: strings.TrimSpace(record[urlColumn])})
+		rows = append(rows, row)
+	}
+	if size == 0 {
+		payload.Size = len(payload.Tiktoks)
+	}
+
+	err = validate(payload, rows)
+	var importError *ImportError
+	if errors.As(err, &importError) {
+		errs = append(errs, importError.Errors...)
+	}
+	if len(errs) > 0 {
+		sort.SliceStable(errs, func(i, j int) bool {
+			return errs[i].Row < errs[j].Row
+		})
+		return nil, &ImportError{Errors: errs}
+	}
+	return payload, nil
+}
+
+func validate(payload *models.CreateTournament, rows []int) error {
+	errs := models.ValidateTournamentImport(payload, rows)
+	if len(errs) > 0 {
+		return &ImportError{Errors: errs}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package webhooks
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"tiktok-arena/tracing"
+	"time"
+)
+
+// newClient returns HTTP client for deliveries. Unless private networks are allowed, client refuses
+// to connect to loopback, private and link-local addresses, so webhooks can not reach internal services
+func newClient(timeout time.Duration, allowPrivateNetworks bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivateNetworks {
+		dialer.Control = rejectPrivateAddress
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	transport.Proxy = nil
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: tracing.Transport(transport),
+		//	Redirects are not followed, redirect response counts as failed attempt
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func rejectPrivateAddress(_ string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast() {
+		return fmt.Errorf("webhook address %s is not public", host)
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/exp/slog"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"tiktok-arena/configuration"
+	"tiktok-arena/models"
+	"tiktok-arena/store"
+	"tiktok-arena/tracing"
+	"time"
+)
+
+const (
+	// batchSize is maximum count of deliveries taken from database by one poll
+	batchSize = 50
+	// concurrency is maximum count of deliveries sent at the same time
+	concurrency = 4
+)
+
+// Dispatcher sends pending deliveries in background and retries failed ones with exponential backoff
+type Dispatcher struct {
+	Store        store.WebhookStore
+	Client       *http.Client
+	MaxAttempts  int
+	BackoffBase  time.Duration
+	BackoffMax   time.Duration
+	PollInterval time.Duration
+
+	wakeup  chan struct{}
+	running int32
+}
+
+func NewDispatcher(config *configuration.EnvConfigModel, webhookStore store.WebhookStore) *Dispatcher {
+	return &Dispatcher{
+		Store:        webhookStore,
+		Client:       newClient(config.WebhookTimeout, config.WebhookAllowPrivateNetworks),
+		MaxAttempts:  config.WebhookMaxAttempts,
+		BackoffBase:  config.WebhookBackoffBase,
+		BackoffMax:   config.WebhookBackoffMax,
+		PollInterval: config.WebhookPollInterval,
+		wakeup:       make(chan struct{}, 1),
+	}
+}
+
+// Run sends due deliveries until ctx is cancelled. Deliveries are kept in store,
+// so several server instances can run dispatchers at the same time. Delivery interrupted
+// by cancellation is retried when its lease expires
+func (d *Dispatcher) Run(ctx context.Context) {
+	atomic.StoreInt32(&d.running, 1)
+	defer atomic.StoreInt32(&d.running, 0)
+
+	ticker := time.NewTicker(d.PollInterval)
+	defer ticker.Stop()
+	for {
+		d.sendDue(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.wakeup:
+		}
+	}
+}
+
+// Running reports whether Run is sending deliveries
+func (d *Dispatcher) Running() bool {
+	return atomic.LoadInt32(&d.running) == 1
+}
+
+// wake makes Run check for deliveries without waiting for next poll
+func (d *Dispatcher) wake() {
+	select {
+	case d.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+func (d *Dispatcher) sendDue(ctx context.Context) {
+	now := time.Now().UTC()
+	deliveries, err := d.Store.GetDueWebhookDeliveries(ctx, now, batchSize)
+	if err != nil {
+		slog.ErrorCtx(ctx, "Failed to get due webhook deliveries", "error", err)
+		return
+	}
+
+	//	Lease lasts longer than request timeout, so delivery is retried if this instance dies while sending it
+	leaseUntil := now.Add(2*d.Client.Timeout + d.PollInterval)
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, concurrency)
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		claimed, err := d.Store.ClaimWebhookDelivery(ctx, delivery, leaseUntil)
+		if err != nil {
+			slog.ErrorCtx(ctx, "Failed to claim webhook delivery", "delivery_id", delivery.ID, "error", err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		slots <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			d.attempt(ctx, delivery)
+		}()
+	}
+	wg.Wait()
+}
+
+// attempt sends delivery once and records result, delivery is rescheduled if attempts are left
+func (d *Dispatcher) attempt(ctx context.Context, delivery *models.WebhookDelivery) {
+	ctx, span := tracing.Tracer().Start(ctx, "webhook delivery", trace.WithAttributes(
+		attribute.String("webhook.delivery_id", delivery.ID.String()),
+		attribute.String("webhook.event", delivery.EventType),
+	))
+	defer span.End()
+
+	now := time.Now().UTC()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+
+	webhook, err := d.Store.GetWebhookById(ctx, delivery.WebhookID.String())
+	if err == nil {
+		delivery.ResponseStatus, err = d.send(ctx, &webhook, delivery)
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	switch {
+	case err == nil:
+		delivery.Status = models.DeliverySucceeded
+		delivery.NextAttemptAt = nil
+		delivery.LastError = ""
+	case delivery.Attempts >= d.MaxAttempts:
+		delivery.Status = models.DeliveryFailed
+		delivery.NextAttemptAt = nil
+		delivery.LastError = err.Error()
+	default:
+		next := now.Add(d.backoff(delivery.Attempts))
+		delivery.NextAttemptAt = &next
+		delivery.LastError = err.Error()
+	}
+
+	err = d.Store.SaveWebhookDeliveryAttempt(ctx, delivery)
+	if err != nil {
+		slog.ErrorCtx(ctx, "Failed to save webhook delivery", "delivery_id", delivery.ID, "error", err)
+	}
+}
+
+func (d *Dispatcher) send(ctx context.Context, webhook *models.Webhook, delivery *models.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := time.Now().Unix()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "TikTok-Arena-Webhooks/1.0")
+	request.Header.Set(HeaderEvent, delivery.EventType)
+	request.Header.Set(HeaderDelivery, delivery.ID.String())
+	request.Header.Set(HeaderTimestamp, fmt.Sprint(timestamp))
+	request.Header.Set(HeaderSignature, Sign(webhook.Secret, timestamp, body))
+
+	response, err := d.Client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, fmt.Errorf("unexpected response status %d", response.StatusCode)
+	}
+	return response.StatusCode, nil
+}
+
+// backoff returns delay after given count of failed attempts: base, 2*base, 4*base... up to max
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.BackoffBase
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= d.BackoffMax {
+			return d.BackoffMax
+		}
+	}
+	return delay
+}
@@ -0,0 +1,148 @@
+// Package webhooks delivers events to URLs registered by users. Users receive events they caused and events
+// on their tournaments. Events are stored as pending deliveries and sent by background Dispatcher with retries,
+// so handlers never wait for remote servers
+package webhooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"golang.org/x/exp/slog"
+	"strconv"
+	"tiktok-arena/configuration"
+	"tiktok-arena/events"
+	"tiktok-arena/models"
+	"tiktok-arena/store"
+	"time"
+)
+
+const (
+	HeaderEvent     = "X-Arena-Event"
+	HeaderDelivery  = "X-Arena-Delivery"
+	HeaderTimestamp = "X-Arena-Timestamp"
+	HeaderSignature = "X-Arena-Signature"
+)
+
+// Setup creates dispatcher from configuration and subscribes it to events published on bus.
+// Deliveries are sent only after Run of returned dispatcher is started
+func Setup(config *configuration.EnvConfigModel, bus *events.Bus, webhookStore store.WebhookStore) *Dispatcher {
+	dispatcher := NewDispatcher(config, webhookStore)
+	bus.Subscribe(dispatcher.handleEvent)
+	return dispatcher
+}
+
+// Sign returns signature of delivery body sent in X-Arena-Signature header. Receiver should compute it
+// from X-Arena-Timestamp header and raw body and reject old timestamps to prevent replay attacks
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// handleEvent stores pending delivery for every webhook subscribed to event by its actor or owner of its target
+func (d *Dispatcher) handleEvent(ctx context.Context, event events.Event) {
+	if !models.CheckIfAllowedWebhookEvent(event.Type) {
+		return
+	}
+	var userIds []string
+	for _, userId := range []*uuid.UUID{event.ActorID, event.TargetOwnerID} {
+		if userId != nil {
+			userIds = append(userIds, userId.String())
+		}
+	}
+	if len(userIds) == 0 {
+		return
+	}
+
+	webhooks, err := d.Store.GetWebhooksForEvent(ctx, event.Type, userIds)
+	if err != nil {
+		slog.ErrorCtx(ctx, "Failed to get webhooks for event", "event", event.Type, "error", err)
+		return
+	}
+	if len(webhooks) == 0 {
+		return
+	}
+
+	payload, err := json.Marshal(eventPayload(&event))
+	if err != nil {
+		slog.ErrorCtx(ctx, "Failed to encode event", "event", event.Type, "error", err)
+		return
+	}
+
+	for i := range webhooks {
+		err = d.enqueue(ctx, &models.WebhookDelivery{
+			WebhookID: webhooks[i].ID,
+			EventType: event.Type,
+			Payload:   string(payload),
+		})
+		if err != nil {
+			slog.ErrorCtx(ctx, "Failed to create webhook delivery", "webhook_id", webhooks[i].ID, "error", err)
+		}
+	}
+}
+
+// Replay queues new delivery with payload of earlier delivery
+func (d *Dispatcher) Replay(ctx context.Context, delivery *models.WebhookDelivery) (models.WebhookDelivery, error) {
+	replay := models.WebhookDelivery{
+		WebhookID:  delivery.WebhookID,
+		EventType:  delivery.EventType,
+		Payload:    delivery.Payload,
+		ReplayOfID: delivery.ID,
+	}
+	err := d.enqueue(ctx, &replay)
+	return replay, err
+}
+
+func (d *Dispatcher) enqueue(ctx context.Context, delivery *models.WebhookDelivery) error {
+	deliveryId, err := uuid.NewRandom()
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	delivery.ID = &deliveryId
+	delivery.Status = models.DeliveryPending
+	delivery.NextAttemptAt = &now
+
+	err = d.Store.CreateWebhookDelivery(ctx, delivery)
+	if err != nil {
+		return err
+	}
+	d.wake()
+	return nil
+}
+
+func eventPayload(event *events.Event) models.WebhookPayload {
+	payload := models.WebhookPayload{
+		Event:     event.Type,
+		CreatedAt: event.CreatedAt,
+		Details:   event.Details,
+	}
+	if event.ActorID != nil {
+		payload.Actor = &models.WebhookPayloadActor{ID: event.ActorID, Name: event.ActorName}
+	}
+	if event.TargetID != nil {
+		payload.Target = &models.WebhookPayloadTarget{
+			Type: event.TargetType,
+			ID:   event.TargetID,
+			Name: event.TargetName,
+		}
+	}
+	return payload
+}
+
+// GenerateSecret returns random secret used to sign deliveries of new webhook
+func GenerateSecret() (string, error) {
+	random := make([]byte, 32)
+	_, err := rand.Read(random)
+	if err != nil {
+		return "", fmt.Errorf("could not generate webhook secret: %v", err)
+	}
+	return "whsec_" + hex.EncodeToString(random), nil
+}